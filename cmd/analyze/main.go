@@ -0,0 +1,147 @@
+// Command analyze buckets lost arena games by failure mode with a breakdown
+// per map size, so tuning effort goes to the dominant cause of losses.
+//
+//	analyze results.jsonl [more.jsonl ...]
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"spring2020/internal/arena"
+	"spring2020/internal/params"
+)
+
+// Failure mode of a lost game
+type Cause string
+
+// Failure modes, in the order they are checked
+const (
+	Timeout   Cause = "timeout"
+	PacDeaths Cause = "pac deaths"
+	Supers    Cause = "super pellets lost"
+	OutFarmed Cause = "out-farmed"
+)
+
+var causes = []Cause{Timeout, PacDeaths, Supers, OutFarmed}
+
+// Slowest turn of a decision log, summing the time of all pacs per turn
+func slowestTurn(name string) (time.Duration, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	turns := make(map[int]int64)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1000000), 1000000)
+	for scanner.Scan() {
+		var decision struct {
+			Turn   int   `json:"turn"`
+			Micros int64 `json:"micros"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &decision); err != nil {
+			return 0, err
+		}
+		turns[decision.Turn] += decision.Micros
+	}
+	var slowest int64
+	for _, micros := range turns {
+		if micros > slowest {
+			slowest = micros
+		}
+	}
+	return time.Duration(slowest) * time.Microsecond, scanner.Err()
+}
+
+// Categorize a lost game
+func categorize(result arena.Result, dir string, budget time.Duration) Cause {
+	if result.Timeout {
+		return Timeout
+	}
+	if result.DecisionLog != "" {
+		name := result.DecisionLog
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		slowest, err := slowestTurn(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "decision log:", err)
+		} else if slowest > budget {
+			return Timeout
+		}
+	}
+	if result.MyPacsLost > result.OpponentPacsLost {
+		return PacDeaths
+	}
+	if result.MySupers < result.OpponentSupers {
+		return Supers
+	}
+	return OutFarmed
+}
+
+func main() {
+	budget := flag.Duration("budget", 50*time.Millisecond, "turn time above which a decision log counts as a timeout")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: analyze [-budget 50ms] results.jsonl ...")
+		os.Exit(2)
+	}
+
+	outcomes := make(map[arena.Outcome]int)
+	losses := make(map[params.SizeClass]map[Cause]int)
+	games := make(map[params.SizeClass]int)
+	for _, name := range flag.Args() {
+		file, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		results, err := arena.ReadResults(file)
+		file.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, name+":", err)
+			os.Exit(1)
+		}
+		for _, result := range results {
+			size := params.Size(result.Width, result.Height)
+			outcomes[result.Outcome]++
+			games[size]++
+			if result.Outcome != arena.Loss {
+				continue
+			}
+			if losses[size] == nil {
+				losses[size] = make(map[Cause]int)
+			}
+			losses[size][categorize(result, filepath.Dir(name), *budget)]++
+		}
+	}
+
+	total := outcomes[arena.Win] + outcomes[arena.Draw] + outcomes[arena.Loss]
+	fmt.Printf("games %d  wins %d  draws %d  losses %d\n\n", total, outcomes[arena.Win], outcomes[arena.Draw], outcomes[arena.Loss])
+	fmt.Printf("%-8s %6s", "size", "games")
+	for _, cause := range causes {
+		fmt.Printf(" %20s", cause)
+	}
+	fmt.Println()
+	sizes := []params.SizeClass{params.Small, params.Medium, params.Large}
+	sum := make(map[Cause]int)
+	for _, size := range sizes {
+		fmt.Printf("%-8s %6d", size, games[size])
+		for _, cause := range causes {
+			fmt.Printf(" %20d", losses[size][cause])
+			sum[cause] += losses[size][cause]
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%-8s %6d", "all", total)
+	for _, cause := range causes {
+		fmt.Printf(" %20d", sum[cause])
+	}
+	fmt.Println()
+}
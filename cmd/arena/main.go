@@ -0,0 +1,132 @@
+// Command arena plays every pair of contenders against each other over the
+// same pool of generated maps and prints their win, draw and loss counts,
+// the Elo difference of each pair with its 95% confidence interval and the
+// Elo ratings updated game by game. A contender is a git revision, . for the
+// working tree, optionally with parameter overrides after an @. The maps
+// come from mapgen, an approximation of the contest generator, so ratings
+// measure play on maps like the contest's rather than on its own.
+//
+//	arena -games 100 . HEAD~3 .@RiskWeight=2,ThreatRadius=4
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"spring2020/internal/arena"
+	"spring2020/internal/harness"
+	"spring2020/internal/mapgen"
+	"spring2020/internal/referee"
+)
+
+// Contender of the arena: a build and the parameter overrides it plays with
+type contender struct {
+	name   string
+	rev    string
+	params string
+	bin    string
+	// results of the contender against all others
+	wins, draws, losses int
+}
+
+// Command running the contender
+func (c *contender) command() string {
+	if c.params == "" {
+		return c.bin
+	}
+	return c.bin + " -params " + c.params
+}
+
+// Parse a contender given as rev or rev@Name=value,Name=value
+func parseContender(spec string) *contender {
+	c := &contender{name: spec, rev: spec}
+	if at := strings.Index(spec, "@"); at >= 0 {
+		c.rev, c.params = spec[:at], spec[at+1:]
+	}
+	if c.rev == "." {
+		c.rev = ""
+	}
+	return c
+}
+
+func main() {
+	dir := flag.String("dir", "dist", "directory the contender binaries are built into")
+	games := flag.Int("games", 100, "games per pair of contenders")
+	seed := flag.Int64("seed", 0, "seed the map seeds are drawn from, the same maps for every pair")
+	pacs := flag.Int("pacs", 0, "pacs per player, random when 0")
+	parallel := flag.Int("parallel", runtime.NumCPU()/2, "games played at once")
+	turn := flag.Duration("turn", referee.TurnTimeout, "turn response time limit")
+	k := flag.Float64("k", arena.DefaultK, "Elo points moved per game for a full point above expectation")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: arena [flags] contender contender...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	fail := func(err error) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		fail(err)
+	}
+	var contenders []*contender
+	built := make(map[string]string)
+	for _, spec := range flag.Args() {
+		c := parseContender(spec)
+		if c.bin = built[c.rev]; c.bin == "" {
+			c.bin = filepath.Join(*dir, fmt.Sprintf("arena-%d", len(built)))
+			if err := harness.Build(".", c.rev, c.bin); err != nil {
+				fail(err)
+			}
+			built[c.rev] = c.bin
+		}
+		contenders = append(contenders, c)
+	}
+
+	ratings := arena.NewRatings(*k)
+	opts := harness.Options{
+		Games:       *games,
+		Seed:        *seed,
+		Map:         mapgen.Options{PacsPerPlayer: *pacs},
+		Parallel:    *parallel,
+		TurnTimeout: *turn,
+	}
+	fmt.Printf("%-24s %-24s %5s %5s %6s %8s %s\n", "contender", "opponent", "wins", "draws", "losses", "elo", "95% interval")
+	for i, a := range contenders {
+		for _, b := range contenders[i+1:] {
+			summary, err := harness.Run(a.command(), b.command(), opts, func(_ int, _ *referee.Record, result arena.Result) {
+				ratings.Update(a.name, b.name, result.Outcome.Points())
+			})
+			if err != nil {
+				fail(err)
+			}
+			a.wins, a.draws, a.losses = a.wins+summary.Wins, a.draws+summary.Draws, a.losses+summary.Losses
+			b.wins, b.draws, b.losses = b.wins+summary.Losses, b.draws+summary.Draws, b.losses+summary.Wins
+			diff, low, high := arena.EloInterval(summary.Wins, summary.Draws, summary.Losses)
+			fmt.Printf("%-24s %-24s %5d %5d %6d %+8.0f [%+.0f, %+.0f]\n", a.name, b.name, summary.Wins, summary.Draws, summary.Losses, diff, low, high)
+		}
+	}
+
+	sort.SliceStable(contenders, func(i, j int) bool {
+		return ratings.Rating(contenders[i].name) > ratings.Rating(contenders[j].name)
+	})
+	fmt.Printf("\n%-24s %7s %5s %5s %6s %7s\n", "contender", "rating", "wins", "draws", "losses", "score")
+	for _, c := range contenders {
+		n := c.wins + c.draws + c.losses
+		score := 0.0
+		if n > 0 {
+			score = (float64(c.wins) + float64(c.draws)/2) / float64(n)
+		}
+		fmt.Printf("%-24s %7.0f %5d %5d %6d %6.1f%%\n", c.name, ratings.Rating(c.name), c.wins, c.draws, c.losses, 100*score)
+	}
+}
@@ -0,0 +1,366 @@
+// Command bundle merges the bot and the module packages it imports into the
+// single self-contained main.go CodinGame accepts. Files are selected with
+// the given build tags, so the default "submit" profile leaves out the debug
+// subsystems local builds keep. The bundle is minified, without comments,
+// unreachable declarations, flag usage texts or long names, and failing
+// over the SizeLimit CodinGame accepts.
+//
+//	go run ./cmd/bundle -o dist/main.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Most characters of a submission CodinGame accepts
+const SizeLimit = 100000
+
+// Bundler collecting the declarations of a package and its module imports
+type Bundler struct {
+	module string
+	root   string
+	ctx    build.Context
+	done   map[string]bool
+	// standard library imports to their alias, "" when unnamed
+	imports map[string]string
+	// top-level names to the package declaring them
+	names map[string]string
+	decls []string
+}
+
+// Create bundler for the module rooted at root
+func NewBundler(root string, tags []string) (*Bundler, error) {
+	mod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	module := ""
+	for _, line := range strings.Split(string(mod), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+			module = fields[1]
+		}
+	}
+	if module == "" {
+		return nil, fmt.Errorf("no module line in %s", filepath.Join(root, "go.mod"))
+	}
+	ctx := build.Default
+	ctx.BuildTags = tags
+	return &Bundler{
+		module:  module,
+		root:    root,
+		ctx:     ctx,
+		done:    make(map[string]bool),
+		imports: make(map[string]string),
+		names:   make(map[string]string),
+	}, nil
+}
+
+// Check if import path belongs to the module
+func (b *Bundler) inModule(path string) bool {
+	return path == b.module || strings.HasPrefix(path, b.module+"/")
+}
+
+// Add the package in dir after the module packages it imports
+func (b *Bundler) Add(dir string) error {
+	if b.done[dir] {
+		return nil
+	}
+	b.done[dir] = true
+	pkg, err := b.ctx.ImportDir(dir, 0)
+	if err != nil {
+		return err
+	}
+	for _, path := range pkg.Imports {
+		if b.inModule(path) {
+			if err := b.Add(filepath.Join(b.root, strings.TrimPrefix(path, b.module))); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range pkg.GoFiles {
+		if err := b.addFile(pkg.ImportPath, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add the declarations of one file, dropping qualifiers of module packages
+func (b *Bundler) addFile(pkg, name string) error {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	// comments only cost characters of the submission
+	file, err := parser.ParseFile(fset, name, src, 0)
+	if err != nil {
+		return err
+	}
+	local := make(map[string]bool)
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		alias := ""
+		if imp.Name != nil {
+			alias = imp.Name.Name
+		}
+		if b.inModule(path) {
+			if alias == "" {
+				alias = filepath.Base(path)
+			}
+			local[alias] = true
+			continue
+		}
+		if prev, ok := b.imports[path]; ok && prev != alias {
+			return fmt.Errorf("%s: %q imported as %q and %q", name, path, prev, alias)
+		}
+		b.imports[path] = alias
+	}
+
+	// byte ranges of "pkg." qualifiers to cut out
+	var cuts [][2]int
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok && local[x.Name] && x.Obj == nil {
+			cuts = append(cuts, [2]int{fset.Position(x.Pos()).Offset, fset.Position(sel.Sel.Pos()).Offset})
+		}
+		return true
+	})
+
+	for _, decl := range file.Decls {
+		start := decl.Pos()
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					err = b.declare(pkg, s.Name.Name)
+				case *ast.ValueSpec:
+					for _, ident := range s.Names {
+						if err == nil {
+							err = b.declare(pkg, ident.Name)
+						}
+					}
+				}
+				if err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				if err := b.declare(pkg, d.Name.Name); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+		}
+		from, to := fset.Position(start).Offset, fset.Position(decl.End()).Offset
+		var text bytes.Buffer
+		for _, cut := range cuts {
+			if cut[0] >= from && cut[1] <= to {
+				text.Write(src[from:cut[0]])
+				from = cut[1]
+			}
+		}
+		text.Write(src[from:to])
+		b.decls = append(b.decls, text.String())
+	}
+	return nil
+}
+
+// Record a top-level name, failing when another package declared it too
+func (b *Bundler) declare(pkg, name string) error {
+	if name == "_" || name == "init" {
+		return nil
+	}
+	if prev, ok := b.names[name]; ok && prev != pkg {
+		return fmt.Errorf("%s declared in both %s and %s", name, prev, pkg)
+	}
+	b.names[name] = pkg
+	return nil
+}
+
+// Render the bundle as one minified main package
+func (b *Bundler) Source() ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString("package main\n\nimport (\n")
+	paths := make([]string, 0, len(b.imports))
+	for path := range b.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&out, "\t%s %q\n", b.imports[path], path)
+	}
+	out.WriteString(")\n\n")
+	out.WriteString(strings.Join(b.decls, "\n\n"))
+	out.WriteString("\n")
+	// the declarations still hold the comments inside their bodies, printing
+	// them parsed without comments drops those
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", out.Bytes(), 0)
+	if err != nil {
+		return nil, err
+	}
+	prune(file)
+	dropUsage(file)
+	if err := minify(fset, file); err != nil {
+		return nil, err
+	}
+	src, err := compact(fset, file)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("// Code generated by cmd/bundle. DO NOT EDIT.\n"), src...), nil
+}
+
+// Names a declaration introduces, methods keyed by their name alone
+func declared(decl ast.Decl) []string {
+	var names []string
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		names = append(names, d.Name.Name)
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, ident := range s.Names {
+					names = append(names, ident.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// Drop the declarations main cannot reach. Reachability goes by identifier
+// names only, so a method survives when anything kept mentions its name;
+// that keeps whatever satisfies an interface at the price of some dead code.
+func prune(file *ast.File) {
+	used := map[string]bool{"main": true, "init": true, "_": true, ".": true}
+	for _, name := range append(implicitMethods, "String", "Len", "Less", "Swap", "Push", "Pop") {
+		used[name] = true
+	}
+	// names used as a qualifier, the only way to use an import
+	qualifiers := make(map[string]bool)
+	kept := make([]bool, len(file.Decls))
+	for grown := true; grown; {
+		grown = false
+		for i, decl := range file.Decls {
+			if kept[i] {
+				continue
+			}
+			for _, name := range declared(decl) {
+				if used[name] {
+					kept[i] = true
+				}
+			}
+			if !kept[i] {
+				continue
+			}
+			grown = true
+			ast.Inspect(decl, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.Ident:
+					used[n.Name] = true
+				case *ast.SelectorExpr:
+					if x, ok := n.X.(*ast.Ident); ok {
+						qualifiers[x.Name] = true
+					}
+				}
+				return true
+			})
+		}
+	}
+	decls := file.Decls[:0]
+	for i, decl := range file.Decls {
+		if kept[i] {
+			decls = append(decls, decl)
+			continue
+		}
+		// keep the imports the kept declarations qualify with
+		if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.IMPORT {
+			specs := d.Specs[:0]
+			for _, spec := range d.Specs {
+				s := spec.(*ast.ImportSpec)
+				name := path.Base(strings.Trim(s.Path.Value, `"`))
+				if s.Name != nil {
+					name = s.Name.Name
+				}
+				if qualifiers[name] || name == "_" || name == "." {
+					specs = append(specs, spec)
+				}
+			}
+			d.Specs = specs
+			decls = append(decls, d)
+		}
+	}
+	file.Decls = decls
+	file.Imports = nil
+}
+
+func main() {
+	tags := flag.String("tags", "submit", "comma separated build tags selecting the files")
+	output := flag.String("o", "", "output file, stdout when empty")
+	flag.Parse()
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var tagList []string
+	if *tags != "" {
+		tagList = strings.Split(*tags, ",")
+	}
+	b, err := NewBundler(dir, tagList)
+	if err == nil {
+		err = b.Add(dir)
+	}
+	var src []byte
+	if err == nil {
+		src, err = b.Source()
+	}
+	if err == nil && len(src) > SizeLimit {
+		err = fmt.Errorf("%d bytes, over the %d CodinGame accepts", len(src), SizeLimit)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bundle:", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		_, _ = os.Stdout.Write(src)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(*output), 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "bundled %d declarations into %s (%d bytes)\n", len(b.decls), *output, len(src))
+}
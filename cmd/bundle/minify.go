@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// Methods the standard library finds through interfaces it does not export
+// from the packages the bundle imports
+var implicitMethods = []string{"Error", "Format", "GoString", "MarshalText", "UnmarshalText"}
+
+// Shorten the identifiers the bundle declares. Renaming goes by name, one
+// short name per original one, which keeps shadowing, interfaces and
+// promoted fields intact. Names that also refer to something outside the
+// bundle stay, as do the methods of standard library interfaces and the
+// exported fields reflect and encoding/json may read.
+func minify(fset *token.FileSet, file *ast.File) error {
+	info := &types.Info{
+		Defs:      make(map[*ast.Ident]types.Object),
+		Uses:      make(map[*ast.Ident]types.Object),
+		Implicits: make(map[ast.Node]types.Object),
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("main", fset, []*ast.File{file}, info)
+	if err != nil {
+		return err
+	}
+
+	keep := map[string]bool{"main": true, "init": true, "_": true}
+	for _, name := range implicitMethods {
+		keep[name] = true
+	}
+	for _, imp := range pkg.Imports() {
+		scope := imp.Scope()
+		for _, name := range scope.Names() {
+			if iface, ok := scope.Lookup(name).Type().Underlying().(*types.Interface); ok {
+				for i := 0; i < iface.NumMethods(); i++ {
+					keep[iface.Method(i).Name()] = true
+				}
+			}
+		}
+	}
+	reflected := reflectedFields(file, info)
+	own := make(map[string]bool)
+	add := func(obj types.Object) {
+		if obj == nil {
+			return
+		}
+		if _, ok := obj.(*types.PkgName); ok || obj.Pkg() != pkg {
+			keep[obj.Name()] = true
+			return
+		}
+		if v, ok := obj.(*types.Var); ok && reflected[v] {
+			keep[obj.Name()] = true
+			return
+		}
+		own[obj.Name()] = true
+	}
+	for _, obj := range info.Defs {
+		add(obj)
+	}
+	for _, obj := range info.Uses {
+		add(obj)
+	}
+	for _, obj := range info.Implicits {
+		add(obj)
+	}
+
+	// the most frequent names get the shortest replacements
+	count := make(map[string]int)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			count[ident.Name]++
+		}
+		return true
+	})
+	var names []string
+	for name := range own {
+		if !keep[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := names[i], names[j]
+		if count[a] != count[b] {
+			return count[a] > count[b]
+		}
+		return a < b
+	})
+	short := make(map[string]string, len(names))
+	next := 0
+	for _, name := range names {
+		for {
+			candidate := shortName(next)
+			next++
+			if !keep[candidate] && token.Lookup(candidate) == token.IDENT {
+				short[name] = candidate
+				break
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			if name, ok := short[ident.Name]; ok {
+				ident.Name = name
+			}
+		}
+		return true
+	})
+	return nil
+}
+
+// Blank the usage texts of the flags the bundle defines, which a submission
+// never prints
+func dropUsage(file *ast.File) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 3 {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+			pkg, ok := sel.X.(*ast.Ident)
+			usage, isLit := call.Args[2].(*ast.BasicLit)
+			if ok && pkg.Name == "flag" && isLit && usage.Kind == token.STRING {
+				usage.Value = `""`
+			}
+		}
+		return true
+	})
+}
+
+// Exported fields of the types handed to reflect or encoding/json, and of
+// every anonymous struct since those usually only exist to be encoded
+func reflectedFields(file *ast.File, info *types.Info) map[*types.Var]bool {
+	fields := make(map[*types.Var]bool)
+	seen := make(map[types.Type]bool)
+	var walk func(t types.Type)
+	walk = func(t types.Type) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		switch t := t.(type) {
+		case *types.Named:
+			// custom encodings hide the fields
+			if obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), true, nil, "MarshalJSON"); obj != nil {
+				return
+			}
+			walk(t.Underlying())
+		case *types.Pointer:
+			walk(t.Elem())
+		case *types.Slice:
+			walk(t.Elem())
+		case *types.Array:
+			walk(t.Elem())
+		case *types.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		case *types.Struct:
+			for i := 0; i < t.NumFields(); i++ {
+				if field := t.Field(i); field.Exported() {
+					fields[field] = true
+					walk(field.Type())
+				}
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.StructType:
+			walk(info.TypeOf(n))
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok {
+				break
+			}
+			fn, ok := info.Uses[sel.Sel].(*types.Func)
+			if !ok || fn.Pkg() == nil {
+				break
+			}
+			if path := fn.Pkg().Path(); path == "encoding/json" || path == "reflect" {
+				for _, arg := range n.Args {
+					walk(info.TypeOf(arg))
+				}
+			}
+		}
+		return true
+	})
+	return fields
+}
+
+// Name number n in the sequence a..z, A..Z, aa, ab..
+func shortName(n int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	name := []byte{letters[n%len(letters)]}
+	for n /= len(letters); n > 0; n /= len(letters) {
+		n--
+		name = append([]byte{letters[n%len(letters)]}, name...)
+	}
+	return string(name)
+}
+
+// Print file without indentation or alignment, which Go does not need
+func compact(fset *token.FileSet, file *ast.File) ([]byte, error) {
+	var out bytes.Buffer
+	config := printer.Config{Mode: printer.RawFormat}
+	if err := config.Fprint(&out, fset, file); err != nil {
+		return nil, err
+	}
+	src := out.Bytes()
+
+	// lines starting inside a raw string keep their leading blanks
+	inside := make(map[int]bool)
+	var s scanner.Scanner
+	lines := token.NewFileSet().AddFile("", -1, len(src))
+	s.Init(lines, src, nil, 0)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.STRING && strings.HasPrefix(lit, "`") {
+			first := lines.Position(pos).Line
+			for line := first + 1; line <= first+strings.Count(lit, "\n"); line++ {
+				inside[line] = true
+			}
+		}
+	}
+	var compacted bytes.Buffer
+	for i, line := range bytes.Split(src, []byte("\n")) {
+		if !inside[i+1] {
+			line = bytes.TrimLeft(line, " \t")
+			if len(line) == 0 {
+				continue
+			}
+		}
+		compacted.Write(line)
+		compacted.WriteByte('\n')
+	}
+	return compacted.Bytes(), nil
+}
@@ -0,0 +1,70 @@
+// Command cgreplay turns the game data exported from the CodinGame replay
+// viewer back into the stdin stream the bot read, for the offline replay
+// mode, and lists the actions both players printed every turn. The input is
+// only there when the bot mirrored it to stderr, as it does by default.
+//
+//	cgreplay -o game.txt -actions actions.txt replay.json
+//	go run . -replay game.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"spring2020/internal/cgreplay"
+)
+
+func main() {
+	agent := flag.Int("agent", -1, "agent whose input is rebuilt, the one that mirrored it when -1")
+	out := flag.String("o", "", "write the input stream to this file instead of stdout")
+	actions := flag.String("actions", "", "write the actions of every turn to this file, one line per turn with the agents separated by tabs")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: cgreplay [flags] replay.json")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	fail := func(err error) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+	replay, err := cgreplay.Parse(file)
+	file.Close()
+	if err != nil {
+		fail(err)
+	}
+	if *agent < 0 {
+		if *agent = replay.Mirrored(); *agent < 0 {
+			fail(fmt.Errorf("no agent mirrored its input to stderr, was the replay exported by its player?"))
+		}
+	}
+	input, err := replay.Input(*agent)
+	if err != nil {
+		fail(err)
+	}
+	if *out == "" {
+		fmt.Print(input)
+	} else if err := os.WriteFile(*out, []byte(input), 0o644); err != nil {
+		fail(err)
+	}
+	if *actions != "" {
+		var b strings.Builder
+		for turn := 0; turn < replay.Turns(); turn++ {
+			fmt.Fprintf(&b, "%d\t%s\n", turn+1, strings.Join(replay.Actions(turn), "\t"))
+		}
+		if err := os.WriteFile(*actions, []byte(b.String()), 0o644); err != nil {
+			fail(err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "agent %d %s: %d turns\n", *agent, replay.Agents[*agent], replay.Turns())
+}
@@ -0,0 +1,77 @@
+// Command harness builds the working tree and an older revision of the bot
+// and plays them against each other, printing the win rate of the working
+// tree. With -build it only leaves both binaries in -dir for external
+// referees such as cg-brutaltester. With -params and -base-params the bots
+// play with parameter overrides, A/B testing weights without code edits.
+//
+//	harness -base HEAD~1 -games 200 -parallel 4
+//	harness -params RiskWeight=2,ThreatRadius=4 -base-params RiskWeight=4
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"spring2020/internal/arena"
+	"spring2020/internal/harness"
+	"spring2020/internal/mapgen"
+	"spring2020/internal/referee"
+)
+
+func main() {
+	base := flag.String("base", "HEAD", "git revision of the opponent build")
+	dir := flag.String("dir", "dist", "directory the bot and base binaries are built into")
+	build := flag.Bool("build", false, "only build the binaries")
+	games := flag.Int("games", 100, "number of games")
+	seed := flag.Int64("seed", 0, "seed the map seeds are drawn from")
+	pacs := flag.Int("pacs", 0, "pacs per player, random when 0")
+	parallel := flag.Int("parallel", runtime.NumCPU()/2, "games played at once")
+	turn := flag.Duration("turn", referee.TurnTimeout, "turn response time limit")
+	verbose := flag.Bool("v", false, "print every game")
+	botParams := flag.String("params", "", "parameter overrides of the working tree bot, as Name=value,Name=value")
+	baseParams := flag.String("base-params", "", "parameter overrides of the base bot, which must support -params")
+	flag.Parse()
+
+	fail := func(err error) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		fail(err)
+	}
+	bot, opponent := filepath.Join(*dir, "bot"), filepath.Join(*dir, "base")
+	if err := harness.Build(".", "", bot); err != nil {
+		fail(err)
+	}
+	if err := harness.Build(".", *base, opponent); err != nil {
+		fail(err)
+	}
+	if *build {
+		return
+	}
+	opts := harness.Options{
+		Games:       *games,
+		Seed:        *seed,
+		Map:         mapgen.Options{PacsPerPlayer: *pacs},
+		Parallel:    *parallel,
+		TurnTimeout: *turn,
+	}
+	if *botParams != "" {
+		bot += " -params " + *botParams
+	}
+	if *baseParams != "" {
+		opponent += " -params " + *baseParams
+	}
+	summary, err := harness.Run(bot, opponent, opts, func(game int, record *referee.Record, result arena.Result) {
+		if *verbose {
+			fmt.Printf("game %d seed %d: %s %d-%d\n", game, result.Seed, result.Outcome, result.MyScore, result.OpponentScore)
+		}
+	})
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("working tree vs %s: %v\n", *base, summary)
+}
@@ -0,0 +1,84 @@
+// Command mapgen prints generated maps, or with -stats the shape statistics
+// of many seeds for comparing against real contest maps.
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"spring2020/internal/mapgen"
+)
+
+// Shape statistics of one map
+type Stats struct {
+	Width, Height int
+	Floor         float64
+	DeadEnds      int
+	Junctions     int
+	Tunnels       int
+}
+
+// Measure shape statistics of m
+func measure(m *mapgen.Map) Stats {
+	s := Stats{Width: m.Width, Height: m.Height}
+	floor := 0
+	for y := 0; y < m.Height; y++ {
+		if m.IsFloor(0, y) {
+			s.Tunnels++
+		}
+		for x := 0; x < m.Width; x++ {
+			if !m.IsFloor(x, y) {
+				continue
+			}
+			floor++
+			open := 0
+			for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				nx := (x + d[0] + m.Width) % m.Width
+				if m.IsFloor(nx, y+d[1]) {
+					open++
+				}
+			}
+			if open == 1 {
+				s.DeadEnds++
+			} else if open >= 3 {
+				s.Junctions++
+			}
+		}
+	}
+	s.Floor = float64(floor) / float64(m.Width*m.Height)
+	return s
+}
+
+func main() {
+	seed := flag.Int64("seed", 0, "map seed")
+	width := flag.Int("width", 0, "map width, random when 0")
+	height := flag.Int("height", 0, "map height, random when 0")
+	pacs := flag.Int("pacs", 0, "pacs per player, random when 0")
+	stats := flag.Int("stats", 0, "print mean shape statistics over this many seeds drawn from -seed")
+	flag.Parse()
+	opts := mapgen.Options{Width: *width, Height: *height, PacsPerPlayer: *pacs}
+
+	if *stats == 0 {
+		m := mapgen.Generate(*seed, opts)
+		fmt.Println(m.Width, m.Height)
+		fmt.Println(m)
+		fmt.Printf("%+v\n", measure(m))
+		return
+	}
+	// consecutive seeds give correlated first draws, so draw the seeds randomly
+	seeds := mapgen.NewRandom(*seed)
+	var sum Stats
+	for i := 0; i < *stats; i++ {
+		s := measure(mapgen.Generate(seeds.Int64(), opts))
+		sum.Width += s.Width
+		sum.Height += s.Height
+		sum.Floor += s.Floor
+		sum.DeadEnds += s.DeadEnds
+		sum.Junctions += s.Junctions
+		sum.Tunnels += s.Tunnels
+	}
+	n := float64(*stats)
+	fmt.Printf("maps %d width %.1f height %.1f floor %.2f dead ends %.2f junctions %.1f tunnels %.2f\n",
+		*stats, float64(sum.Width)/n, float64(sum.Height)/n, sum.Floor/n,
+		float64(sum.DeadEnds)/n, float64(sum.Junctions)/n, float64(sum.Tunnels)/n)
+}
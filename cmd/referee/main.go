@@ -0,0 +1,76 @@
+// Command referee plays local games between two bot builds under the
+// contest rules and reports the scores, so strategy changes can be tried
+// without submitting. Bots swap sides every other game.
+//
+//	referee -games 20 -out results.jsonl ./bot-new ./bot-old
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"spring2020/internal/arena"
+	"spring2020/internal/harness"
+	"spring2020/internal/mapgen"
+	"spring2020/internal/referee"
+)
+
+func main() {
+	seed := flag.Int64("seed", 0, "seed the map seeds are drawn from")
+	games := flag.Int("games", 1, "number of games")
+	width := flag.Int("width", 0, "map width, random when 0")
+	height := flag.Int("height", 0, "map height, random when 0")
+	pacs := flag.Int("pacs", 0, "pacs per player, random when 0")
+	out := flag.String("out", "", "append arena results of the first bot to this file")
+	logs := flag.String("logs", "", "write the stderr of the bots to files in this directory")
+	first := flag.Duration("first", referee.FirstTurnTimeout, "first turn response time limit")
+	turn := flag.Duration("turn", referee.TurnTimeout, "turn response time limit")
+	flag.Parse()
+	if flag.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: referee [flags] bot opponent")
+		os.Exit(2)
+	}
+	bots := [2]string{flag.Arg(0), flag.Arg(1)}
+
+	var results io.Writer
+	if *out != "" {
+		file, err := os.OpenFile(*out, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		results = file
+	}
+	opts := harness.Options{
+		Games:            *games,
+		Seed:             *seed,
+		Map:              mapgen.Options{Width: *width, Height: *height, PacsPerPlayer: *pacs},
+		Logs:             *logs,
+		FirstTurnTimeout: *first,
+		TurnTimeout:      *turn,
+	}
+	var writeErr error
+	summary, err := harness.Run(bots[0], bots[1], opts, func(game int, record *referee.Record, result arena.Result) {
+		fmt.Printf("game %d seed %d %dx%d %d pacs: %s %d-%d in %d turns\n", game, result.Seed, result.Width,
+			result.Height, result.PacsPerPlayer, result.Outcome, result.MyScore, result.OpponentScore, result.Turns)
+		for player, err := range record.Disqualified {
+			if err != nil {
+				fmt.Printf("  player %d disqualified: %v\n", player, err)
+			}
+		}
+		if results != nil && writeErr == nil {
+			writeErr = arena.WriteResult(results, result)
+		}
+	})
+	if err == nil {
+		err = writeErr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s vs %s: %v\n", bots[0], bots[1], summary)
+}
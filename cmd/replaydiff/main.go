@@ -0,0 +1,236 @@
+// Command replaydiff replays the same recorded game input, raw or mirrored in
+// a stderr log, on two bot builds with the same seed and reports the first
+// turn their commands diverge, with both decision traces side by side. The
+// seed defaults to the one the bot logged, so a game's own choices replay.
+//
+//	replaydiff -input game.txt -seed 42 ./bot-old ./bot-new
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"spring2020/internal/gameio"
+	"spring2020/internal/random"
+)
+
+// Stderr line the bot logs at the start of every turn
+var turnMarker = regexp.MustCompile(`^\[\d+\] Turn \d+$`)
+
+// Output of one bot run over the recorded input
+type Run struct {
+	Commands []string
+	Traces   [][]string
+}
+
+// Game input recorded in the file called name
+func readInput(name string) ([]byte, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	recording, err := gameio.ReadRecording(file)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(recording)
+}
+
+// Seed the bot logged in the file called name, 1 when it logged none
+func inputSeed(name string) string {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "1"
+	}
+	if seed, ok := random.LoggedSeed(string(data)); ok {
+		log("Replaying with logged seed", seed)
+		return seed
+	}
+	return "1"
+}
+
+// Count turns in a recorded input stream
+func countTurns(input []byte) (int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(input))
+	scanner.Buffer(make([]byte, 1000000), 1000000)
+	next := func() string {
+		scanner.Scan()
+		return scanner.Text()
+	}
+	var width, height int
+	if _, err := fmt.Sscan(next(), &width, &height); err != nil {
+		return 0, fmt.Errorf("reading map size: %w", err)
+	}
+	for i := 0; i < height; i++ {
+		next()
+	}
+	turns := 0
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var pacs, pellets int
+		if _, err := fmt.Sscan(next(), &pacs); err != nil {
+			return turns, fmt.Errorf("turn %d pac count: %w", turns+1, err)
+		}
+		for i := 0; i < pacs; i++ {
+			next()
+		}
+		if _, err := fmt.Sscan(next(), &pellets); err != nil {
+			return turns, fmt.Errorf("turn %d pellet count: %w", turns+1, err)
+		}
+		for i := 0; i < pellets; i++ {
+			next()
+		}
+		turns++
+	}
+	return turns, nil
+}
+
+// Run bot replaying the recording in file with seed until it printed a
+// command line for every turn
+func runBot(bot, file, seed string, turns int, timeout time.Duration) (*Run, error) {
+	cmd := exec.Command(bot, "-replay", file, "-seed", seed)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	run := &Run{}
+	done := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for len(run.Commands) < turns && scanner.Scan() {
+			run.Commands = append(run.Commands, scanner.Text())
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log("bot", bot, "timed out after", len(run.Commands), "turns")
+	}
+	_ = cmd.Process.Kill()
+	<-done
+	_ = cmd.Wait()
+
+	// split the decision trace on turn markers, dropping anything before turn 1
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if turnMarker.MatchString(line) {
+			run.Traces = append(run.Traces, nil)
+		} else if len(run.Traces) > 0 {
+			run.Traces[len(run.Traces)-1] = append(run.Traces[len(run.Traces)-1], line)
+		}
+	}
+	return run, nil
+}
+
+// First turn the commands of a and b differ in, -1 when they never do
+func divergence(a, b *Run, turns int) int {
+	for turn := 0; turn < turns; turn++ {
+		if a.command(turn) != b.command(turn) {
+			return turn
+		}
+	}
+	return -1
+}
+
+// Get trace of turn or nil
+func (r *Run) trace(turn int) []string {
+	if turn < len(r.Traces) {
+		return r.Traces[turn]
+	}
+	return nil
+}
+
+// Get command of turn or a placeholder when the bot printed none
+func (r *Run) command(turn int) string {
+	if turn < len(r.Commands) {
+		return r.Commands[turn]
+	}
+	return "<no output>"
+}
+
+// Cut or pad s to exactly width runes
+func column(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "~"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+// Print two traces next to each other
+func sideBySide(a, b []string, width int) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var left, right string
+		if i < len(a) {
+			left = a[i]
+		}
+		if i < len(b) {
+			right = b[i]
+		}
+		fmt.Printf("%s | %s\n", column(left, width), right)
+	}
+}
+
+func log(a ...any) {
+	_, _ = fmt.Fprintln(os.Stderr, a...)
+}
+
+func main() {
+	input := flag.String("input", "", "recorded game input, raw or mirrored in a stderr log")
+	seed := flag.String("seed", "", "seed both bots replay the game with, the one logged in the input or 1 when empty")
+	timeout := flag.Duration("timeout", 10*time.Second, "time limit per bot run")
+	width := flag.Int("width", 70, "column width of the side by side trace")
+	flag.Parse()
+	if *input == "" || flag.NArg() != 2 {
+		log("usage: replaydiff -input game.txt BOT_A BOT_B")
+		os.Exit(2)
+	}
+	data, err := readInput(*input)
+	if err != nil {
+		log(err)
+		os.Exit(1)
+	}
+	turns, err := countTurns(data)
+	if err != nil {
+		log("input:", err)
+	}
+	if *seed == "" {
+		*seed = inputSeed(*input)
+	}
+
+	a, err := runBot(flag.Arg(0), *input, *seed, turns, *timeout)
+	if err != nil {
+		log(flag.Arg(0), err)
+		os.Exit(1)
+	}
+	b, err := runBot(flag.Arg(1), *input, *seed, turns, *timeout)
+	if err != nil {
+		log(flag.Arg(1), err)
+		os.Exit(1)
+	}
+
+	if turn := divergence(a, b, turns); turn >= 0 {
+		fmt.Printf("First divergence at turn %d of %d\n\n", turn+1, turns)
+		sideBySide([]string{flag.Arg(0), a.command(turn)}, []string{flag.Arg(1), b.command(turn)}, *width)
+		fmt.Println()
+		sideBySide(a.trace(turn), b.trace(turn), *width)
+		os.Exit(1)
+	}
+	fmt.Printf("No divergence in %d turns\n", turns)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"spring2020/internal/mapgen"
+	"spring2020/internal/random"
+	"spring2020/internal/referee"
+)
+
+func TestSameBuildNeverDiverges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the bot and plays a game")
+	}
+	dir := t.TempDir()
+	bot := filepath.Join(dir, "bot")
+	if out, err := exec.Command("go", "build", "-o", bot, "spring2020").CombinedOutput(); err != nil {
+		t.Fatalf("building the bot: %v\n%s", err, out)
+	}
+	// the stderr log of a game mirrors its input and logs the seed
+	t.Setenv(random.SeedEnvVar, "99")
+	var stderr bytes.Buffer
+	match := referee.Match{
+		Seed:     3,
+		Map:      mapgen.Options{Width: 31, Height: 15, PacsPerPlayer: 4},
+		Commands: [2]string{bot, bot},
+		Stderr:   [2]io.Writer{&stderr, io.Discard},
+	}
+	if _, err := match.Play(); err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "game.log")
+	if err := os.WriteFile(input, stderr.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := readInput(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	turns, err := countTurns(data)
+	if err != nil || turns == 0 {
+		t.Fatalf("counted %d turns, %v", turns, err)
+	}
+	seed := inputSeed(input)
+	if seed != "99" {
+		t.Errorf("read seed %s from the log, want 99", seed)
+	}
+	a, err := runBot(bot, input, seed, turns, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := runBot(bot, input, seed, turns, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Commands) != turns {
+		t.Errorf("bot answered %d of %d turns", len(a.Commands), turns)
+	}
+	if turn := divergence(a, b, turns); turn >= 0 {
+		t.Errorf("diverges at turn %d: %q against %q", turn+1, a.command(turn), b.command(turn))
+	}
+}
@@ -0,0 +1,90 @@
+// Command tune searches bot parameters with the cross-entropy method. It
+// builds the working tree once and scores every sampled parameter set by
+// its win rate against the same build with its compiled in parameters, all
+// sets of a generation playing the same maps. The final mean is printed as
+// overrides for the bot, the harness or the compiled in defaults. Games are
+// played on mapgen maps, which share the shape of contest maps but are not
+// the referee's own, so tuned values are worth checking in the contest.
+//
+//	tune -names RiskWeight,ThreatRadius,BeamRiskCost -generations 8 -games 60
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"spring2020/internal/harness"
+	"spring2020/internal/mapgen"
+	"spring2020/internal/params"
+	"spring2020/internal/referee"
+	"spring2020/internal/tuner"
+)
+
+func main() {
+	names := flag.String("names", "RiskWeight,ValueWeight,ThreatRadius,BeamRiskCost,DangerWeight", "comma separated parameters to tune")
+	dir := flag.String("dir", "dist", "directory the bot binary is built into")
+	generations := flag.Int("generations", 10, "generations")
+	population := flag.Int("population", 12, "parameter sets scored per generation")
+	elite := flag.Int("elite", 0, "best sets the distribution moves to, a quarter of the population when 0")
+	spread := flag.Float64("spread", 0.3, "initial standard deviation as a share of each value")
+	smoothing := flag.Float64("smoothing", 0.3, "share of the old distribution kept per generation")
+	games := flag.Int("games", 40, "games per parameter set")
+	seed := flag.Int64("seed", 0, "seed of the samples and maps")
+	pacs := flag.Int("pacs", 0, "pacs per player, random when 0")
+	parallel := flag.Int("parallel", runtime.NumCPU()/2, "games played at once")
+	turn := flag.Duration("turn", referee.TurnTimeout, "turn response time limit")
+	flag.Parse()
+
+	fail := func(err error) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		fail(err)
+	}
+	bot := filepath.Join(*dir, "tune")
+	if err := harness.Build(".", "", bot); err != nil {
+		fail(err)
+	}
+	opts := tuner.Options{
+		Base:        params.Default,
+		Names:       strings.Split(*names, ","),
+		Spread:      *spread,
+		Generations: *generations,
+		Population:  *population,
+		Elite:       *elite,
+		Smoothing:   *smoothing,
+		Seed:        *seed,
+	}
+	// every generation plays its own maps, the same for all of its sets
+	mapSeeds := mapgen.NewRandom(*seed)
+	genSeeds := make([]int64, *generations)
+	for i := range genSeeds {
+		genSeeds[i] = mapSeeds.Int64()
+	}
+	evaluate := func(_ params.Params, spec string, generation int) (float64, error) {
+		summary, err := harness.Run(bot+" -params "+spec, bot, harness.Options{
+			Games:       *games,
+			Seed:        genSeeds[generation],
+			Map:         mapgen.Options{PacsPerPlayer: *pacs},
+			Parallel:    *parallel,
+			TurnTimeout: *turn,
+		}, nil)
+		fmt.Printf("  %s: %v\n", spec, summary)
+		return summary.WinRate(), err
+	}
+	best, err := tuner.Run(opts, evaluate, func(generation int, ranked []tuner.Candidate, dims []tuner.Dimension) {
+		fmt.Printf("generation %d best %.1f%% %s\n", generation, 100*ranked[0].Score, ranked[0].Spec)
+		for _, d := range dims {
+			fmt.Printf("  %s %.3g ± %.3g\n", d.Name, d.Mean, d.Std)
+		}
+	})
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("tuned: -params %s\n", best.Spec)
+}
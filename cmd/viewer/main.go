@@ -0,0 +1,40 @@
+// Command viewer serves an interactive board view of a state export written
+// by the bot with -export, for replays and local games.
+//
+//	go run . -replay game.txt -export turns.jsonl
+//	viewer -addr localhost:8020 turns.jsonl
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"spring2020/internal/viewer"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8020", "address to serve on")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: viewer [-addr host:port] export.jsonl")
+		os.Exit(2)
+	}
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	turns, err := viewer.ReadTurns(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("serving %d turns on http://%s\n", len(turns), *addr)
+	if err := http.ListenAndServe(*addr, viewer.Handler(turns)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
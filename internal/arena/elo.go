@@ -0,0 +1,73 @@
+package arena
+
+import "math"
+
+// Rating every contender starts from
+const InitialRating = 1500
+
+// Rating change per game for a full point above expectation
+const DefaultK = 16
+
+// Score shares closer to 0 or 1 than this are clamped, a clean sweep does
+// not make an infinite Elo difference
+const scoreClamp = 0.001
+
+// Points a result is worth to the bot: 1 for a win, half for a draw
+func (o Outcome) Points() float64 {
+	switch o {
+	case Win:
+		return 1
+	case Draw:
+		return 0.5
+	}
+	return 0
+}
+
+// Elo difference of a player expected to take share p of the points
+// against another
+func EloDiff(p float64) float64 {
+	p = math.Min(math.Max(p, scoreClamp), 1-scoreClamp)
+	return -400 * math.Log10(1/p-1)
+}
+
+// Elo difference implied by wins, draws and losses with the bounds of its
+// 95% confidence interval, taken from the interval of the score share
+func EloInterval(wins, draws, losses int) (diff, low, high float64) {
+	n := float64(wins + draws + losses)
+	if n == 0 {
+		return 0, 0, 0
+	}
+	p := (float64(wins) + float64(draws)/2) / n
+	variance := (float64(wins)*(1-p)*(1-p) + float64(draws)*(0.5-p)*(0.5-p) + float64(losses)*p*p) / n
+	margin := 1.96 * math.Sqrt(variance/n)
+	return EloDiff(p), EloDiff(p - margin), EloDiff(p + margin)
+}
+
+// Elo ratings of contenders updated game by game
+type Ratings struct {
+	K       float64
+	ratings map[string]float64
+}
+
+// Create ratings moving K points per full point above expectation, every
+// contender starting at InitialRating
+func NewRatings(k float64) *Ratings {
+	return &Ratings{K: k, ratings: make(map[string]float64)}
+}
+
+// Rating of contender
+func (r *Ratings) Rating(name string) float64 {
+	if rating, ok := r.ratings[name]; ok {
+		return rating
+	}
+	return InitialRating
+}
+
+// Rate a game of a against b where a took points, 1 for a win
+func (r *Ratings) Update(a, b string, points float64) {
+	ra, rb := r.Rating(a), r.Rating(b)
+	expected := 1 / (1 + math.Pow(10, (rb-ra)/400))
+	delta := r.K * (points - expected)
+	r.ratings[a] = ra + delta
+	r.ratings[b] = rb - delta
+}
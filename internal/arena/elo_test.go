@@ -0,0 +1,41 @@
+package arena
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEloDiff(t *testing.T) {
+	if d := EloDiff(0.5); d != 0 {
+		t.Errorf("even score gave %.1f, want 0", d)
+	}
+	// three points out of four is about 191 Elo
+	if d := EloDiff(0.75); math.Abs(d-190.8) > 0.1 {
+		t.Errorf("75%% score gave %.1f, want 190.8", d)
+	}
+	if d := EloDiff(1); math.IsInf(d, 0) || d <= 0 {
+		t.Errorf("clean sweep gave %v, want a finite lead", d)
+	}
+}
+
+func TestEloInterval(t *testing.T) {
+	diff, low, high := EloInterval(55, 0, 45)
+	if !(low < diff && diff < high) || low > 0 {
+		t.Errorf("got %.1f in [%.1f, %.1f], want an interval around it reaching below 0", diff, low, high)
+	}
+	_, narrowLow, narrowHigh := EloInterval(550, 0, 450)
+	if narrowHigh-narrowLow >= high-low {
+		t.Error("ten times the games did not narrow the interval")
+	}
+}
+
+func TestRatingsUpdate(t *testing.T) {
+	r := NewRatings(DefaultK)
+	r.Update("a", "b", Win.Points())
+	if a, b := r.Rating("a"), r.Rating("b"); a != InitialRating+DefaultK/2 || b != InitialRating-DefaultK/2 {
+		t.Errorf("got %.1f and %.1f after an even game won", a, b)
+	}
+	if sum := r.Rating("a") + r.Rating("b"); sum != 2*InitialRating {
+		t.Errorf("ratings sum to %.1f, want them kept", sum)
+	}
+}
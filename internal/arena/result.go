@@ -0,0 +1,64 @@
+// Package arena holds the records of offline games between two bots.
+package arena
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Outcome of a game for the evaluated bot
+type Outcome string
+
+// Game outcomes
+const (
+	Win  Outcome = "win"
+	Draw Outcome = "draw"
+	Loss Outcome = "loss"
+)
+
+// Result of one game, one JSON line per game in arena result files
+type Result struct {
+	Seed          int64   `json:"seed"`
+	Width         int     `json:"width"`
+	Height        int     `json:"height"`
+	PacsPerPlayer int     `json:"pacs_per_player"`
+	Bot           string  `json:"bot"`
+	Opponent      string  `json:"opponent"`
+	Outcome       Outcome `json:"outcome"`
+	Turns         int     `json:"turns"`
+	MyScore       int     `json:"my_score"`
+	OpponentScore int     `json:"opponent_score"`
+	// Bot missed the turn deadline or crashed
+	Timeout          bool `json:"timeout,omitempty"`
+	MyPacsLost       int  `json:"my_pacs_lost"`
+	OpponentPacsLost int  `json:"opponent_pacs_lost"`
+	MySupers         int  `json:"my_supers"`
+	OpponentSupers   int  `json:"opponent_supers"`
+	// JSONL decision log of the bot for this game, if recorded
+	DecisionLog string `json:"decision_log,omitempty"`
+}
+
+// Read results from JSON lines
+func ReadResults(r io.Reader) ([]Result, error) {
+	var results []Result
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1000000), 1000000)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var result Result
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			return results, fmt.Errorf("line %d: %w", line, err)
+		}
+		results = append(results, result)
+	}
+	return results, scanner.Err()
+}
+
+// Write result as a JSON line
+func WriteResult(w io.Writer, result Result) error {
+	return json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,55 @@
+// Package budget tracks the time left to answer a turn, so planning can stop
+// with the best it found so far and still publish before the referee's
+// deadline.
+package budget
+
+import (
+	"math"
+	"time"
+)
+
+// Share of a turn budget kept for the cheap fallback moves once searches stop
+const ReserveShare = 4
+
+// Time budget of a turn, started when its first input line is read. A nil
+// budget or one without a limit never runs low, which is how replays and
+// tests plan.
+type TurnBudget struct {
+	start time.Time
+	limit time.Duration
+}
+
+// Create budget of limit starting now, unlimited when limit is 0
+func NewTurnBudget(limit time.Duration) *TurnBudget {
+	return &TurnBudget{start: time.Now(), limit: limit}
+}
+
+// Time spent since the turn started
+func (b *TurnBudget) Elapsed() time.Duration {
+	if b == nil {
+		return 0
+	}
+	return time.Since(b.start)
+}
+
+// Time left until the deadline
+func (b *TurnBudget) Remaining() time.Duration {
+	if b == nil || b.limit == 0 {
+		return math.MaxInt64
+	}
+	return b.limit - time.Since(b.start)
+}
+
+// Check if searches should return their best result so far, leaving the
+// reserve to the moves still to be decided
+func (b *TurnBudget) Low() bool {
+	if b == nil || b.limit == 0 {
+		return false
+	}
+	return b.Remaining() < b.limit/ReserveShare
+}
+
+// Check if the deadline passed
+func (b *TurnBudget) Expired() bool {
+	return b.Remaining() <= 0
+}
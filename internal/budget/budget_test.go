@@ -0,0 +1,30 @@
+package budget_test
+
+import (
+	"testing"
+	"time"
+
+	"spring2020/internal/budget"
+)
+
+func TestTurnBudget(t *testing.T) {
+	var none *budget.TurnBudget
+	if none.Low() || none.Expired() || none.Elapsed() != 0 {
+		t.Error("nil budget runs out")
+	}
+	if unlimited := budget.NewTurnBudget(0); unlimited.Low() || unlimited.Expired() {
+		t.Error("unlimited budget runs out")
+	}
+	b := budget.NewTurnBudget(40 * time.Millisecond)
+	if b.Low() || b.Expired() {
+		t.Fatal("fresh budget already low")
+	}
+	time.Sleep(32 * time.Millisecond)
+	if !b.Low() {
+		t.Error("budget not low within its reserve")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if !b.Expired() {
+		t.Error("budget not expired past its limit")
+	}
+}
@@ -0,0 +1,159 @@
+// Package cgreplay imports the game data the CodinGame replay viewer
+// exports: the outputs of both players every turn and the stderr of the
+// player who downloaded it, which holds the input the bot mirrored there.
+package cgreplay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"spring2020/internal/gameio"
+)
+
+// Game data of a replay, indexed by agent then turn
+type Replay struct {
+	// Pseudo of each agent's player, empty when the export leaves it out
+	Agents []string
+	// Stdout of each agent every turn
+	Outputs [][]string
+	// Stderr of each agent every turn, empty where CodinGame hides it
+	Errors [][]string
+}
+
+// Frame of the replay viewer export, one agent's turn or a referee frame
+type frame struct {
+	AgentId *int   `json:"agentId"`
+	Stdout  string `json:"stdout"`
+	Stderr  string `json:"stderr"`
+}
+
+// Agent of the replay viewer export
+type agent struct {
+	Index      int `json:"index"`
+	Codingamer struct {
+		Pseudo string `json:"pseudo"`
+	} `json:"codingamer"`
+}
+
+// Game result as the replay viewer exports it, sometimes wrapped in the
+// answer of the game service, or as the local SDK writes it
+type export struct {
+	GameResult *export `json:"gameResult"`
+	Frames     []frame `json:"frames"`
+	Agents     []agent `json:"agents"`
+	// SDK format: outputs and errors per agent index per frame
+	Outputs map[string][]*string `json:"outputs"`
+	Errors  map[string][]*string `json:"errors"`
+}
+
+// Parse the JSON game data of a replay
+func Parse(r io.Reader) (*Replay, error) {
+	var e export
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, fmt.Errorf("replay JSON: %w", err)
+	}
+	if e.GameResult != nil {
+		e = *e.GameResult
+	}
+	replay := &Replay{}
+	grow := func(n int) {
+		for len(replay.Outputs) < n {
+			replay.Agents = append(replay.Agents, "")
+			replay.Outputs = append(replay.Outputs, nil)
+			replay.Errors = append(replay.Errors, nil)
+		}
+	}
+	for _, a := range e.Agents {
+		grow(a.Index + 1)
+		replay.Agents[a.Index] = a.Codingamer.Pseudo
+	}
+	for _, f := range e.Frames {
+		if f.AgentId == nil || *f.AgentId < 0 {
+			continue
+		}
+		id := *f.AgentId
+		grow(id + 1)
+		replay.Outputs[id] = append(replay.Outputs[id], strings.TrimRight(f.Stdout, "\n"))
+		replay.Errors[id] = append(replay.Errors[id], f.Stderr)
+	}
+	for key, outputs := range e.Outputs {
+		var id int
+		if _, err := fmt.Sscan(key, &id); err != nil || id < 0 {
+			return nil, fmt.Errorf("replay JSON: agent %q", key)
+		}
+		grow(id + 1)
+		for i, output := range outputs {
+			// the SDK keeps a frame for every agent, null for those not playing it
+			if output == nil {
+				continue
+			}
+			replay.Outputs[id] = append(replay.Outputs[id], strings.TrimRight(*output, "\n"))
+			text := ""
+			if errs := e.Errors[key]; i < len(errs) && errs[i] != nil {
+				text = *errs[i]
+			}
+			replay.Errors[id] = append(replay.Errors[id], text)
+		}
+	}
+	if len(replay.Outputs) == 0 {
+		return nil, fmt.Errorf("replay JSON: no agent output")
+	}
+	return replay, nil
+}
+
+// Index of the agent whose stderr holds its mirrored input, -1 when none
+func (r *Replay) Mirrored() int {
+	for id, errs := range r.Errors {
+		for _, text := range errs {
+			if strings.Contains(text, gameio.InputPrefix) {
+				return id
+			}
+		}
+	}
+	return -1
+}
+
+// Stdin stream agent read, rebuilt from the input its bot mirrored to
+// stderr, ready for the offline replay mode
+func (r *Replay) Input(id int) (string, error) {
+	if id < 0 || id >= len(r.Errors) {
+		return "", fmt.Errorf("no agent %d", id)
+	}
+	var lines []string
+	for _, text := range r.Errors[id] {
+		for _, line := range strings.Split(text, "\n") {
+			if strings.HasPrefix(line, gameio.InputPrefix) {
+				lines = append(lines, strings.TrimPrefix(line, gameio.InputPrefix))
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return "", fmt.Errorf("agent %d mirrored no input to stderr", id)
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// Turns played, the most outputs of an agent
+func (r *Replay) Turns() int {
+	turns := 0
+	for _, outputs := range r.Outputs {
+		if len(outputs) > turns {
+			turns = len(outputs)
+		}
+	}
+	return turns
+}
+
+// Output of every agent on turn, counted from 0, empty for an agent that
+// printed nothing
+func (r *Replay) Actions(turn int) []string {
+	actions := make([]string, len(r.Outputs))
+	for id, outputs := range r.Outputs {
+		if turn < len(outputs) {
+			actions[id] = outputs[turn]
+		}
+	}
+	return actions
+}
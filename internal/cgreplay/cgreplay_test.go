@@ -0,0 +1,60 @@
+package cgreplay
+
+import (
+	"strings"
+	"testing"
+)
+
+// Two turns of a replay viewer export, my bot agent 1 mirroring its input
+const viewerExport = `{"gameResult": {
+	"agents": [{"index": 0, "codingamer": {"pseudo": "them"}}, {"index": 1, "codingamer": {"pseudo": "me"}}],
+	"frames": [
+		{"gameInformation": "start", "keyframe": true},
+		{"agentId": 0, "stdout": "MOVE 0 1 1\n"},
+		{"agentId": 1, "stdout": "MOVE 0 5 1 c\n", "stderr": "<< 7 3\n<< #######\nTurn 1\n<< 0 0\n"},
+		{"agentId": 0, "stdout": "SPEED 0\n"},
+		{"agentId": 1, "stdout": "MOVE 0 4 1\n", "stderr": "<< 2 0\n"}
+	]
+}}`
+
+func TestParseViewerExport(t *testing.T) {
+	replay, err := Parse(strings.NewReader(viewerExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Agents[1] != "me" || replay.Turns() != 2 {
+		t.Errorf("got agents %q over %d turns", replay.Agents, replay.Turns())
+	}
+	if id := replay.Mirrored(); id != 1 {
+		t.Fatalf("agent %d mirrored its input, want 1", id)
+	}
+	input, err := replay.Input(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "7 3\n#######\n0 0\n2 0\n"; input != want {
+		t.Errorf("got input %q, want %q", input, want)
+	}
+	if got := replay.Actions(1); got[0] != "SPEED 0" || got[1] != "MOVE 0 4 1" {
+		t.Errorf("got turn 2 actions %q", got)
+	}
+	if _, err := replay.Input(0); err == nil {
+		t.Error("rebuilt input of an agent that mirrored none")
+	}
+}
+
+func TestParseSDKResult(t *testing.T) {
+	replay, err := Parse(strings.NewReader(`{
+		"outputs": {"0": [null, "MOVE 0 1 1\n"], "1": [null, "MOVE 1 2 2\n"]},
+		"errors": {"0": [null, "<< 5 5\n"], "1": [null, null]}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Turns() != 1 || replay.Actions(0)[1] != "MOVE 1 2 2" {
+		t.Errorf("got %d turns, actions %q", replay.Turns(), replay.Actions(0))
+	}
+	if input, err := replay.Input(0); err != nil || input != "5 5\n" {
+		t.Errorf("got input %q, %v", input, err)
+	}
+}
@@ -0,0 +1,114 @@
+// Package fixture builds game states from mazes drawn as string literals, for
+// tests. A maze row uses '#' for walls, ' ' for empty floor, '.' for a
+// pellet, 'o' for a super pellet, digits for my pacs and the letters a to e
+// for opponent pacs 0 to 4, all on floor cells.
+package fixture
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/mapgen"
+	"spring2020/internal/params"
+	"spring2020/internal/state"
+)
+
+// Type of the pacs of a fixture
+const PacType = "ROCK"
+
+// Size and pacs per player of the largest contest maps
+const (
+	ContestWidth  = 35
+	ContestHeight = 17
+	ContestPacs   = 5
+)
+
+// Grid of the maze rows with neighbors linked
+func Grid(rows ...string) [][]*grid.Cell {
+	cells := make([][]*grid.Cell, len(rows))
+	for y, row := range rows {
+		cells[y] = make([]*grid.Cell, len(row))
+		for x, c := range row {
+			cells[y][x] = &grid.Cell{X: x, Y: y, IsWall: c == '#'}
+		}
+	}
+	for _, row := range cells {
+		for _, cell := range row {
+			cell.InitNeighbors(cells)
+		}
+	}
+	return cells
+}
+
+// Game on its first turn with the pacs and pellets of the maze rows, the
+// distance table and corridor graph computed and the default parameters
+func Game(rows ...string) *state.Game {
+	g := &state.Game{
+		Turn:         1,
+		Width:        len(rows[0]),
+		Height:       len(rows),
+		Grid:         Grid(rows...),
+		MyPacs:       make([]*state.Pac, 0),
+		OpponentPacs: make([]*state.Pac, 0),
+		Params:       params.Default,
+	}
+	g.Pellet = state.NewPelletStore(g.Width, g.Height)
+	mirrored := g.Symmetric()
+	g.Dist = grid.NewDistanceTable(g.Grid, mirrored)
+	g.Corridors = grid.NewCorridorGraph(g.Grid, mirrored)
+	g.DeadEnds = grid.NewDeadEnds(g.Grid)
+	g.Zobrist = state.NewZobrist(g.Width, g.Height)
+	g.Walls = grid.WallBoard(g.Grid)
+	for y, row := range rows {
+		for x, c := range row {
+			switch {
+			case c == '.':
+				g.AddPellet(0, x, y, 1)
+			case c == 'o':
+				g.AddPellet(0, x, y, 10)
+			case c >= '0' && c <= '9':
+				g.AddPac(int(c-'0'), 1, x, y, PacType, 0, 0)
+			case c >= 'a' && c <= 'e':
+				g.AddPac(int(c-'a'), 0, x, y, PacType, 0, 0)
+			}
+		}
+	}
+	return g
+}
+
+// My pac id of g, nil when there is none
+func Pac(g *state.Game, id int) *state.Pac {
+	for _, pac := range g.MyPacs {
+		if pac.Id == id {
+			return pac
+		}
+	}
+	return nil
+}
+
+// Maze rows of the largest contest map generated from seed on its first
+// turn: a pellet on every free floor cell, the super pellets and both
+// players' pacs
+func ContestMaze(seed int64) []string {
+	m := mapgen.Generate(seed, mapgen.Options{Width: ContestWidth, Height: ContestHeight, PacsPerPlayer: ContestPacs})
+	rows := make([][]byte, m.Height)
+	for y, row := range m.Rows {
+		rows[y] = []byte(string(row))
+		for x, c := range row {
+			if c == ' ' {
+				rows[y][x] = '.'
+			}
+		}
+	}
+	for _, p := range m.Supers {
+		rows[p.Y][p.X] = 'o'
+	}
+	for _, pac := range m.Pacs {
+		mirror := m.Mirror(pac.Point)
+		rows[pac.Point.Y][pac.Point.X] = byte('0' + pac.Id)
+		rows[mirror.Y][mirror.X] = byte('a' + pac.Id)
+	}
+	maze := make([]string, m.Height)
+	for y, row := range rows {
+		maze[y] = string(row)
+	}
+	return maze
+}
@@ -0,0 +1,117 @@
+package gameio
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Command of one pac for a turn
+type Command interface {
+	// Pac the command is for
+	PacId() int
+	// Command text without label
+	String() string
+}
+
+// Move a pac towards a cell
+type Move struct {
+	Pac, X, Y int
+}
+
+func (c Move) PacId() int { return c.Pac }
+
+func (c Move) String() string {
+	return fmt.Sprintf("MOVE %d %d %d", c.Pac, c.X, c.Y)
+}
+
+// Activate SPEED
+type Speed struct {
+	Pac int
+}
+
+func (c Speed) PacId() int { return c.Pac }
+
+func (c Speed) String() string {
+	return fmt.Sprintf("SPEED %d", c.Pac)
+}
+
+// Switch a pac to another type
+type Switch struct {
+	Pac  int
+	Type string
+}
+
+func (c Switch) PacId() int { return c.Pac }
+
+func (c Switch) String() string {
+	return fmt.Sprintf("SWITCH %d %s", c.Pac, c.Type)
+}
+
+// Hold a pac on its cell, there is no wait command so it moves to where it stands
+type Wait struct {
+	Pac, X, Y int
+}
+
+func (c Wait) PacId() int { return c.Pac }
+
+func (c Wait) String() string {
+	return fmt.Sprintf("MOVE %d %d %d", c.Pac, c.X, c.Y)
+}
+
+// Commands of my pacs for a turn, at most one per pac, in the order their
+// pacs were first given one
+type CommandSet struct {
+	order    []int
+	commands map[int]Command
+	labels   map[int]string
+}
+
+// Create empty command set
+func NewCommandSet() *CommandSet {
+	return &CommandSet{commands: make(map[int]Command), labels: make(map[int]string)}
+}
+
+// Add command, failing when its pac already has one
+func (s *CommandSet) Add(c Command) error {
+	if _, ok := s.commands[c.PacId()]; ok {
+		return fmt.Errorf("pac %d already has command %s", c.PacId(), s.commands[c.PacId()])
+	}
+	s.Set(c)
+	return nil
+}
+
+// Set command, replacing the one its pac had
+func (s *CommandSet) Set(c Command) {
+	if _, ok := s.commands[c.PacId()]; !ok {
+		s.order = append(s.order, c.PacId())
+	}
+	s.commands[c.PacId()] = c
+}
+
+// Command of pac, nil when it has none
+func (s *CommandSet) Get(pacId int) Command {
+	return s.commands[pacId]
+}
+
+// Attach a debug label printed after the command of pac
+func (s *CommandSet) Label(pacId int, label string) {
+	s.labels[pacId] = strings.ReplaceAll(label, "|", "/")
+}
+
+// Command of pac with its label
+func (s *CommandSet) Render(pacId int) string {
+	text := s.commands[pacId].String()
+	if label := s.labels[pacId]; label != "" {
+		text += " " + label
+	}
+	return text
+}
+
+// Output line of all commands
+func (s *CommandSet) String() string {
+	texts := make([]string, len(s.order))
+	for i, id := range s.order {
+		texts[i] = s.Render(id)
+	}
+	return strings.Join(texts, "|")
+}
@@ -0,0 +1,100 @@
+package gameio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Turns of raw input kept for crash dumps
+const CrashDumpTurns = 5
+
+// Bytes of the crash dump copied to stderr
+const CrashStderrLimit = 4000
+
+// Prefix of the input lines mirrored to stderr, telling them apart from logs
+const InputPrefix = "<< "
+
+// Input reader keeping the initialization lines and the last turns of raw
+// input, so a crash can be reproduced from the dump
+type InputReader struct {
+	scanner *bufio.Scanner
+	header  []string
+	turns   [][]string
+	closed  bool
+	// mirror of every line read, with its prefix
+	record io.Writer
+	prefix string
+}
+
+// Create input reader on the referee input, stdin or a recorded game
+func NewInputReader(input io.Reader) *InputReader {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 1000000), 1000000)
+	return &InputReader{scanner: scanner}
+}
+
+// Read next line, recording it into the current turn
+func (r *InputReader) Line() string {
+	if !r.scanner.Scan() {
+		r.closed = true
+	}
+	line := r.scanner.Text()
+	if r.record != nil && !r.closed {
+		fmt.Fprintln(r.record, r.prefix+line)
+	}
+	if len(r.turns) == 0 {
+		r.header = append(r.header, line)
+	} else {
+		r.turns[len(r.turns)-1] = append(r.turns[len(r.turns)-1], line)
+	}
+	return line
+}
+
+// Mirror every line read from now on to w, prefixed with prefix
+func (r *InputReader) Record(w io.Writer, prefix string) {
+	r.record = w
+	r.prefix = prefix
+}
+
+// Recorded game input from a file holding either the raw input or a stderr
+// log with the input mirrored into it, such as the one CodinGame shows
+func ReadRecording(file io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(string(data), InputPrefix) {
+		return strings.NewReader(string(data)), nil
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, InputPrefix) {
+			lines = append(lines, strings.TrimPrefix(line, InputPrefix))
+		}
+	}
+	return strings.NewReader(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// Check if the input ended, which is how the referee ends the game
+func (r *InputReader) Closed() bool {
+	return r.closed
+}
+
+// Start recording a new turn, forgetting turns older than CrashDumpTurns
+func (r *InputReader) StartTurn() {
+	r.turns = append(r.turns, nil)
+	if len(r.turns) > CrashDumpTurns {
+		r.turns = r.turns[1:]
+	}
+}
+
+// Recorded input as a replayable stream
+func (r *InputReader) Recorded() string {
+	lines := append([]string{}, r.header...)
+	for _, turn := range r.turns {
+		lines = append(lines, turn...)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,169 @@
+// Package gameio reads the referee input into the game state and prints the
+// commands of a turn.
+package gameio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/protocol"
+	"spring2020/internal/state"
+)
+
+// Read the map sent before the first turn into game and precompute its
+// distances
+func ReadGrid(in *InputReader, game *state.Game) {
+	// width: size of the grid
+	// height: top left corner is (x=0, y=0)
+	fmt.Sscan(in.Line(), &game.Width, &game.Height)
+	game.Pellet = state.NewPelletStore(game.Width, game.Height)
+	game.Grid = make([][]*grid.Cell, game.Height)
+	for i := range game.Grid {
+		row := in.Line()
+		game.Grid[i] = make([]*grid.Cell, game.Width)
+		for j, c := range row {
+			game.Grid[i][j] = &grid.Cell{
+				X:      j,
+				Y:      i,
+				IsWall: c == '#',
+			}
+		}
+	}
+
+	for _, cells := range game.Grid {
+		for _, cell := range cells {
+			cell.InitNeighbors(game.Grid)
+		}
+	}
+	start := time.Now()
+	// mirrored maps precompute one half and look the other up mirrored
+	mirrored := game.Symmetric()
+	game.Dist = grid.NewDistanceTable(game.Grid, mirrored)
+	game.Corridors = grid.NewCorridorGraph(game.Grid, mirrored)
+	game.DeadEnds = grid.NewDeadEnds(game.Grid)
+	game.Zobrist = state.NewZobrist(game.Width, game.Height)
+	game.Walls = grid.WallBoard(game.Grid)
+	logger.Info("Distance table took", time.Since(start))
+}
+
+// Read the scores starting a turn into game
+func ReadScores(in *InputReader, game *state.Game) {
+	myScore, opponentScore := game.MyScore, game.OpponentScore
+	fmt.Sscan(in.Line(), &game.MyScore, &game.OpponentScore)
+	game.MyGain, game.OpponentGain = game.MyScore-myScore, game.OpponentScore-opponentScore
+}
+
+// Type of the pacs of leagues without types, which never beat one another
+const NeutralType = "NEUTRAL"
+
+// Pac line of the entities input
+type PacLine struct {
+	Id, Mine, X, Y  int
+	TypeId          string
+	SpeedTurnsLeft  int
+	AbilityCooldown int
+}
+
+// Parse a pac line, whatever league sent it: "pacId mine x y" at least,
+// then typeId, speedTurnsLeft and abilityCooldown where the league has them.
+// Wood leagues leave them out or send a type without abilities, so a pac
+// without a known type is NEUTRAL, and one without ability data never gets
+// to use an ability.
+func ParsePac(line string) (PacLine, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return PacLine{}, fmt.Errorf("%q has %d fields, want at least 4", line, len(fields))
+	}
+	var ints [6]int
+	for i, at := range []int{0, 1, 2, 3, 5, 6} {
+		if at >= len(fields) {
+			break
+		}
+		n, err := strconv.Atoi(fields[at])
+		if err != nil {
+			return PacLine{}, fmt.Errorf("%q field %d: %v", line, at+1, err)
+		}
+		ints[i] = n
+	}
+	pac := PacLine{Id: ints[0], Mine: ints[1], X: ints[2], Y: ints[3], TypeId: NeutralType}
+	if len(fields) > 4 {
+		pac.TypeId = strings.ToUpper(fields[4])
+	}
+	if len(fields) < 7 || !knownType(pac.TypeId) {
+		pac.AbilityCooldown = state.NoAbilityCooldown
+		if !knownType(pac.TypeId) {
+			pac.TypeId = NeutralType
+		}
+		return pac, nil
+	}
+	pac.SpeedTurnsLeft, pac.AbilityCooldown = ints[4], ints[5]
+	return pac, nil
+}
+
+// Check if the referee may send typeId in a league with abilities
+func knownType(typeId string) bool {
+	if typeId == "DEAD" {
+		return true
+	}
+	for _, t := range protocol.PacTypes {
+		if t == typeId {
+			return true
+		}
+	}
+	return false
+}
+
+// Read the pacs and pellets in sight into game, updating what is known
+// about the cells out of sight
+func ReadEntities(in *InputReader, game *state.Game) {
+	// visiblePacCount: all your pacs and enemy pacs in sight
+	var visiblePacCount int
+	fmt.Sscan(in.Line(), &visiblePacCount)
+	game.VisiblePacCount = visiblePacCount
+	logger.Trace("Visible pac count", visiblePacCount)
+	for i := 0; i < visiblePacCount; i++ {
+		line := in.Line()
+		pac, err := ParsePac(line)
+		if err != nil {
+			logger.Info("Pac line", i, "skipped:", err)
+			continue
+		}
+		logger.Trace("pac id", pac.Id, "mine", pac.Mine, "x", pac.X, "y", pac.Y, "type id", pac.TypeId, "speed turns left",
+			pac.SpeedTurnsLeft, "ability cooldown", pac.AbilityCooldown)
+		state.Check(pac.X >= 0 && pac.X < game.Width && pac.Y >= 0 && pac.Y < game.Height && !grid.GetCell(pac.X, pac.Y, game.Grid).IsWall,
+			"pac %d at (%d, %d) is not on a floor cell", pac.Id, pac.X, pac.Y)
+		game.AddPac(pac.Id, pac.Mine, pac.X, pac.Y, pac.TypeId, pac.SpeedTurnsLeft, pac.AbilityCooldown)
+	}
+	if game.Turn == 1 {
+		game.SeedPellets()
+	}
+	game.RemoveDeadPacs()
+	// pellets in sight are listed again if they are still there
+	game.UpdateVisibility()
+	game.InferEnemyDeaths()
+	game.MarkContacts()
+	believed := game.ValueInSight()
+	game.CountMyHarvest()
+	game.ForgetObservedPellets()
+	game.InferEnemyHarvest()
+	// visiblePelletCount: all pellets in sight
+	var visiblePelletCount int
+	fmt.Sscan(in.Line(), &visiblePelletCount)
+	game.VisiblePalleteCount = visiblePelletCount
+	for i := 0; i < visiblePelletCount; i++ {
+		// value: amount of points this pellet is worth
+		var x, y, value int
+		fmt.Sscan(in.Line(), &x, &y, &value)
+		game.AddPellet(i, x, y, value)
+	}
+
+	if game.Turn == 1 {
+		game.MirrorSuperPellets()
+	} else {
+		game.InferHiddenHarvest(believed - game.ValueInSight())
+	}
+}
@@ -0,0 +1,34 @@
+package gameio
+
+import (
+	"testing"
+
+	"spring2020/internal/state"
+)
+
+func TestParsePacAcrossLeagues(t *testing.T) {
+	tests := []struct {
+		line string
+		want PacLine
+	}{
+		{"0 1 5 3 ROCK 2 7", PacLine{Id: 0, Mine: 1, X: 5, Y: 3, TypeId: "ROCK", SpeedTurnsLeft: 2, AbilityCooldown: 7}},
+		{"1 0 4 2 DEAD 0 0", PacLine{Id: 1, X: 4, Y: 2, TypeId: "DEAD"}},
+		// wood leagues send a type without abilities or no type at all
+		{"2 1 6 1 NEUTRAL 0 0", PacLine{Id: 2, Mine: 1, X: 6, Y: 1, TypeId: NeutralType, AbilityCooldown: state.NoAbilityCooldown}},
+		{"3 0 7 8", PacLine{Id: 3, X: 7, Y: 8, TypeId: NeutralType, AbilityCooldown: state.NoAbilityCooldown}},
+		{"4 1 1 1 PAPER", PacLine{Id: 4, Mine: 1, X: 1, Y: 1, TypeId: "PAPER", AbilityCooldown: state.NoAbilityCooldown}},
+	}
+	for _, test := range tests {
+		got, err := ParsePac(test.line)
+		if err != nil {
+			t.Errorf("%q: %v", test.line, err)
+		} else if got != test.want {
+			t.Errorf("%q: got %+v, want %+v", test.line, got, test.want)
+		}
+	}
+	for _, line := range []string{"", "0 1 5", "0 1 x 3 ROCK 0 0", "0 1 5 3 ROCK fast 0"} {
+		if _, err := ParsePac(line); err == nil {
+			t.Errorf("%q parsed, want an error", line)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package gameio
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"spring2020/internal/logger"
+	"spring2020/internal/protocol"
+	"spring2020/internal/state"
+	"spring2020/internal/telemetry"
+)
+
+// Publisher owning the commands printed for a turn. It starts with every pac
+// holding its position, planners replace commands as they find better ones,
+// and Publish prints whatever is there once, by the deadline.
+type Publisher struct {
+	mu        sync.Mutex
+	width     int
+	height    int
+	holds     map[int]Command
+	commands  *CommandSet
+	published bool
+}
+
+// Create publisher holding every pac at its position
+func NewPublisher(pacs []*state.Pac, width, height int) *Publisher {
+	p := &Publisher{width: width, height: height, holds: make(map[int]Command), commands: NewCommandSet()}
+	for _, pac := range pacs {
+		p.holds[pac.Id] = Wait{pac.Id, pac.X, pac.Y}
+		p.commands.Set(p.holds[pac.Id])
+	}
+	return p
+}
+
+// Run the command line through the referee's parser and repair protocol
+// violations: commands that do not parse, name a pac that is not mine or
+// repeat a pac are dropped, and pacs left without a command hold position
+func (p *Publisher) repair(line string) string {
+	commands, errs := protocol.ParseCommands(line)
+	valid, invalid := protocol.ValidateCommands(commands, p.commands.order, p.width, p.height)
+	errs = append(errs, invalid...)
+	if len(errs) == 0 {
+		return line
+	}
+	for _, err := range errs {
+		logger.Log("Invalid command dropped:", err)
+	}
+	repaired := make(map[int]string)
+	for _, command := range valid {
+		repaired[command.PacId] = command.Text
+	}
+	var texts []string
+	for _, id := range p.commands.order {
+		if text, ok := repaired[id]; ok {
+			texts = append(texts, text)
+		} else {
+			texts = append(texts, p.holds[id].String())
+		}
+	}
+	return strings.Join(texts, "|")
+}
+
+// Replace the pending command of a pac, ignored once published
+func (p *Publisher) Update(command Command) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.published {
+		p.commands.Set(command)
+	}
+}
+
+// Attach a message to the pending command of a pac, shown next to it in the
+// replay viewer, ignored once published
+func (p *Publisher) Label(pacId int, label string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.published {
+		p.commands.Label(pacId, label)
+	}
+}
+
+// Check if the commands were already published so planning can stop
+func (p *Publisher) Expired() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.published
+}
+
+// Print the pending commands and seal the publisher, returning the line
+// printed
+func (p *Publisher) Publish() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.published = true
+	line := p.repair(p.commands.String())
+	fmt.Println(line)
+	commands, _ := protocol.ParseCommands(line)
+	for _, command := range commands {
+		if command.Verb == protocol.VerbSpeed || command.Verb == protocol.VerbSwitch {
+			telemetry.AbilityUsed(command.Verb)
+		}
+	}
+	return line
+}
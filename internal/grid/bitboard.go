@@ -0,0 +1,76 @@
+package grid
+
+import "math/bits"
+
+// Set of cells of a grid packed into bits, each row in its own words so bit
+// x of the first word of row y is cell x, y. Rows of the contest maps fit a
+// single word.
+type Bitboard struct {
+	stride int // words per row
+	words  []uint64
+}
+
+// Create an empty bitboard for a width by height grid
+func NewBitboard(width, height int) Bitboard {
+	stride := (width + 63) / 64
+	return Bitboard{stride: stride, words: make([]uint64, stride*height)}
+}
+
+// Bitboard of the walls of grid
+func WallBoard(grid [][]*Cell) Bitboard {
+	b := NewBitboard(len(grid[0]), len(grid))
+	for _, row := range grid {
+		for _, cell := range row {
+			if cell.IsWall {
+				b.Set(cell.X, cell.Y)
+			}
+		}
+	}
+	return b
+}
+
+func (b Bitboard) word(x, y int) (int, uint64) {
+	return y*b.stride + x/64, 1 << uint(x%64)
+}
+
+// Add cell x, y to the set
+func (b Bitboard) Set(x, y int) {
+	i, bit := b.word(x, y)
+	b.words[i] |= bit
+}
+
+// Remove cell x, y from the set
+func (b Bitboard) Unset(x, y int) {
+	i, bit := b.word(x, y)
+	b.words[i] &^= bit
+}
+
+// Check if cell x, y is in the set, never on an empty bitboard
+func (b Bitboard) Has(x, y int) bool {
+	if b.words == nil {
+		return false
+	}
+	i, bit := b.word(x, y)
+	return b.words[i]&bit != 0
+}
+
+// Number of cells in the set
+func (b Bitboard) Count() int {
+	n := 0
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Empty the set
+func (b Bitboard) Reset() {
+	for i := range b.words {
+		b.words[i] = 0
+	}
+}
+
+// Copy of the set that changes independently of b
+func (b Bitboard) Clone() Bitboard {
+	return Bitboard{stride: b.stride, words: append([]uint64(nil), b.words...)}
+}
@@ -0,0 +1,61 @@
+package grid_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/grid"
+)
+
+func TestBitboard(t *testing.T) {
+	b := grid.NewBitboard(70, 3)
+	if b.Has(0, 0) || b.Count() != 0 {
+		t.Fatal("new bitboard not empty")
+	}
+	cells := [][2]int{{0, 0}, {63, 1}, {64, 1}, {69, 2}}
+	for _, c := range cells {
+		b.Set(c[0], c[1])
+	}
+	for _, c := range cells {
+		if !b.Has(c[0], c[1]) {
+			t.Errorf("(%d, %d) not set", c[0], c[1])
+		}
+	}
+	if b.Has(64, 0) || b.Has(0, 1) {
+		t.Error("unset cells reported set")
+	}
+	if got := b.Count(); got != len(cells) {
+		t.Errorf("count %d, want %d", got, len(cells))
+	}
+	b.Unset(63, 1)
+	if b.Has(63, 1) || !b.Has(64, 1) {
+		t.Error("unset cleared the wrong bit")
+	}
+	b.Reset()
+	if b.Count() != 0 {
+		t.Error("reset bitboard not empty")
+	}
+	var empty grid.Bitboard
+	if empty.Has(3, 3) {
+		t.Error("zero bitboard has a cell")
+	}
+}
+
+func TestWallBoard(t *testing.T) {
+	cells := fixture.Grid(mirrored...)
+	walls := grid.WallBoard(cells)
+	n := 0
+	for _, row := range cells {
+		for _, cell := range row {
+			if walls.Has(cell.X, cell.Y) != cell.IsWall {
+				t.Errorf("(%d, %d) wall %v on the board, %v in the grid", cell.X, cell.Y, walls.Has(cell.X, cell.Y), cell.IsWall)
+			}
+			if cell.IsWall {
+				n++
+			}
+		}
+	}
+	if walls.Count() != n {
+		t.Errorf("count %d, want %d walls", walls.Count(), n)
+	}
+}
@@ -0,0 +1,321 @@
+package grid
+
+// Cell where corridors meet or end: a junction of three or more corridors or
+// a dead end. A loop of corridor cells with no junction gets one of its
+// cells as junction.
+type Junction struct {
+	Id        int
+	Cell      *Cell
+	Corridors []*Corridor
+}
+
+// Run of cells with exactly two open neighbors between two junctions
+type Corridor struct {
+	Id       int
+	From, To *Junction
+	// Cells strictly between the junctions, in order from From to To
+	Cells []*Cell
+}
+
+// Steps from one junction of the corridor to the other
+func (c *Corridor) Length() int {
+	return len(c.Cells) + 1
+}
+
+// Place of a corridor cell: its corridor and index in Cells
+type corridorSpot struct {
+	corridor *Corridor
+	index    int
+}
+
+// Maze compressed into its junctions and the corridors between them, a
+// graph of far fewer nodes than cells to route long paths on
+type CorridorGraph struct {
+	Junctions []*Junction
+	Corridors []*Corridor
+	junctions map[*Cell]*Junction
+	spots     map[*Cell]corridorSpot
+}
+
+// Compress grid into its corridor graph, computed once before the first turn
+// as the maze never changes. On a mirrored map only the corridors leaving
+// the junctions of the left half are walked, those of the right half are
+// their mirror images.
+func NewCorridorGraph(grid [][]*Cell, mirrored bool) *CorridorGraph {
+	g := &CorridorGraph{
+		junctions: make(map[*Cell]*Junction),
+		spots:     make(map[*Cell]corridorSpot),
+	}
+	for _, row := range grid {
+		for _, cell := range row {
+			if !cell.IsWall && cell.OpenNeighbors() != 2 {
+				g.addJunction(cell)
+			}
+		}
+	}
+	walked := make(map[[2]*Cell]bool)
+	if mirrored {
+		width := len(grid[0])
+		for _, j := range g.Junctions {
+			if j.Cell.X > width/2 {
+				continue
+			}
+			walkedBefore := len(g.Corridors)
+			g.walkFrom(j, walked)
+			for _, c := range g.Corridors[walkedBefore:] {
+				g.mirrorCorridor(c, grid, walked)
+			}
+		}
+	}
+	for _, j := range g.Junctions {
+		g.walkFrom(j, walked)
+	}
+	for _, row := range grid {
+		for _, cell := range row {
+			if cell.IsWall || g.junctions[cell] != nil {
+				continue
+			}
+			if _, ok := g.spots[cell]; !ok {
+				g.walkFrom(g.addJunction(cell), walked)
+			}
+		}
+	}
+	return g
+}
+
+// Add the mirror image of corridor c unless c is its own
+func (g *CorridorGraph) mirrorCorridor(c *Corridor, grid [][]*Cell, walked map[[2]*Cell]bool) {
+	cells := make([]*Cell, len(c.Cells))
+	for i, cell := range c.Cells {
+		cells[i] = mirrorCell(cell, grid)
+	}
+	from, to := g.junctions[mirrorCell(c.From.Cell, grid)], g.junctions[mirrorCell(c.To.Cell, grid)]
+	first, last := to.Cell, from.Cell
+	if len(cells) > 0 {
+		first, last = cells[0], cells[len(cells)-1]
+	}
+	if walked[[2]*Cell{from.Cell, first}] {
+		return
+	}
+	m := &Corridor{Id: len(g.Corridors), From: from, To: to, Cells: cells}
+	for i, cell := range cells {
+		g.spots[cell] = corridorSpot{m, i}
+	}
+	walked[[2]*Cell{from.Cell, first}] = true
+	walked[[2]*Cell{to.Cell, last}] = true
+	g.Corridors = append(g.Corridors, m)
+	from.Corridors = append(from.Corridors, m)
+	if to != from {
+		to.Corridors = append(to.Corridors, m)
+	}
+}
+
+func (g *CorridorGraph) addJunction(cell *Cell) *Junction {
+	j := &Junction{Id: len(g.Junctions), Cell: cell}
+	g.Junctions = append(g.Junctions, j)
+	g.junctions[cell] = j
+	return j
+}
+
+// Walk every corridor leaving j not walked yet from its other end
+func (g *CorridorGraph) walkFrom(j *Junction, walked map[[2]*Cell]bool) {
+	for _, first := range j.Cell.Neighbors {
+		if first.IsWall || walked[[2]*Cell{j.Cell, first}] {
+			continue
+		}
+		c := &Corridor{Id: len(g.Corridors), From: j}
+		prev, current := j.Cell, first
+		for g.junctions[current] == nil {
+			g.spots[current] = corridorSpot{c, len(c.Cells)}
+			c.Cells = append(c.Cells, current)
+			for _, next := range current.Neighbors {
+				if !next.IsWall && next != prev {
+					prev, current = current, next
+					break
+				}
+			}
+		}
+		c.To = g.junctions[current]
+		walked[[2]*Cell{j.Cell, first}] = true
+		walked[[2]*Cell{current, prev}] = true
+		g.Corridors = append(g.Corridors, c)
+		j.Corridors = append(j.Corridors, c)
+		if c.To != j {
+			c.To.Corridors = append(c.To.Corridors, c)
+		}
+	}
+}
+
+// Junction at cell, nil on corridor cells and walls
+func (g *CorridorGraph) JunctionAt(cell *Cell) *Junction {
+	return g.junctions[cell]
+}
+
+// Corridor through cell and the index of cell in its Cells, nil on
+// junctions and walls
+func (g *CorridorGraph) CorridorAt(cell *Cell) (*Corridor, int) {
+	spot, ok := g.spots[cell]
+	if !ok {
+		return nil, 0
+	}
+	return spot.corridor, spot.index
+}
+
+// Junctions reached from cell without crossing another: cell itself on a
+// junction, both ends of its corridor otherwise, one when they are the same
+func (g *CorridorGraph) Ends(cell *Cell) []*Junction {
+	if j := g.junctions[cell]; j != nil {
+		return []*Junction{j}
+	}
+	c, _ := g.CorridorAt(cell)
+	if c == nil {
+		return nil
+	}
+	if c.From == c.To {
+		return []*Junction{c.From}
+	}
+	return []*Junction{c.From, c.To}
+}
+
+// Way between a cell and a junction along a corridor: the junction, its
+// steps and the cells walked after the first up to the last
+type corridorLeg struct {
+	junction *Junction
+	steps    int
+	cells    []*Cell
+}
+
+// Legs leaving cell to the junctions it reaches without crossing another
+func (g *CorridorGraph) exits(cell *Cell) []corridorLeg {
+	if j := g.junctions[cell]; j != nil {
+		return []corridorLeg{{junction: j}}
+	}
+	c, i := g.CorridorAt(cell)
+	back := append(reversed(c.Cells[:i]), c.From.Cell)
+	ahead := append(append([]*Cell{}, c.Cells[i+1:]...), c.To.Cell)
+	return []corridorLeg{{c.From, i + 1, back}, {c.To, len(c.Cells) - i, ahead}}
+}
+
+// Legs entering cell from the junctions that reach it without crossing
+// another
+func (g *CorridorGraph) entries(cell *Cell) []corridorLeg {
+	if j := g.junctions[cell]; j != nil {
+		return []corridorLeg{{junction: j}}
+	}
+	c, i := g.CorridorAt(cell)
+	fromStart := append([]*Cell{}, c.Cells[:i+1]...)
+	fromEnd := reversed(c.Cells[i:])
+	return []corridorLeg{{c.From, i + 1, fromStart}, {c.To, len(c.Cells) - i, fromEnd}}
+}
+
+// Corridor crossed to reach a junction on the shortest route, walked forward
+// from From to To or backward
+type corridorStep struct {
+	corridor *Corridor
+	forward  bool
+}
+
+// Cells walked crossing the corridor, ending on the junction reached
+func (s corridorStep) cells() []*Cell {
+	if s.forward {
+		return append(append([]*Cell{}, s.corridor.Cells...), s.corridor.To.Cell)
+	}
+	return append(reversed(s.corridor.Cells), s.corridor.From.Cell)
+}
+
+// Shortest path from start to goal both included, routed junction to
+// junction on the graph and expanded back to cells, nil when goal cannot be
+// reached
+func (g *CorridorGraph) Path(start, goal *Cell) []*Cell {
+	if start.IsWall || goal.IsWall {
+		return nil
+	}
+	if start == goal {
+		return []*Cell{start}
+	}
+	best, bestPath := -1, []*Cell(nil)
+	if c, i := g.CorridorAt(start); c != nil {
+		if goalCorridor, k := g.CorridorAt(goal); goalCorridor == c {
+			best = abs(k - i)
+			if k > i {
+				bestPath = append([]*Cell{}, c.Cells[i:k+1]...)
+			} else {
+				bestPath = reversed(c.Cells[k : i+1])
+			}
+		}
+	}
+
+	n := len(g.Junctions)
+	dist := make([]int, n)
+	for i := range dist {
+		dist[i] = -1
+	}
+	done := make([]bool, n)
+	exit := make([]int, n)
+	via := make([]corridorStep, n)
+	exits := g.exits(start)
+	for e, leg := range exits {
+		if id := leg.junction.Id; dist[id] < 0 || leg.steps < dist[id] {
+			dist[id], exit[id] = leg.steps, e
+		}
+	}
+	for {
+		current := -1
+		for id := range dist {
+			if !done[id] && dist[id] >= 0 && (current < 0 || dist[id] < dist[current]) {
+				current = id
+			}
+		}
+		if current < 0 {
+			break
+		}
+		done[current] = true
+		j := g.Junctions[current]
+		for _, c := range j.Corridors {
+			for _, step := range []corridorStep{{c, true}, {c, false}} {
+				from, to := c.From, c.To
+				if !step.forward {
+					from, to = to, from
+				}
+				if from != j || done[to.Id] {
+					continue
+				}
+				if d := dist[current] + c.Length(); dist[to.Id] < 0 || d < dist[to.Id] {
+					dist[to.Id], exit[to.Id], via[to.Id] = d, exit[current], step
+				}
+			}
+		}
+	}
+
+	for _, leg := range g.entries(goal) {
+		id := leg.junction.Id
+		if dist[id] < 0 || (best >= 0 && dist[id]+leg.steps >= best) {
+			continue
+		}
+		best = dist[id] + leg.steps
+		var steps []corridorStep
+		for at := id; via[at].corridor != nil; {
+			steps = append(steps, via[at])
+			if via[at].forward {
+				at = via[at].corridor.From.Id
+			} else {
+				at = via[at].corridor.To.Id
+			}
+		}
+		path := []*Cell{start}
+		path = append(path, exits[exit[id]].cells...)
+		for s := len(steps) - 1; s >= 0; s-- {
+			path = append(path, steps[s].cells()...)
+		}
+		bestPath = append(path, leg.cells...)
+	}
+	return bestPath
+}
+
+func reversed(cells []*Cell) []*Cell {
+	out := make([]*Cell, len(cells))
+	for i, cell := range cells {
+		out[len(cells)-1-i] = cell
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package grid_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/grid"
+)
+
+func TestCorridorGraphCompressesMaze(t *testing.T) {
+	cells := fixture.Grid(mirrored...)
+	g := grid.NewCorridorGraph(cells, false)
+	for _, row := range cells {
+		for _, cell := range row {
+			j := g.JunctionAt(cell)
+			c, i := g.CorridorAt(cell)
+			switch {
+			case cell.IsWall && (j != nil || c != nil):
+				t.Errorf("wall (%d, %d) in the graph", cell.X, cell.Y)
+			case !cell.IsWall && (j == nil) == (c == nil):
+				t.Errorf("(%d, %d) junction %v corridor %v, want exactly one", cell.X, cell.Y, j != nil, c != nil)
+			case c != nil && c.Cells[i] != cell:
+				t.Errorf("(%d, %d) not at index %d of its corridor", cell.X, cell.Y, i)
+			}
+		}
+	}
+	// the dead end at the top of (2, 2) is a junction
+	if g.JunctionAt(cells[1][2]) == nil {
+		t.Error("dead end (2, 1) is not a junction")
+	}
+	if ends := g.Ends(cells[1][2]); len(ends) != 1 || ends[0].Cell != cells[1][2] {
+		t.Error("a junction does not end at itself")
+	}
+	if c, _ := g.CorridorAt(cells[3][2]); c != nil {
+		ends := g.Ends(cells[3][2])
+		if len(ends) != 2 || ends[0] != c.From || ends[1] != c.To {
+			t.Error("a corridor cell does not end at both junctions of its corridor")
+		}
+	}
+	if len(g.Junctions) >= 30 {
+		t.Errorf("%d junctions, want the maze compressed", len(g.Junctions))
+	}
+}
+
+func TestCorridorGraphPathIsShortest(t *testing.T) {
+	mazes := map[string][]string{"mirrored": mirrored, "contest": fixture.ContestMaze(7)}
+	mazes["loop"] = []string{
+		"#####",
+		"     ",
+		"#####",
+	}
+	for name, rows := range mazes {
+		t.Run(name, func(t *testing.T) {
+			cells := fixture.Grid(rows...)
+			table := grid.NewDistanceTable(cells, false)
+			g := grid.NewCorridorGraph(cells, true)
+			var floor []*grid.Cell
+			for _, row := range cells {
+				for _, cell := range row {
+					if !cell.IsWall {
+						floor = append(floor, cell)
+					}
+				}
+			}
+			for _, a := range floor {
+				for _, b := range floor {
+					want, ok := table.Between(a, b)
+					path := g.Path(a, b)
+					if !ok {
+						if path != nil {
+							t.Fatalf("(%d, %d) to (%d, %d): path to unreachable cell", a.X, a.Y, b.X, b.Y)
+						}
+						continue
+					}
+					if len(path) != want+1 || path[0] != a || path[len(path)-1] != b {
+						t.Fatalf("(%d, %d) to (%d, %d): %d steps, want %d", a.X, a.Y, b.X, b.Y, len(path)-1, want)
+					}
+					for i := 1; i < len(path); i++ {
+						if d, _ := table.Between(path[i-1], path[i]); d != 1 {
+							t.Fatalf("(%d, %d) to (%d, %d): step %d is not to a neighbor", a.X, a.Y, b.X, b.Y, i)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCorridorGraphMirrorsHalf(t *testing.T) {
+	rows := fixture.ContestMaze(7)
+	fullCells, cells := fixture.Grid(rows...), fixture.Grid(rows...)
+	full, half := grid.NewCorridorGraph(fullCells, false), grid.NewCorridorGraph(cells, true)
+	if len(half.Junctions) != len(full.Junctions) || len(half.Corridors) != len(full.Corridors) {
+		t.Fatalf("%d junctions and %d corridors, want %d and %d", len(half.Junctions), len(half.Corridors), len(full.Junctions), len(full.Corridors))
+	}
+	for y, row := range cells {
+		for x, cell := range row {
+			c, i := half.CorridorAt(cell)
+			want, wantIndex := full.CorridorAt(fullCells[y][x])
+			if (half.JunctionAt(cell) == nil) != (full.JunctionAt(fullCells[y][x]) == nil) || (c == nil) != (want == nil) {
+				t.Fatalf("(%d, %d) placed apart from the full graph", x, y)
+			}
+			if c != nil && (c.Length() != want.Length() || (c.Cells[i] != cell) || (i != wantIndex && i != len(c.Cells)-1-wantIndex)) {
+				t.Errorf("(%d, %d) at %d of a corridor of %d steps, want %d of %d", x, y, i, c.Length(), wantIndex, want.Length())
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package grid
+
+// Dead-end branches of the maze: cells every way out of which runs through
+// one cell, the mouth, where the branch hangs on the loops of the maze. A
+// corridor with a single exit is the simplest branch; a branch may fork
+// further in. A pac inside is trapped once an enemy beating it holds the
+// mouth. A maze without loops has no mouth to hang branches on and counts
+// no dead ends.
+type DeadEnds struct {
+	width int
+	// steps from the mouth, 0 outside the branches
+	depth []int
+	// depth of the deepest cell beyond each cell of a branch
+	deepest []int
+	mouth   []*Cell
+}
+
+// Find the dead-end branches of grid, once before the first turn as the
+// maze never changes
+func NewDeadEnds(grid [][]*Cell) *DeadEnds {
+	width := len(grid[0])
+	n := width * len(grid)
+	d := &DeadEnds{width: width, depth: make([]int, n), deepest: make([]int, n), mouth: make([]*Cell, n)}
+	// peel leaves off until only the cells on loops are left
+	degree := make([]int, n)
+	pruned := make([]bool, n)
+	var leaves []*Cell
+	for _, row := range grid {
+		for _, cell := range row {
+			if cell.IsWall {
+				continue
+			}
+			if degree[d.index(cell)] = cell.OpenNeighbors(); degree[d.index(cell)] <= 1 {
+				leaves = append(leaves, cell)
+			}
+		}
+	}
+	for len(leaves) > 0 {
+		cell := leaves[0]
+		leaves = leaves[1:]
+		pruned[d.index(cell)] = true
+		for _, next := range cell.Neighbors {
+			if i := d.index(next); !next.IsWall && !pruned[i] {
+				if degree[i]--; degree[i] == 1 {
+					leaves = append(leaves, next)
+				}
+			}
+		}
+	}
+	// walk each branch in from its mouth, then pass the depths back out
+	var order []*Cell
+	parent := make([]*Cell, n)
+	for _, row := range grid {
+		for _, mouth := range row {
+			if mouth.IsWall || pruned[d.index(mouth)] {
+				continue
+			}
+			for _, first := range mouth.Neighbors {
+				i := d.index(first)
+				if first.IsWall || !pruned[i] || d.mouth[i] != nil {
+					continue
+				}
+				d.mouth[i], d.depth[i], parent[i] = mouth, 1, mouth
+				queue := []*Cell{first}
+				for len(queue) > 0 {
+					cell := queue[0]
+					queue = queue[1:]
+					order = append(order, cell)
+					for _, next := range cell.Neighbors {
+						if j := d.index(next); !next.IsWall && pruned[j] && d.mouth[j] == nil {
+							d.mouth[j], d.depth[j], parent[j] = mouth, d.depth[d.index(cell)]+1, cell
+							queue = append(queue, next)
+						}
+					}
+				}
+			}
+		}
+	}
+	for k := len(order) - 1; k >= 0; k-- {
+		i := d.index(order[k])
+		if d.deepest[i] < d.depth[i] {
+			d.deepest[i] = d.depth[i]
+		}
+		if up := parent[i]; d.mouth[d.index(up)] != nil && d.deepest[d.index(up)] < d.deepest[i] {
+			d.deepest[d.index(up)] = d.deepest[i]
+		}
+	}
+	return d
+}
+
+func (d *DeadEnds) index(cell *Cell) int {
+	return cell.Y*d.width + cell.X
+}
+
+// Steps from cell back to the mouth of its branch, 0 off the branches
+func (d *DeadEnds) Depth(cell *Cell) int {
+	if d == nil {
+		return 0
+	}
+	return d.depth[d.index(cell)]
+}
+
+// Mouth of the branch of cell, nil off the branches
+func (d *DeadEnds) Mouth(cell *Cell) *Cell {
+	if d == nil {
+		return nil
+	}
+	return d.mouth[d.index(cell)]
+}
+
+// Steps from cell deeper into its branch at most, 0 off the branches
+func (d *DeadEnds) Beyond(cell *Cell) int {
+	if d == nil || d.mouth[d.index(cell)] == nil {
+		return 0
+	}
+	return d.deepest[d.index(cell)] - d.depth[d.index(cell)]
+}
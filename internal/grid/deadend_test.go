@@ -0,0 +1,42 @@
+package grid_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/grid"
+)
+
+func TestDeadEndsFindBranches(t *testing.T) {
+	cells := fixture.Grid(
+		"#########",
+		"#   #   #",
+		"# # # # #",
+		"#       #",
+		"#### ####",
+		"##     ##",
+		"#########",
+	)
+	d := grid.NewDeadEnds(cells)
+	// the upper rows loop, the branch below forks after its mouth (4, 3)
+	for _, cell := range []*grid.Cell{cells[1][1], cells[3][4], cells[2][3]} {
+		if d.Depth(cell) != 0 || d.Mouth(cell) != nil {
+			t.Errorf("(%d, %d) on a loop counted in a branch", cell.X, cell.Y)
+		}
+	}
+	tip := cells[5][2]
+	if d.Mouth(tip) != cells[3][4] || d.Depth(tip) != 4 {
+		t.Errorf("(2, 5) mouth %v depth %d, want (4, 3) and 4", d.Mouth(tip), d.Depth(tip))
+	}
+	fork := cells[5][4]
+	if d.Depth(fork) != 2 || d.Beyond(fork) != 2 || d.Beyond(cells[4][4]) != 3 {
+		t.Errorf("fork depth %d beyond %d, want 2 and 2", d.Depth(fork), d.Beyond(fork))
+	}
+	if d.Beyond(tip) != 0 {
+		t.Errorf("%d steps beyond the tip", d.Beyond(tip))
+	}
+	var none *grid.DeadEnds
+	if none.Depth(tip) != 0 || none.Mouth(tip) != nil || none.Beyond(tip) != 0 {
+		t.Error("nil dead ends count a branch")
+	}
+}
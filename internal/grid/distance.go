@@ -0,0 +1,76 @@
+package grid
+
+// Shortest distances between all floor cells, computed once before the
+// first turn as the maze never changes
+type DistanceTable struct {
+	cells []*Cell
+	dist  [][]int16
+	// id of the mirror cell of every cell on a mirrored map, nil otherwise
+	mirror []int
+}
+
+// Compute the distance table with one BFS from every floor cell. On a
+// mirrored map only the cells of the left half are flooded and kept, the
+// distances from the right half are looked up from their mirror cells.
+func NewDistanceTable(grid [][]*Cell, mirrored bool) *DistanceTable {
+	t := &DistanceTable{}
+	for _, row := range grid {
+		for _, cell := range row {
+			cell.id = -1
+			if !cell.IsWall {
+				cell.id = len(t.cells)
+				t.cells = append(t.cells, cell)
+			}
+		}
+	}
+	width := len(grid[0])
+	if mirrored {
+		t.mirror = make([]int, len(t.cells))
+		for _, cell := range t.cells {
+			t.mirror[cell.id] = mirrorCell(cell, grid).id
+		}
+	}
+	t.dist = make([][]int16, len(t.cells))
+	for _, cell := range t.cells {
+		if !mirrored || cell.X <= width/2 {
+			t.dist[cell.id] = t.flood(cell)
+		}
+	}
+	return t
+}
+
+// Distances from start to every floor cell, -1 where unreachable
+func (t *DistanceTable) flood(start *Cell) []int16 {
+	row := make([]int16, len(t.cells))
+	for i := range row {
+		row[i] = -1
+	}
+	row[start.id] = 0
+	queue := []*Cell{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, neighbor := range current.Neighbors {
+			if neighbor.IsWall || row[neighbor.id] >= 0 {
+				continue
+			}
+			row[neighbor.id] = row[current.id] + 1
+			queue = append(queue, neighbor)
+		}
+	}
+	return row
+}
+
+// Steps between two floor cells of the grid, false when one cannot reach
+// the other
+func (t *DistanceTable) Between(a, b *Cell) (int, bool) {
+	if a.IsWall || b.IsWall {
+		return 0, false
+	}
+	row, to := t.dist[a.id], b.id
+	if row == nil {
+		row, to = t.dist[t.mirror[a.id]], t.mirror[b.id]
+	}
+	d := row[to]
+	return int(d), d >= 0
+}
@@ -0,0 +1,65 @@
+package grid_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/grid"
+)
+
+// Mirrored rows with a tunnel, a dead end and a pocket walled off
+var mirrored = []string{
+	"###########",
+	"   #   #   ",
+	"# # ### # #",
+	"#    #    #",
+	"####   ####",
+	"# ####### #",
+	"###########",
+}
+
+func TestDistanceTableMirrorsHalf(t *testing.T) {
+	fullCells := fixture.Grid(mirrored...)
+	full := grid.NewDistanceTable(fullCells, false)
+	cells := fixture.Grid(mirrored...)
+	half := grid.NewDistanceTable(cells, true)
+	var all []*grid.Cell
+	for _, row := range cells {
+		all = append(all, row...)
+	}
+	for _, a := range all {
+		for _, b := range all {
+			want, wantOk := full.Between(fullCells[a.Y][a.X], fullCells[b.Y][b.X])
+			got, ok := half.Between(a, b)
+			if ok != wantOk || (ok && got != want) {
+				t.Errorf("(%d, %d) to (%d, %d): got %d %v, want %d %v", a.X, a.Y, b.X, b.Y, got, ok, want, wantOk)
+			}
+		}
+	}
+}
+
+func TestDistanceTableBetween(t *testing.T) {
+	cells := fixture.Grid(mirrored...)
+	table := grid.NewDistanceTable(cells, true)
+	tests := []struct {
+		name    string
+		a, b    [2]int
+		steps   int
+		reaches bool
+	}{
+		{"same cell", [2]int{1, 1}, [2]int{1, 1}, 0, true},
+		{"through the tunnel", [2]int{1, 1}, [2]int{9, 1}, 3, true},
+		{"around the middle wall", [2]int{1, 3}, [2]int{6, 3}, 7, true},
+		{"tunnel shorter than the middle", [2]int{1, 3}, [2]int{9, 3}, 7, true},
+		{"walled off pocket", [2]int{1, 3}, [2]int{1, 5}, 0, false},
+		{"wall", [2]int{1, 1}, [2]int{0, 0}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, ok := table.Between(cells[tt.a[1]][tt.a[0]], cells[tt.b[1]][tt.b[0]])
+			if ok != tt.reaches || (ok && steps != tt.steps) {
+				t.Errorf("got %d %v, want %d %v", steps, ok, tt.steps, tt.reaches)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Package grid holds the maze: cells with their neighbors wrapping through
+// the tunnels, breadth first searches and the all-pairs distance table.
+package grid
+
+import "spring2020/internal/telemetry"
+
+// Cell type struct
+type Type string
+
+// Cell type constants
+const (
+	Empty Type = " "
+	Wall  Type = "#"
+)
+
+// Cell structs
+type Cell struct {
+	X, Y   int
+	IsWall bool
+	id     int // index in the distance table, -1 for walls
+	// Neighbors
+	Neighbors []*Cell
+}
+
+// Initialize neighbors for cell
+func (c *Cell) InitNeighbors(grid [][]*Cell) {
+	c.Neighbors = getNeighbors(c, grid)
+}
+
+// Cell mirroring cell around the middle column of grid
+func mirrorCell(cell *Cell, grid [][]*Cell) *Cell {
+	return grid[cell.Y][len(grid[cell.Y])-1-cell.X]
+}
+
+// Count neighbors that are not walls
+func (c *Cell) OpenNeighbors() int {
+	open := 0
+	for _, neighbor := range c.Neighbors {
+		if !neighbor.IsWall {
+			open++
+		}
+	}
+	return open
+}
+
+// Check if cell joins three or more corridors
+func (c *Cell) IsJunction() bool {
+	return c.OpenNeighbors() >= 3
+}
+
+// Breadth first search from start over passable cells, returning the path to
+// the first cell matching goal
+func BfsFind(start *Cell, passable func(*Cell) bool, goal func(*Cell) bool) []*Cell {
+	telemetry.CountFlood()
+	parents := map[*Cell]*Cell{start: nil}
+	queue := []*Cell{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current != start && goal(current) {
+			var path []*Cell
+			for current != nil {
+				path = append([]*Cell{current}, path...)
+				current = parents[current]
+			}
+			return path
+		}
+		for _, neighbor := range current.Neighbors {
+			if _, seen := parents[neighbor]; seen || neighbor.IsWall || !passable(neighbor) {
+				continue
+			}
+			parents[neighbor] = current
+			queue = append(queue, neighbor)
+		}
+	}
+	return nil
+}
+
+// Get the cells next to cell, wrapping horizontally through the tunnels
+func getNeighbors(cell *Cell, grid [][]*Cell) []*Cell {
+	neighbors := []*Cell{}
+	x, y := cell.X, cell.Y
+	width := len(grid[0])
+	if width > 1 {
+		neighbors = append(neighbors, grid[y][(x+width-1)%width])
+	}
+	if width > 2 {
+		neighbors = append(neighbors, grid[y][(x+1)%width])
+	}
+	if y > 0 {
+		neighbors = append(neighbors, grid[y-1][x])
+	}
+	if y < len(grid)-1 {
+		neighbors = append(neighbors, grid[y+1][x])
+	}
+	return neighbors
+}
+
+// Manhattan distance on a map wrapping horizontally at width
+func ManhattanDistance(a, b *Cell, width int) int {
+	dx := abs(a.X - b.X)
+	return MinInt(dx, width-dx) + abs(a.Y-b.Y)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func MinInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Get cell pointer at x, y
+func GetCell(x, y int, grid [][]*Cell) *Cell {
+	return grid[y][x]
+}
+
+// Distances from the nearest of sources to every reachable cell
+func BfsDistances(sources []*Cell) map[*Cell]int {
+	telemetry.CountFlood()
+	dist := make(map[*Cell]int)
+	var queue []*Cell
+	for _, cell := range sources {
+		if _, ok := dist[cell]; !ok {
+			dist[cell] = 0
+			queue = append(queue, cell)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, neighbor := range current.Neighbors {
+			if _, seen := dist[neighbor]; seen || neighbor.IsWall {
+				continue
+			}
+			dist[neighbor] = dist[current] + 1
+			queue = append(queue, neighbor)
+		}
+	}
+	return dist
+}
@@ -0,0 +1,192 @@
+// Package harness evaluates a bot build against another over many local
+// games, building older builds from git revisions and summarizing win rates.
+// The games are played on mapgen maps, not on maps of the contest referee.
+package harness
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"spring2020/internal/arena"
+	"spring2020/internal/mapgen"
+	"spring2020/internal/referee"
+)
+
+// Build the bot at git revision rev of the repository in dir into the binary
+// out, the working tree itself when rev is empty. The binary is static so it
+// runs under any external referee.
+func Build(dir, rev, out string) error {
+	out, err := filepath.Abs(out)
+	if err != nil {
+		return err
+	}
+	src := dir
+	if rev != "" {
+		tmp, err := os.MkdirTemp("", "harness-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		src = filepath.Join(tmp, "src")
+		if err := run(dir, "git", "worktree", "add", "--detach", src, rev); err != nil {
+			return err
+		}
+		defer run(dir, "git", "worktree", "remove", "--force", src)
+	}
+	build := exec.Command("go", "build", "-o", out, ".")
+	build.Dir = src
+	build.Env = append(os.Environ(), "CGO_ENABLED=0")
+	if output, err := build.CombinedOutput(); err != nil {
+		return fmt.Errorf("building %s: %w\n%s", rev, err, output)
+	}
+	return nil
+}
+
+// Run a command in dir, failing with its output
+func run(dir string, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w\n%s", name, err, output)
+	}
+	return nil
+}
+
+// Win, draw and loss counts of a bot over many games
+type Summary struct {
+	Games, Wins, Draws, Losses int
+	Score, OpponentScore       int
+	Timeouts                   int
+}
+
+// Count the result of one game
+func (s *Summary) Add(result arena.Result) {
+	s.Games++
+	switch result.Outcome {
+	case arena.Win:
+		s.Wins++
+	case arena.Draw:
+		s.Draws++
+	case arena.Loss:
+		s.Losses++
+	}
+	s.Score += result.MyScore
+	s.OpponentScore += result.OpponentScore
+	if result.Timeout {
+		s.Timeouts++
+	}
+}
+
+// Share of games won, draws counting half
+func (s Summary) WinRate() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return (float64(s.Wins) + float64(s.Draws)/2) / float64(s.Games)
+}
+
+// Half width of the 95% confidence interval of the win rate
+func (s Summary) Margin() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	p := s.WinRate()
+	return 1.96 * math.Sqrt(p*(1-p)/float64(s.Games))
+}
+
+func (s Summary) String() string {
+	n := math.Max(float64(s.Games), 1)
+	return fmt.Sprintf("%d games: %d wins %d draws %d losses, win rate %.1f%% ± %.1f%%, mean score %.1f-%.1f, %d timeouts",
+		s.Games, s.Wins, s.Draws, s.Losses, 100*s.WinRate(), 100*s.Margin(),
+		float64(s.Score)/n, float64(s.OpponentScore)/n, s.Timeouts)
+}
+
+// Settings of a series of games
+type Options struct {
+	Games int
+	// Seed the map seeds are drawn from
+	Seed int64
+	Map  mapgen.Options
+	// Games played at once, 1 when zero
+	Parallel int
+	// Directory the stderr of the bots is written to, discarded when empty
+	Logs string
+	// Response time limits, the contest ones when zero
+	FirstTurnTimeout time.Duration
+	TurnTimeout      time.Duration
+}
+
+// Play games between the bot and opponent commands, the bot playing player
+// 1 in odd games, calling report with each game as it finishes. Reports
+// come from one goroutine at a time.
+func Run(bot, opponent string, opts Options, report func(game int, record *referee.Record, result arena.Result)) (Summary, error) {
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	// consecutive seeds give correlated first draws, so draw the seeds randomly
+	seeds := mapgen.NewRandom(opts.Seed)
+	games := make(chan int)
+	matches := make([]referee.Match, opts.Games)
+	for i := range matches {
+		matches[i] = referee.Match{
+			Seed:             seeds.Int64(),
+			Map:              opts.Map,
+			FirstTurnTimeout: opts.FirstTurnTimeout,
+			TurnTimeout:      opts.TurnTimeout,
+		}
+		matches[i].Commands[i%2], matches[i].Commands[1-i%2] = bot, opponent
+	}
+	var mu sync.Mutex
+	var summary Summary
+	var firstErr error
+	var wg sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range games {
+				record, err := play(matches[i], i, opts.Logs)
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else {
+					result := record.Result(i%2, bot, opponent)
+					summary.Add(result)
+					if report != nil {
+						report(i, record, result)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range matches {
+		games <- i
+	}
+	close(games)
+	wg.Wait()
+	return summary, firstErr
+}
+
+// Play one match, writing the stderr of its bots to logs when set
+func play(match referee.Match, game int, logs string) (*referee.Record, error) {
+	if logs != "" {
+		for player := range match.Stderr {
+			file, err := os.Create(filepath.Join(logs, fmt.Sprintf("game-%d-player-%d.log", game, player)))
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+			match.Stderr[player] = file
+		}
+	}
+	return match.Play()
+}
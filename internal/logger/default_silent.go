@@ -0,0 +1,6 @@
+//go:build silent
+
+package logger
+
+// Level written unless set otherwise, nothing for ranked games
+const DefaultLevel = LevelSilent
@@ -0,0 +1,106 @@
+// Package logger writes the debug log to stderr, the only output of the bot
+// CodinGame shows besides its commands. Lines are prefixed with the turn and
+// written only at or above the level set, which local runs pick with
+// -log or the SPRING2020_LOG environment variable. Submissions bundled with
+// the silent build tag write nothing for ranked games.
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Detail of a log line, lines below the level set are dropped
+type Level int
+
+// Log levels from the most detailed
+const (
+	// Per cell and per pellet detail
+	LevelTrace Level = iota
+	// Per pac decisions
+	LevelDebug
+	// Per turn summaries, timeouts and crashes
+	LevelInfo
+	// Nothing at all
+	LevelSilent
+)
+
+// Environment variable with the log level of local runs
+const LogEnvVar = "SPRING2020_LOG"
+
+var levelNames = []string{"trace", "debug", "info", "silent"}
+
+func (l Level) String() string {
+	if l < LevelTrace || l > LevelSilent {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// Level named name, case insensitively
+func ParseLevel(name string) (Level, error) {
+	for l, n := range levelNames {
+		if strings.EqualFold(name, n) {
+			return Level(l), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q, want one of %s", name, strings.Join(levelNames, ", "))
+}
+
+var (
+	level = DefaultLevel
+	turn  int
+	out   io.Writer = os.Stderr
+)
+
+// Set the least detailed level written
+func SetLevel(l Level) {
+	level = l
+}
+
+// Set the turn prefixing the lines, none before the first turn
+func SetTurn(t int) {
+	turn = t
+}
+
+// Check if lines of level l are written, to skip building costly ones
+func Enabled(l Level) bool {
+	return l >= level && level != LevelSilent
+}
+
+func write(line string) {
+	if turn > 0 {
+		line = fmt.Sprintf("[%d] %s", turn, line)
+	}
+	_, _ = io.WriteString(out, line)
+}
+
+// debug logging method
+func Log(a ...any) {
+	if Enabled(LevelDebug) {
+		write(fmt.Sprintln(a...))
+	}
+}
+
+// Log per turn summaries
+func Info(a ...any) {
+	if Enabled(LevelInfo) {
+		write(fmt.Sprintln(a...))
+	}
+}
+
+// Log per cell detail
+func Trace(a ...any) {
+	if Enabled(LevelTrace) {
+		write(fmt.Sprintln(a...))
+	}
+}
+
+// Log a formatted line at level l, formatting only when it is written
+func Logf(l Level, format string, a ...any) {
+	if Enabled(l) {
+		write(fmt.Sprintf(format, a...) + "\n")
+	}
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	var b strings.Builder
+	out = &b
+	defer SetLevel(DefaultLevel)
+	defer SetTurn(0)
+	SetLevel(LevelDebug)
+	SetTurn(7)
+	Trace("cell", 1, 2)
+	Log("pac", 0)
+	Info("turn")
+	if got, want := b.String(), "[7] pac 0\n[7] turn\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	b.Reset()
+	SetLevel(LevelSilent)
+	Info("turn")
+	Logf(LevelInfo, "%d", 1)
+	if b.Len() > 0 {
+		t.Errorf("silenced logger wrote %q", b.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if l, err := ParseLevel("TRACE"); err != nil || l != LevelTrace {
+		t.Errorf("got %v %v, want trace", l, err)
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("unknown level accepted")
+	}
+}
@@ -0,0 +1,306 @@
+// Package mapgen generates Spring Challenge 2020 style mazes for offline
+// games.
+//
+// Parameters follow the contest referee: heights 10 to 17, odd widths
+// roughly twice the height, a maze mirrored around the middle column with
+// wrapping tunnels on the side borders, few dead ends, pacs spawned mirrored
+// for both players and four mirrored super pellets. The carving algorithm is
+// an approximation of the referee's, so a seed gives a map of the same shape
+// statistics but not the same map.
+package mapgen
+
+import (
+	"strings"
+
+	"spring2020/internal/protocol"
+)
+
+// Map size and population limits of the contest
+const (
+	MinHeight = 10
+	MaxHeight = 17
+	MinWidth  = 28
+	MaxWidth  = 35
+	MinPacs   = 2
+	MaxPacs   = 5
+	// Super pellets per player
+	SuperPellets = 2
+	// Chance to knock out an extra wall between two corridors, creating loops
+	LoopDensity = 0.12
+)
+
+// Point on the map
+type Point struct {
+	X, Y int
+}
+
+// Pac spawn of player 0, player 1 spawns mirrored with the same id and type
+type Spawn struct {
+	Id     int
+	Point  Point
+	TypeId string
+}
+
+// Generated map
+type Map struct {
+	Width  int
+	Height int
+	// Walls as '#' and floor as ' ', indexed [y][x]
+	Rows   [][]byte
+	Pacs   []Spawn
+	Supers []Point
+}
+
+// Options for Generate, zero values pick contest-like random values
+type Options struct {
+	Width         int
+	Height        int
+	PacsPerPlayer int
+}
+
+// Check if x, y is floor
+func (m *Map) IsFloor(x, y int) bool {
+	return x >= 0 && x < m.Width && y >= 0 && y < m.Height && m.Rows[y][x] == ' '
+}
+
+// Mirror of p around the middle column
+func (m *Map) Mirror(p Point) Point {
+	return Point{m.Width - 1 - p.X, p.Y}
+}
+
+// Rows as the strings the referee sends on the first turn
+func (m *Map) Lines() []string {
+	lines := make([]string, m.Height)
+	for y, row := range m.Rows {
+		lines[y] = string(row)
+	}
+	return lines
+}
+
+// Map drawn with pacs of player 0 as digits, player 1 as letters and super pellets as '*'
+func (m *Map) String() string {
+	rows := make([][]byte, m.Height)
+	for y, row := range m.Rows {
+		rows[y] = append([]byte{}, row...)
+	}
+	for _, p := range m.Supers {
+		rows[p.Y][p.X] = '*'
+	}
+	for _, pac := range m.Pacs {
+		mirror := m.Mirror(pac.Point)
+		rows[pac.Point.Y][pac.Point.X] = byte('0' + pac.Id)
+		rows[mirror.Y][mirror.X] = byte('a' + pac.Id)
+	}
+	lines := make([]string, m.Height)
+	for y, row := range rows {
+		lines[y] = string(row)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Generate a map from seed
+func Generate(seed int64, opts Options) *Map {
+	r := NewRandom(seed)
+	height := opts.Height
+	if height == 0 {
+		height = r.Between(MinHeight, MaxHeight)
+	}
+	width := opts.Width
+	if width == 0 {
+		width = 2*height + r.Between(-3, 3)
+		if width%2 == 0 {
+			width++
+		}
+		if width < MinWidth {
+			width = MinWidth + 1
+		}
+		if width > MaxWidth {
+			width = MaxWidth
+		}
+	}
+	pacs := opts.PacsPerPlayer
+	if pacs == 0 {
+		pacs = r.Between(MinPacs, MaxPacs)
+	}
+
+	// the maze lattice needs an odd height to end on a single wall row, even
+	// heights are carved a row shorter and stretched
+	carved := height - 1 + height%2
+	m := &Map{Width: width, Height: carved, Rows: make([][]byte, carved)}
+	for y := range m.Rows {
+		m.Rows[y] = []byte(strings.Repeat("#", width))
+	}
+	m.carve(r)
+	m.braid(r)
+	m.tunnels(r)
+	if carved < height {
+		m.stretch(r)
+	}
+	m.mirror()
+	m.spawn(r, pacs)
+	return m
+}
+
+// Middle column of the map
+func (m *Map) middle() int {
+	return m.Width / 2
+}
+
+// Set floor at x, y
+func (m *Map) open(x, y int) {
+	m.Rows[y][x] = ' '
+}
+
+var directions = []Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+// Check if x, y is a lattice cell of the left half that carve connects
+func (m *Map) lattice(x, y int) bool {
+	return x%2 == 1 && y%2 == 1 && x <= m.middle() && y < m.Height-1
+}
+
+// Carve a spanning maze over the odd lattice of the left half with a
+// randomized depth first search
+func (m *Map) carve(r *Random) {
+	start := Point{1, 1}
+	m.open(start.X, start.Y)
+	stack := []Point{start}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		var options []Point
+		for _, d := range directions {
+			next := Point{current.X + 2*d.X, current.Y + 2*d.Y}
+			if m.lattice(next.X, next.Y) && !m.IsFloor(next.X, next.Y) {
+				options = append(options, d)
+			}
+		}
+		if len(options) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		d := options[r.Intn(len(options))]
+		m.open(current.X+d.X, current.Y+d.Y)
+		m.open(current.X+2*d.X, current.Y+2*d.Y)
+		stack = append(stack, Point{current.X + 2*d.X, current.Y + 2*d.Y})
+	}
+	// connect the half to its mirror through the middle column
+	mid := m.middle()
+	if mid%2 == 0 {
+		var rows []int
+		for y := 1; y < m.Height-1; y += 2 {
+			if m.IsFloor(mid-1, y) {
+				rows = append(rows, y)
+			}
+		}
+		m.open(mid, rows[r.Intn(len(rows))])
+		for _, y := range rows {
+			if r.Float64() < 0.4 {
+				m.open(mid, y)
+			}
+		}
+	}
+}
+
+// Count floor neighbors of x, y
+func (m *Map) openNeighbors(x, y int) int {
+	count := 0
+	for _, d := range directions {
+		if m.IsFloor(x+d.X, y+d.Y) {
+			count++
+		}
+	}
+	return count
+}
+
+// Remove dead ends and knock out extra walls so the maze has loops like the
+// contest maps
+func (m *Map) braid(r *Random) {
+	mid := m.middle()
+	for y := 1; y < m.Height-1; y += 2 {
+		for x := 1; x <= mid; x += 2 {
+			if !m.IsFloor(x, y) {
+				continue
+			}
+			var walls []Point
+			for _, d := range directions {
+				wall := Point{x + d.X, y + d.Y}
+				beyond := Point{x + 2*d.X, y + 2*d.Y}
+				if wall.X > 0 && wall.X <= mid && wall.Y > 0 && wall.Y < m.Height-1 &&
+					!m.IsFloor(wall.X, wall.Y) && (m.IsFloor(beyond.X, beyond.Y) || beyond.X > mid) {
+					walls = append(walls, wall)
+				}
+			}
+			if len(walls) == 0 {
+				continue
+			}
+			if m.openNeighbors(x, y) == 1 {
+				wall := walls[r.Intn(len(walls))]
+				m.open(wall.X, wall.Y)
+			} else if r.Float64() < LoopDensity {
+				wall := walls[r.Intn(len(walls))]
+				m.open(wall.X, wall.Y)
+			}
+		}
+	}
+}
+
+// Open wrapping tunnels on the left border, mirrored later to the right one
+func (m *Map) tunnels(r *Random) {
+	var rows []int
+	for y := 1; y < m.Height-1; y += 2 {
+		if m.IsFloor(1, y) {
+			rows = append(rows, y)
+		}
+	}
+	count := r.Between(0, 2)
+	for i := 0; i < count && len(rows) > 0; i++ {
+		j := r.Intn(len(rows))
+		m.open(0, rows[j])
+		rows = append(rows[:j], rows[j+1:]...)
+	}
+}
+
+// Add a row by doubling one of the rows between two corridor rows, which
+// lengthens the passages crossing it by a cell without opening any square
+func (m *Map) stretch(r *Random) {
+	y := 2 * r.Between(1, (m.Height-3)/2)
+	row := append([]byte{}, m.Rows[y]...)
+	m.Rows = append(m.Rows[:y+1], append([][]byte{row}, m.Rows[y+1:]...)...)
+	m.Height++
+}
+
+// Copy the left half onto the right half
+func (m *Map) mirror() {
+	for _, row := range m.Rows {
+		for x := 0; x < m.middle(); x++ {
+			row[m.Width-1-x] = row[x]
+		}
+	}
+}
+
+// Place pacs and super pellets on distinct floor cells of the left half
+func (m *Map) spawn(r *Random, pacs int) {
+	var free []Point
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.middle(); x++ {
+			if m.IsFloor(x, y) {
+				free = append(free, Point{x, y})
+			}
+		}
+	}
+	take := func() Point {
+		i := r.Intn(len(free))
+		p := free[i]
+		free = append(free[:i], free[i+1:]...)
+		return p
+	}
+	// types are dealt to pac ids in the order of the protocol
+	types := protocol.PacTypes
+	first := r.Intn(len(types))
+	for id := 0; id < pacs; id++ {
+		m.Pacs = append(m.Pacs, Spawn{Id: id, Point: take(), TypeId: types[(first+id)%len(types)]})
+	}
+	for i := 0; i < SuperPellets; i++ {
+		p := take()
+		m.Supers = append(m.Supers, p, m.Mirror(p))
+	}
+}
@@ -0,0 +1,38 @@
+package mapgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"spring2020/internal/mapgen"
+)
+
+func TestSingleWallBorder(t *testing.T) {
+	for height := mapgen.MinHeight; height <= mapgen.MaxHeight; height++ {
+		for seed := int64(0); seed < 20; seed++ {
+			m := mapgen.Generate(seed, mapgen.Options{Height: height})
+			if m.Height != height || len(m.Rows) != height {
+				t.Fatalf("height %d seed %d: map of %d rows, height %d", height, seed, len(m.Rows), m.Height)
+			}
+			wall := strings.Repeat("#", m.Width)
+			for _, y := range []int{0, height - 1} {
+				if string(m.Rows[y]) != wall {
+					t.Errorf("height %d seed %d: border row %d is %q", height, seed, y, m.Rows[y])
+				}
+			}
+			for _, y := range []int{1, height - 2} {
+				if string(m.Rows[y]) == wall {
+					t.Errorf("height %d seed %d: row %d next to the border is all wall", height, seed, y)
+				}
+			}
+			// no square of floor, as in the contest mazes
+			for y := 0; y+1 < height; y++ {
+				for x := 0; x+1 < m.Width; x++ {
+					if m.IsFloor(x, y) && m.IsFloor(x+1, y) && m.IsFloor(x, y+1) && m.IsFloor(x+1, y+1) {
+						t.Errorf("height %d seed %d: open square at (%d, %d)", height, seed, x, y)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package mapgen
+
+// Random is a port of java.util.Random, the generator the CodinGame referee
+// seeds from the game seed, so a seed yields the same random sequence here.
+type Random struct {
+	seed int64
+}
+
+const (
+	multiplier = 0x5DEECE66D
+	addend     = 0xB
+	mask       = (1 << 48) - 1
+)
+
+// Create random source with seed like new java.util.Random(seed)
+func NewRandom(seed int64) *Random {
+	return &Random{seed: (seed ^ multiplier) & mask}
+}
+
+func (r *Random) next(bits uint) int32 {
+	r.seed = (r.seed*multiplier + addend) & mask
+	return int32(r.seed >> (48 - bits))
+}
+
+// Random int in [0, n) like Random.nextInt(n)
+func (r *Random) Intn(n int) int {
+	if n <= 0 {
+		panic("mapgen: Intn bound must be positive")
+	}
+	bound := int32(n)
+	if bound&-bound == bound {
+		return int((int64(bound) * int64(r.next(31))) >> 31)
+	}
+	for {
+		bits := r.next(31)
+		val := bits % bound
+		if bits-val+(bound-1) >= 0 {
+			return int(val)
+		}
+	}
+}
+
+// Random int64 like Random.nextLong()
+func (r *Random) Int64() int64 {
+	return int64(r.next(32))<<32 + int64(r.next(32))
+}
+
+// Random float in [0, 1) like Random.nextDouble()
+func (r *Random) Float64() float64 {
+	return float64(int64(r.next(26))<<27+int64(r.next(27))) * (1.0 / (1 << 53))
+}
+
+// Random int in [min, max]
+func (r *Random) Between(min, max int) int {
+	return min + r.Intn(max-min+1)
+}
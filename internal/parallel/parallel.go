@@ -0,0 +1,41 @@
+// Package parallel runs independent per-pac work on a pool of goroutines,
+// one per processor the runtime may use. Work items only read the shared
+// game state and write their own result slot, so results merge in index
+// order and a turn plans the same whatever the scheduling.
+package parallel
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Run work for every index below n, at most GOMAXPROCS at a time, returning
+// once all are done. With one processor the work runs inline in order.
+func ForEach(n int, work func(i int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			work(i)
+		}
+		return
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				work(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
@@ -0,0 +1,26 @@
+package parallel_test
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"spring2020/internal/parallel"
+)
+
+func TestForEachRunsEveryIndexOnce(t *testing.T) {
+	for _, procs := range []int{1, 4} {
+		old := runtime.GOMAXPROCS(procs)
+		counts := make([]int32, 100)
+		parallel.ForEach(len(counts), func(i int) {
+			atomic.AddInt32(&counts[i], 1)
+		})
+		runtime.GOMAXPROCS(old)
+		for i, c := range counts {
+			if c != 1 {
+				t.Errorf("%d procs: index %d ran %d times", procs, i, c)
+			}
+		}
+	}
+	parallel.ForEach(0, func(int) { t.Error("work run for no items") })
+}
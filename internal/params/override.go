@@ -0,0 +1,82 @@
+package params
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Environment variable with parameter overrides for local runs, in the
+// format of Set. CodinGame sets none, so the compiled in profiles apply.
+const EnvVar = "SPRING2020_PARAMS"
+
+// Names of the tunable parameters, in declaration order
+func Names() []string {
+	t := reflect.TypeOf(Params{})
+	names := make([]string, t.NumField())
+	for i := range names {
+		names[i] = t.Field(i).Name
+	}
+	return names
+}
+
+// Override parameters from spec, a comma separated list of Name=value pairs
+// such as "ThreatRadius=4,RiskWeight=2.5". Fails on the first unknown name
+// or value that does not parse, leaving the pairs before it applied.
+func (p *Params) Set(spec string) error {
+	v := reflect.ValueOf(p).Elem()
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("parameter %q: want Name=value", pair)
+		}
+		field := v.FieldByName(strings.TrimSpace(name))
+		if !field.IsValid() {
+			return fmt.Errorf("unknown parameter %q", name)
+		}
+		value = strings.TrimSpace(value)
+		switch field.Kind() {
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("parameter %s: %w", name, err)
+			}
+			field.SetInt(int64(n))
+		case reflect.Float64:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("parameter %s: %w", name, err)
+			}
+			field.SetFloat(f)
+		}
+	}
+	return nil
+}
+
+// Overrides of p in the format of Set, listing every parameter
+func (p Params) Spec() string {
+	v := reflect.ValueOf(p)
+	pairs := make([]string, v.NumField())
+	for i, name := range Names() {
+		pairs[i] = fmt.Sprintf("%s=%v", name, v.Field(i))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Value of the parameter name, whether it takes whole numbers only, and
+// false when there is no such parameter
+func (p Params) Value(name string) (value float64, integer, ok bool) {
+	field := reflect.ValueOf(p).FieldByName(name)
+	switch {
+	case !field.IsValid():
+		return 0, false, false
+	case field.Kind() == reflect.Int:
+		return float64(field.Int()), true, true
+	}
+	return field.Float(), false, true
+}
@@ -0,0 +1,242 @@
+// Package params holds the tunable weights of the bot with separately tuned
+// profiles per map size and pac count, shared by the bot and the offline
+// tools that bucket games by size.
+package params
+
+// Map size class
+type SizeClass string
+
+// Map size classes by height
+const (
+	Small  SizeClass = "small"
+	Medium SizeClass = "medium"
+	Large  SizeClass = "large"
+)
+
+// Size class of a map
+func Size(width, height int) SizeClass {
+	switch {
+	case height <= 12:
+		return Small
+	case height <= 14:
+		return Medium
+	default:
+		return Large
+	}
+}
+
+// Tunable weights of the bot
+type Params struct {
+	// Extra steps a pac may walk to deny an enemy pellet instead of taking its own closest one
+	DenialMargin int
+	// Lead over the other pacs at which a pellet is left to its owner
+	OwnershipMargin int
+	// Steps of territory depth beyond the Voronoi frontier that are still contested
+	TerritoryFrontier int
+	// Most steps a pellet's territory depth adds or saves in target scoring
+	TerritoryCap int
+	// Projected lead, as a share of all points in the game, above which we turtle
+	TurtleLead float64
+	// Projected deficit, as a share of all points in the game, above which we hunt
+	HuntDeficit float64
+	// Cells within which an opponent pac counts as a threat
+	ThreatRadius int
+	// Steps a higher value pellet must be closer than the current target to switch to it
+	ReplanHysteresis int
+	// Turns a pac keeps a target before replanning anyway
+	ReplanInterval int
+	// Turns in a row a pac may be blocked on detours before it picks another target
+	StuckLimit int
+	// Turns ahead checked for my pacs meeting head-on in a corridor
+	PassingLookahead int
+	// Extra steps a pac accepts to loop around a corridor held by another pac
+	LoopMargin int
+	// Share of the confidence in an opponent pac's whereabouts kept per turn out of sight
+	TrackingDecay float64
+	// Steps added to a pellet's distance where meeting an opponent pac is certain
+	RiskWeight float64
+	// Steps a pellet's target cost drops per point it is worth above a regular pellet
+	ValueWeight int
+	// Turns ahead the beam search walks, 0 disables it
+	BeamDepth int
+	// Walks the beam search keeps per turn
+	BeamWidth int
+	// Share of a pellet's points kept per turn it is eaten later
+	BeamDiscount float64
+	// Points a walk loses per unit of risk on the cells it enters
+	BeamRiskCost float64
+	// Points a walk loses per step it ends farther from the plan target
+	BeamProgressCost float64
+	// Steps within which an opponent pac is fought with the duel search
+	DuelRadius int
+	// Turns ahead the duel search looks
+	DuelDepth int
+	// Steps within which an opponent pac beating a pac that cannot switch
+	// makes it retreat when its plan walks into the enemy's reach
+	EvadeRadius int
+	// Turns a retreat must keep a pac out of the enemy's reach, 0 disables
+	// retreat planning
+	EvadeDepth int
+	// Steps over which a pellet adds to the influence of the cells around it
+	InfluenceRadius int
+	// Share of a pellet's influence kept per step away from it
+	InfluenceDecay float64
+	// Steps a pellet's target cost drops per point of influence on its cell
+	InfluenceWeight float64
+	// Steps added to a path or target for a cell an opponent pac beating the
+	// pac may reach in two turns
+	DangerWeight float64
+	// Steps added to a target per step of depth into a dead-end branch an
+	// opponent pac able to eat the pac may shut it in
+	TrapWeight float64
+	// Turns an opponent pac must beat a pac by to a super pellet before the
+	// pac concedes the race
+	RaceMargin int
+	// Tracking confidence below which an opponent pac out of sight is left
+	// out of races to a cell
+	RaceConfidence float64
+	// Points a beam search walk must collect above the planned path for a pac to leave it
+	BeamMinGain float64
+	// Value of a duel position per step closer to an opponent pac that cannot
+	// switch away from the type eating it, well below a kill
+	DuelPressure float64
+	// Factor on the risk adjustment of target scoring with a safe lead
+	SafeRiskFactor int
+	// Fewest steps between the cells two pacs explore
+	ExploreSpacing int
+	// Steps within which a pac hunts an opponent pac it beats
+	HuntRadius int
+	// Steps within which a pac hunts an opponent pac it beats and corners
+	TrapRadius int
+	// Most cells an opponent pac may reach before my pac and still count as
+	// cornered by it
+	TrapCells int
+	// Steps from a rich cell of my territory within which an opponent pac
+	// makes a pac guard it
+	GuardRadius int
+	// Share of a step taken off routes for each cell with a pellet they
+	// enter, so long routes sweep up pellets on the way
+	PelletDiscount float64
+	// Most pellets a tour of nearby pellets visits, fewer than three
+	// disables tours
+	TourStops int
+	// Steps from a pac within which a tour picks its pellets
+	TourRadius int
+	// Most steps a tour walks
+	TourSteps int
+	// Random rollouts each command set is played out in, 0 disables them
+	Rollouts int
+	// Turns a rollout plays
+	RolloutDepth int
+	// Points a rollout counts for a pac eaten, lost for one of mine
+	RolloutDeathCost float64
+	// Mean rollout points a command set must gain over the planned commands
+	// to replace them
+	RolloutMinGain float64
+	// Chance an opponent pac in contact with mine switches when it may,
+	// before any of its switches are seen
+	SwitchPrior float64
+	// Chance a switching opponent pac takes the type beating mine, before
+	// any of its switches are seen
+	CounterPrior float64
+	// Turns of contact the switch priors count as against the history seen
+	SwitchPriorWeight float64
+	// Slots of the transposition table of the duel search as a power of
+	// two, 0 disables it
+	TableBits int
+	// Decision logic playing the turns: 0 walks the routes to the targets
+	// shared out greedily, 1 follows the walks of the beam planner
+	Strategy int
+	// Most cells an opponent pac out of sight may have covered for the
+	// points it scored there to mark pellets eaten rather than doubtful
+	HiddenHarvestReach int
+}
+
+// Weights for medium maps with three or four pacs per player
+var Default = Params{
+	DenialMargin:       3,
+	OwnershipMargin:    2,
+	TerritoryFrontier:  2,
+	TerritoryCap:       6,
+	TurtleLead:         0.1,
+	HuntDeficit:        0.1,
+	ThreatRadius:       3,
+	ReplanHysteresis:   4,
+	ReplanInterval:     10,
+	StuckLimit:         2,
+	PassingLookahead:   8,
+	LoopMargin:         4,
+	TrackingDecay:      0.8,
+	RiskWeight:         4,
+	ValueWeight:        4,
+	BeamDepth:          4,
+	BeamWidth:          8,
+	BeamDiscount:       0.9,
+	BeamRiskCost:       5,
+	BeamProgressCost:   0.3,
+	DuelRadius:         2,
+	DuelDepth:          2,
+	EvadeRadius:        3,
+	EvadeDepth:         4,
+	InfluenceRadius:    4,
+	InfluenceDecay:     0.6,
+	InfluenceWeight:    0.5,
+	DangerWeight:       6,
+	TrapWeight:         1,
+	RaceMargin:         1,
+	RaceConfidence:     0.5,
+	BeamMinGain:        1,
+	DuelPressure:       0.1,
+	SafeRiskFactor:     3,
+	ExploreSpacing:     5,
+	HuntRadius:         6,
+	TrapRadius:         10,
+	TrapCells:          8,
+	GuardRadius:        6,
+	PelletDiscount:     0.3,
+	TourStops:          6,
+	TourRadius:         6,
+	TourSteps:          12,
+	Rollouts:           24,
+	RolloutDepth:       6,
+	RolloutDeathCost:   20,
+	RolloutMinGain:     3,
+	SwitchPrior:        0.85,
+	CounterPrior:       0.8,
+	SwitchPriorWeight:  4,
+	TableBits:          14,
+	Strategy:           0,
+	HiddenHarvestReach: 4,
+}
+
+// Profile tuned for a map of the given size with pacs per player. Small maps
+// meet the opponent sooner, so threats are judged closer and plans are kept
+// shorter; large maps the other way round. Few pacs spread by themselves,
+// many pacs need a stronger push apart.
+func Profile(width, height, pacs int) Params {
+	p := Default
+	switch Size(width, height) {
+	case Small:
+		p.ThreatRadius = 2
+		p.TerritoryCap = 4
+		p.ReplanInterval = 8
+		p.PassingLookahead = 6
+		p.LoopMargin = 3
+		p.DenialMargin = 2
+	case Large:
+		p.ThreatRadius = 4
+		p.TerritoryCap = 8
+		p.ReplanInterval = 12
+		p.PassingLookahead = 10
+		p.LoopMargin = 6
+	}
+	switch {
+	case pacs <= 2:
+		p.OwnershipMargin = 3
+		p.DenialMargin++
+	case pacs >= 5:
+		p.OwnershipMargin = 1
+		p.PassingLookahead += 2
+	}
+	return p
+}
@@ -0,0 +1,32 @@
+package params
+
+import "testing"
+
+func TestSet(t *testing.T) {
+	p := Default
+	if err := p.Set("ThreatRadius=5, RiskWeight=2.5,"); err != nil {
+		t.Fatal(err)
+	}
+	if p.ThreatRadius != 5 || p.RiskWeight != 2.5 {
+		t.Errorf("got ThreatRadius %d RiskWeight %v, want 5 and 2.5", p.ThreatRadius, p.RiskWeight)
+	}
+	if p.ValueWeight != Default.ValueWeight {
+		t.Error("parameter left out of the spec changed")
+	}
+	for _, spec := range []string{"Threat=1", "ThreatRadius=1.5", "ThreatRadius"} {
+		if err := p.Set(spec); err == nil {
+			t.Errorf("%q accepted", spec)
+		}
+	}
+}
+
+func TestSpecRoundTrip(t *testing.T) {
+	want := Profile(35, 17, 5)
+	var got Params
+	if err := got.Set(want.Spec()); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
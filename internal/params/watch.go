@@ -0,0 +1,46 @@
+//go:build !submit
+
+package params
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// Weights file of a local run, watched so edits apply to a game in
+// progress. It holds overrides in the format of Set, pairs separated by
+// commas or newlines, and lines starting with # are comments.
+type Watch struct {
+	name     string
+	modified time.Time
+}
+
+// Watch the weights file called name
+func NewWatch(name string) *Watch {
+	return &Watch{name: name}
+}
+
+// Overrides in the file when it changed since the last call, the first
+// call included, and false when it did not
+func (w *Watch) Changed() (string, bool, error) {
+	info, err := os.Stat(w.name)
+	if err != nil {
+		return "", false, err
+	}
+	if info.ModTime().Equal(w.modified) {
+		return "", false, nil
+	}
+	data, err := os.ReadFile(w.name)
+	if err != nil {
+		return "", false, err
+	}
+	w.modified = info.ModTime()
+	var pairs []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			pairs = append(pairs, line)
+		}
+	}
+	return strings.Join(pairs, ","), true, nil
+}
@@ -0,0 +1,17 @@
+//go:build submit
+
+package params
+
+import "errors"
+
+// Stand-in for the weights file watch of watch.go: a submission has no
+// files to edit between turns
+type Watch struct{}
+
+func NewWatch(name string) *Watch {
+	return &Watch{}
+}
+
+func (w *Watch) Changed() (string, bool, error) {
+	return "", false, errors.New("weights file is not compiled into submissions")
+}
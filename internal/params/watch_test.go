@@ -0,0 +1,35 @@
+//go:build !submit
+
+package params
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "weights")
+	if err := os.WriteFile(name, []byte("# tried last\nThreatRadius=5\nRiskWeight=2.5, BeamDepth=4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := NewWatch(name)
+	spec, changed, err := w.Changed()
+	if err != nil || !changed || spec != "ThreatRadius=5,RiskWeight=2.5, BeamDepth=4" {
+		t.Fatalf("got %q, %v, %v on the first check", spec, changed, err)
+	}
+	if _, changed, _ := w.Changed(); changed {
+		t.Error("unchanged file reported changed")
+	}
+	if err := os.WriteFile(name, []byte("ThreatRadius=3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(name, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if spec, changed, err := w.Changed(); err != nil || !changed || spec != "ThreatRadius=3" {
+		t.Errorf("got %q, %v, %v after an edit", spec, changed, err)
+	}
+}
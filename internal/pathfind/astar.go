@@ -0,0 +1,209 @@
+// Package pathfind finds shortest paths between cells with A* and floods
+// Dijkstra flow fields from a cell to every other.
+package pathfind
+
+import (
+	"container/heap"
+	"sync"
+
+	"spring2020/internal/grid"
+	"spring2020/internal/telemetry"
+)
+
+// A* open set ordered by f score
+type PriorityQueue []*searchNode
+
+// PriorityQueue methods
+
+func (pq PriorityQueue) Len() int { return len(pq) }
+
+func (pq PriorityQueue) Less(i, j int) bool {
+	return pq[i].f < pq[j].f
+}
+
+func (pq PriorityQueue) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].index = i
+	pq[j].index = j
+}
+
+func (pq *PriorityQueue) Push(x interface{}) {
+	n := len(*pq)
+	item := x.(*searchNode)
+	item.index = n
+	*pq = append(*pq, item)
+}
+
+func (pq *PriorityQueue) Pop() interface{} {
+	old := *pq
+	n := len(old)
+	item := old[n-1]
+	item.index = -1
+	*pq = old[0 : n-1]
+	return item
+}
+
+func (pq *PriorityQueue) update(item *searchNode, g, h int) {
+	item.g = g
+	item.f = g + h
+	heap.Fix(pq, item.index)
+}
+
+// A* scratch state of a cell, only valid in the search of its generation
+type searchNode struct {
+	cell   *grid.Cell
+	gen    int
+	open   bool
+	closed bool
+	g, f   int
+	parent *searchNode
+	index  int // index in the heap
+}
+
+// Reusable A* scratch space for one grid. Every search bumps the generation
+// instead of clearing the nodes, so searches allocate nothing but the path.
+type Search struct {
+	grid  [][]*grid.Cell
+	nodes []searchNode
+	gen   int
+	open  PriorityQueue
+}
+
+// Create search scratch space for grid
+func NewSearch(grid [][]*grid.Cell) *Search {
+	s := &Search{grid: grid, nodes: make([]searchNode, len(grid)*len(grid[0]))}
+	for y, row := range grid {
+		for x, cell := range row {
+			s.nodes[y*len(row)+x].cell = cell
+		}
+	}
+	return s
+}
+
+// Scratch node of cell, reset when left over from an earlier search
+func (s *Search) node(cell *grid.Cell) *searchNode {
+	n := &s.nodes[cell.Y*len(s.grid[0])+cell.X]
+	if n.gen != s.gen {
+		*n = searchNode{cell: cell, gen: s.gen, index: -1}
+	}
+	return n
+}
+
+// Extra steps charged for entering a cell on top of the step itself, or a
+// negative cost for cells that must not be entered
+type CostFunc func(cell *grid.Cell) int
+
+// Cells a path must not enter for now on top of the walls, such as the cells
+// of pacs in the way
+type Blocked func(cell *grid.Cell) bool
+
+// Cost charging what cost charges and forbidding the blocked cells. A nil
+// blocked forbids nothing.
+func Avoiding(cost CostFunc, blocked Blocked) CostFunc {
+	if blocked == nil {
+		return cost
+	}
+	return func(cell *grid.Cell) int {
+		if blocked(cell) {
+			return -1
+		}
+		if cost == nil {
+			return 0
+		}
+		return cost(cell)
+	}
+}
+
+// Find the shortest path between two cells, nil when there is none
+func (s *Search) Run(startX, startY, endX, endY int) []*grid.Cell {
+	return s.RunWeighted(startX, startY, endX, endY, nil)
+}
+
+// Find the cheapest path between two cells, each step costing one plus what
+// cost charges for the cell entered, nil when there is none. A nil cost
+// charges nothing.
+func (s *Search) RunWeighted(startX, startY, endX, endY int, cost CostFunc) []*grid.Cell {
+	telemetry.CountSearch()
+	s.gen++
+	s.open = s.open[:0]
+	width := len(s.grid[0])
+	goal := grid.GetCell(endX, endY, s.grid)
+	start := s.node(grid.GetCell(startX, startY, s.grid))
+	start.open = true
+	heap.Push(&s.open, start)
+	for s.open.Len() > 0 {
+		current := heap.Pop(&s.open).(*searchNode)
+		current.open = false
+		if current.cell == goal {
+			var path []*grid.Cell
+			for n := current; n != nil; n = n.parent {
+				path = append([]*grid.Cell{n.cell}, path...)
+			}
+			return path
+		}
+		current.closed = true
+
+		for _, cell := range current.cell.Neighbors {
+			if cell.IsWall {
+				continue
+			}
+			neighbor := s.node(cell)
+			if neighbor.closed {
+				continue
+			}
+			tentativeGScore := current.g + 1
+			if cost != nil {
+				extra := cost(cell)
+				if extra < 0 {
+					continue
+				}
+				tentativeGScore += extra
+			}
+			if !neighbor.open {
+				neighbor.open = true
+				heap.Push(&s.open, neighbor)
+			} else if tentativeGScore >= neighbor.g {
+				continue
+			}
+			neighbor.parent = current
+			s.open.update(neighbor, tentativeGScore, grid.ManhattanDistance(cell, goal, width))
+		}
+	}
+	return nil
+}
+
+// Search scratch spaces for reuse, one per concurrent search
+var searches sync.Pool
+
+// Find the shortest path between two cells of grid with A*, nil when there
+// is none
+func AStar(startX, startY, endX, endY int, grid [][]*grid.Cell) []*grid.Cell {
+	return AStarWeighted(startX, startY, endX, endY, grid, nil)
+}
+
+// Find the cheapest path between two cells of grid with A* under cost, nil
+// when there is none
+func AStarWeighted(startX, startY, endX, endY int, grid [][]*grid.Cell, cost CostFunc) []*grid.Cell {
+	s, _ := searches.Get().(*Search)
+	if s == nil || len(s.grid) == 0 || &s.grid[0][0] != &grid[0][0] {
+		s = NewSearch(grid)
+	}
+	defer searches.Put(s)
+	return s.RunWeighted(startX, startY, endX, endY, cost)
+}
+
+// Find the cheapest path of cells under cost around the blocked cells other
+// than the goal, or through them when they close every way, nil when there
+// is no path even then
+func AStarAround(startX, startY, endX, endY int, cells [][]*grid.Cell, cost CostFunc, blocked Blocked) []*grid.Cell {
+	if blocked != nil {
+		goal := grid.GetCell(endX, endY, cells)
+		around := Avoiding(cost, func(cell *grid.Cell) bool {
+			return cell != goal && blocked(cell)
+		})
+		if path := AStarWeighted(startX, startY, endX, endY, cells, around); path != nil {
+			return path
+		}
+	}
+	return AStarWeighted(startX, startY, endX, endY, cells, cost)
+}
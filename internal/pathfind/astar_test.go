@@ -0,0 +1,126 @@
+package pathfind_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/grid"
+	"spring2020/internal/pathfind"
+)
+
+func TestAStar(t *testing.T) {
+	tests := []struct {
+		name       string
+		maze       []string
+		start, end [2]int
+		// steps of the shortest path, -1 when there is none
+		steps int
+	}{
+		{
+			name:  "straight corridor",
+			maze:  []string{"#######", "#     #", "#######"},
+			start: [2]int{1, 1}, end: [2]int{5, 1},
+			steps: 4,
+		},
+		{
+			name:  "around a wall",
+			maze:  []string{"#######", "#  #  #", "#  #  #", "#     #", "#######"},
+			start: [2]int{1, 1}, end: [2]int{5, 1},
+			steps: 8,
+		},
+		{
+			name:  "through the tunnel",
+			maze:  []string{"#######", "  # #  ", "#######"},
+			start: [2]int{1, 1}, end: [2]int{5, 1},
+			steps: 3,
+		},
+		{
+			name:  "start is the goal",
+			maze:  []string{"#####", "#   #", "#####"},
+			start: [2]int{2, 1}, end: [2]int{2, 1},
+			steps: 0,
+		},
+		{
+			name:  "walled off",
+			maze:  []string{"#######", "#  #  #", "#######"},
+			start: [2]int{1, 1}, end: [2]int{5, 1},
+			steps: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells := fixture.Grid(tt.maze...)
+			path := pathfind.AStar(tt.start[0], tt.start[1], tt.end[0], tt.end[1], cells)
+			if tt.steps < 0 {
+				if path != nil {
+					t.Fatalf("got path of %d cells, want none", len(path))
+				}
+				return
+			}
+			if len(path) != tt.steps+1 {
+				t.Fatalf("got path of %d cells, want %d", len(path), tt.steps+1)
+			}
+			if first := path[0]; first.X != tt.start[0] || first.Y != tt.start[1] {
+				t.Errorf("path starts at (%d, %d)", first.X, first.Y)
+			}
+			if last := path[len(path)-1]; last.X != tt.end[0] || last.Y != tt.end[1] {
+				t.Errorf("path ends at (%d, %d)", last.X, last.Y)
+			}
+			for i := 1; i < len(path); i++ {
+				if path[i].IsWall || grid.ManhattanDistance(path[i-1], path[i], len(tt.maze[0])) != 1 {
+					t.Errorf("step %d from (%d, %d) to (%d, %d) is not a move", i,
+						path[i-1].X, path[i-1].Y, path[i].X, path[i].Y)
+				}
+			}
+		})
+	}
+}
+
+func TestAStarReusesScratchAcrossGrids(t *testing.T) {
+	small := fixture.Grid("#####", "#   #", "#####")
+	large := fixture.Grid("#######", "#     #", "#######")
+	if path := pathfind.AStar(1, 1, 3, 1, small); len(path) != 3 {
+		t.Fatalf("small grid path has %d cells, want 3", len(path))
+	}
+	if path := pathfind.AStar(1, 1, 5, 1, large); len(path) != 5 {
+		t.Fatalf("large grid path has %d cells, want 5", len(path))
+	}
+}
+
+func TestAStarAround(t *testing.T) {
+	cells := fixture.Grid("#######", "#     #", "# ### #", "#     #", "#######")
+	blocked := func(cell *grid.Cell) bool {
+		return cell == cells[1][3]
+	}
+	if path := pathfind.AStarAround(1, 1, 5, 1, cells, nil, blocked); len(path) != 9 {
+		t.Errorf("got path of %d cells, want the 9 around the blocked cell", len(path))
+	}
+	// the goal itself is never blocked
+	if path := pathfind.AStarAround(1, 1, 3, 1, cells, nil, blocked); len(path) != 3 {
+		t.Errorf("got path of %d cells to the blocked goal, want 3", len(path))
+	}
+	corridor := fixture.Grid("#######", "#     #", "#######")
+	if path := pathfind.AStarAround(1, 1, 5, 1, corridor, nil, func(cell *grid.Cell) bool {
+		return cell == corridor[1][3]
+	}); len(path) != 5 {
+		t.Errorf("got path of %d cells, want the 5 through the blocked cell", len(path))
+	}
+}
+
+func BenchmarkAStar(b *testing.B) {
+	cells := fixture.Grid(fixture.ContestMaze(1)...)
+	var floor []*grid.Cell
+	for _, row := range cells {
+		for _, cell := range row {
+			if !cell.IsWall {
+				floor = append(floor, cell)
+			}
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// paths across the whole maze, the longest a plan walks
+		start, end := floor[i%len(floor)], floor[len(floor)-1-i%len(floor)]
+		pathfind.AStar(start.X, start.Y, end.X, end.Y, cells)
+	}
+}
@@ -0,0 +1,173 @@
+package pathfind
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/telemetry"
+)
+
+// Cheapest cost from one start cell to every cell of a grid, flooded once
+// with Dijkstra so any number of goals read their paths off it. A field is
+// reflooded in place from another start without allocating.
+type FlowField struct {
+	width  int
+	cells  [][]*grid.Cell
+	start  *grid.Cell
+	cost   []int // -1 where not reached
+	parent []*grid.Cell
+	open   []flowItem
+}
+
+// Dijkstra open set entry
+type flowItem struct {
+	cell *grid.Cell
+	cost int
+}
+
+// Flood grid from start, each step costing one plus what cost charges for
+// the cell entered, as in AStarWeighted. A nil cost charges nothing.
+func NewFlowField(start *grid.Cell, cells [][]*grid.Cell, cost CostFunc) *FlowField {
+	f := &FlowField{
+		width:  len(cells[0]),
+		cells:  cells,
+		cost:   make([]int, len(cells)*len(cells[0])),
+		parent: make([]*grid.Cell, len(cells)*len(cells[0])),
+	}
+	f.Flood(start, cost)
+	return f
+}
+
+// Check if the field was made for grid, so it can be reflooded on it
+func (f *FlowField) Fits(cells [][]*grid.Cell) bool {
+	return len(f.cells) > 0 && len(cells) > 0 && &f.cells[0][0] == &cells[0][0]
+}
+
+// Reflood the field from start under cost, reusing its buffers
+func (f *FlowField) Flood(start *grid.Cell, cost CostFunc) {
+	telemetry.CountFlood()
+	f.start = start
+	for i := range f.cost {
+		f.cost[i], f.parent[i] = -1, nil
+	}
+	f.open = f.open[:0]
+	if start.IsWall {
+		return
+	}
+	f.cost[f.index(start)] = 0
+	f.push(flowItem{start, 0})
+	for len(f.open) > 0 {
+		current := f.pop()
+		if current.cost > f.cost[f.index(current.cell)] {
+			continue
+		}
+		for _, cell := range current.cell.Neighbors {
+			if cell.IsWall {
+				continue
+			}
+			step := current.cost + 1
+			if cost != nil {
+				extra := cost(cell)
+				if extra < 0 {
+					continue
+				}
+				step += extra
+			}
+			if i := f.index(cell); f.cost[i] < 0 || step < f.cost[i] {
+				f.cost[i], f.parent[i] = step, current.cell
+				f.push(flowItem{cell, step})
+			}
+		}
+	}
+}
+
+// Push item on the open set, a binary heap by cost kept by hand as
+// container/heap boxes every item it is given
+func (f *FlowField) push(item flowItem) {
+	f.open = append(f.open, item)
+	for i := len(f.open) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if f.open[parent].cost <= f.open[i].cost {
+			break
+		}
+		f.open[parent], f.open[i] = f.open[i], f.open[parent]
+		i = parent
+	}
+}
+
+// Pop the cheapest item off the open set
+func (f *FlowField) pop() flowItem {
+	top := f.open[0]
+	last := len(f.open) - 1
+	f.open[0] = f.open[last]
+	f.open = f.open[:last]
+	for i := 0; ; {
+		least, left, right := i, 2*i+1, 2*i+2
+		if left < last && f.open[left].cost < f.open[least].cost {
+			least = left
+		}
+		if right < last && f.open[right].cost < f.open[least].cost {
+			least = right
+		}
+		if least == i {
+			break
+		}
+		f.open[least], f.open[i] = f.open[i], f.open[least]
+		i = least
+	}
+	return top
+}
+
+func (f *FlowField) index(cell *grid.Cell) int {
+	return cell.Y*f.width + cell.X
+}
+
+// Cell the field was flooded from
+func (f *FlowField) Start() *grid.Cell {
+	return f.start
+}
+
+// Cheapest cost from the start to cell, false when it cannot be reached
+func (f *FlowField) Cost(cell *grid.Cell) (int, bool) {
+	c := f.cost[f.index(cell)]
+	return c, c >= 0
+}
+
+// Cheapest path from the start to goal both included, nil when goal cannot
+// be reached
+func (f *FlowField) PathTo(goal *grid.Cell) []*grid.Cell {
+	if _, ok := f.Cost(goal); !ok {
+		return nil
+	}
+	var path []*grid.Cell
+	for cell := goal; cell != nil; cell = f.parent[f.index(cell)] {
+		path = append(path, cell)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Neighbor of the start the cheapest path to goal moves to, nil when goal is
+// the start or cannot be reached
+func (f *FlowField) StepToward(goal *grid.Cell) *grid.Cell {
+	if _, ok := f.Cost(goal); !ok || goal == f.start {
+		return nil
+	}
+	cell := goal
+	for f.parent[f.index(cell)] != f.start {
+		cell = f.parent[f.index(cell)]
+	}
+	return cell
+}
+
+// Cheapest of goals to reach and its cost, nil when none can be reached
+func (f *FlowField) Nearest(goals []*grid.Cell) (*grid.Cell, int) {
+	var nearest *grid.Cell
+	best := 0
+	for _, goal := range goals {
+		if c, ok := f.Cost(goal); ok && (nearest == nil || c < best) {
+			nearest, best = goal, c
+		}
+	}
+	return nearest, best
+}
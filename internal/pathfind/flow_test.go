@@ -0,0 +1,98 @@
+package pathfind_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/grid"
+	"spring2020/internal/pathfind"
+)
+
+func TestFlowFieldMatchesDistances(t *testing.T) {
+	cells := fixture.Grid(fixture.ContestMaze(3)...)
+	table := grid.NewDistanceTable(cells, false)
+	var start *grid.Cell
+	for _, row := range cells {
+		for _, cell := range row {
+			if start == nil && !cell.IsWall {
+				start = cell
+			}
+		}
+	}
+	flow := pathfind.NewFlowField(start, cells, nil)
+	for _, row := range cells {
+		for _, cell := range row {
+			want, wantOk := table.Between(start, cell)
+			got, ok := flow.Cost(cell)
+			if ok != wantOk || (ok && got != want) {
+				t.Fatalf("(%d, %d): got %d %v, want %d %v", cell.X, cell.Y, got, ok, want, wantOk)
+			}
+			if path := flow.PathTo(cell); ok && (len(path) != want+1 || path[0] != start || path[len(path)-1] != cell) {
+				t.Fatalf("(%d, %d): path of %d cells, want %d", cell.X, cell.Y, len(path), want+1)
+			}
+		}
+	}
+}
+
+func TestFlowFieldAvoidsCostlyCells(t *testing.T) {
+	cells := fixture.Grid(
+		"#######",
+		"#     #",
+		"# ### #",
+		"#     #",
+		"#######",
+	)
+	blocked := cells[1][3]
+	cost := func(cell *grid.Cell) int {
+		if cell == blocked {
+			return -1
+		}
+		return 0
+	}
+	flow := pathfind.NewFlowField(cells[1][1], cells, cost)
+	if _, ok := flow.Cost(blocked); ok {
+		t.Error("forbidden cell reached")
+	}
+	if got, _ := flow.Cost(cells[1][5]); got != 8 {
+		t.Errorf("cost to (5, 1) %d, want 8 around the block", got)
+	}
+	if step := flow.StepToward(cells[1][5]); step != cells[2][1] {
+		t.Errorf("first step (%d, %d), want (1, 2)", step.X, step.Y)
+	}
+	if step := flow.StepToward(cells[1][1]); step != nil {
+		t.Error("step toward the start")
+	}
+	if !flow.Fits(cells) || flow.Fits(fixture.Grid("   ")) {
+		t.Error("field fits the wrong grid")
+	}
+	goal, c := flow.Nearest([]*grid.Cell{cells[1][5], cells[3][3], blocked})
+	if goal != cells[3][3] || c != 4 {
+		t.Errorf("nearest goal (%d, %d) at %d, want (3, 3) at 4", goal.X, goal.Y, c)
+	}
+	// reflooded from elsewhere without the block
+	flow.Flood(cells[1][5], nil)
+	if got, _ := flow.Cost(cells[1][1]); got != 4 {
+		t.Errorf("reflooded cost to (1, 1) %d, want 4", got)
+	}
+	if flow.Start() != cells[1][5] {
+		t.Error("reflooded field keeps its old start")
+	}
+}
+
+func BenchmarkFlowField(b *testing.B) {
+	cells := fixture.Grid(fixture.ContestMaze(1)...)
+	var floor []*grid.Cell
+	for _, row := range cells {
+		for _, cell := range row {
+			if !cell.IsWall {
+				floor = append(floor, cell)
+			}
+		}
+	}
+	flow := pathfind.NewFlowField(floor[0], cells, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		flow.Flood(floor[i%len(floor)], nil)
+	}
+}
@@ -0,0 +1,144 @@
+// Package protocol parses the command lines bots print each turn, shared by
+// the bot's self-validation and the local referee.
+package protocol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Command verbs
+const (
+	VerbMove   = "MOVE"
+	VerbSpeed  = "SPEED"
+	VerbSwitch = "SWITCH"
+)
+
+// Pac types a SWITCH may name
+var PacTypes = []string{"ROCK", "PAPER", "SCISSORS"}
+
+// One parsed command of a command line
+type ParsedCommand struct {
+	Verb    string
+	PacId   int
+	X, Y    int
+	TypeId  string
+	Message string
+	// Source text of the command
+	Text string
+}
+
+// Error of a single command in a command line
+type CommandError struct {
+	Index  int
+	Text   string
+	Reason string
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("command %d %q: %s", e.Index, e.Text, e.Reason)
+}
+
+// Parse a command line into its commands. Commands that fail to parse are
+// returned as errors and left out, the others are parsed regardless.
+func ParseCommands(line string) ([]ParsedCommand, []error) {
+	var commands []ParsedCommand
+	var errs []error
+	for i, text := range strings.Split(line, "|") {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		command, reason := parseCommand(text)
+		if reason != "" {
+			errs = append(errs, &CommandError{Index: i, Text: text, Reason: reason})
+			continue
+		}
+		commands = append(commands, command)
+	}
+	return commands, errs
+}
+
+// Parse one command, returning the reason when it is invalid
+func parseCommand(text string) (ParsedCommand, string) {
+	fields := strings.Fields(text)
+	command := ParsedCommand{Verb: strings.ToUpper(fields[0]), Text: text}
+	var args int
+	switch command.Verb {
+	case VerbMove:
+		args = 3
+	case VerbSpeed:
+		args = 1
+	case VerbSwitch:
+		args = 2
+	default:
+		return command, "unknown verb " + fields[0]
+	}
+	if len(fields) < 1+args {
+		return command, fmt.Sprintf("%s needs %d arguments", command.Verb, args)
+	}
+	var err error
+	if command.PacId, err = strconv.Atoi(fields[1]); err != nil {
+		return command, "bad pac id " + fields[1]
+	}
+	switch command.Verb {
+	case VerbMove:
+		if command.X, err = strconv.Atoi(fields[2]); err != nil {
+			return command, "bad x " + fields[2]
+		}
+		if command.Y, err = strconv.Atoi(fields[3]); err != nil {
+			return command, "bad y " + fields[3]
+		}
+	case VerbSwitch:
+		command.TypeId = strings.ToUpper(fields[2])
+		if !validType(command.TypeId) {
+			return command, "unknown pac type " + fields[2]
+		}
+	}
+	if len(fields) > 1+args {
+		command.Message = strings.Join(fields[1+args:], " ")
+	}
+	return command, ""
+}
+
+// Check if typeId names a pac type
+func validType(typeId string) bool {
+	for _, t := range PacTypes {
+		if t == typeId {
+			return true
+		}
+	}
+	return false
+}
+
+// Check parsed commands against the pacs a player owns: every command must
+// name one of its living pacs, at most once, and MOVE targets must lie on
+// the map. Returns the commands that pass and errors for the others.
+func ValidateCommands(commands []ParsedCommand, pacIds []int, width, height int) ([]ParsedCommand, []error) {
+	owned := make(map[int]bool)
+	for _, id := range pacIds {
+		owned[id] = true
+	}
+	seen := make(map[int]bool)
+	var valid []ParsedCommand
+	var errs []error
+	for i, command := range commands {
+		reason := ""
+		switch {
+		case !owned[command.PacId]:
+			reason = fmt.Sprintf("pac %d is not owned", command.PacId)
+		case seen[command.PacId]:
+			reason = fmt.Sprintf("pac %d already has a command", command.PacId)
+		case command.Verb == VerbMove && (command.X < 0 || command.X >= width || command.Y < 0 || command.Y >= height):
+			reason = fmt.Sprintf("target (%d, %d) is off the map", command.X, command.Y)
+		}
+		if reason != "" {
+			errs = append(errs, &CommandError{Index: i, Text: command.Text, Reason: reason})
+			continue
+		}
+		seen[command.PacId] = true
+		valid = append(valid, command)
+	}
+	return valid, errs
+}
@@ -0,0 +1,81 @@
+// Package random is the one source of the bot's random choices. A game's
+// seed comes from -seed or the SPRING2020_SEED environment variable, else
+// from the clock, and is logged at startup where LoggedSeed finds it again:
+// rerunning the input of a game with its seed makes the same choices again.
+package random
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variable with the seed of local runs
+const SeedEnvVar = "SPRING2020_SEED"
+
+// Random choices of a game, all derived from its seed. Every turn gets its
+// own stream, so what one turn draws does not depend on how far a search
+// got in the turns before. A nil RNG has seed 0, which is how tests play.
+type RNG struct {
+	seed int64
+}
+
+// Create the RNG of seed
+func NewRNG(seed int64) *RNG {
+	return &RNG{seed: seed}
+}
+
+// RNG of the seed in text, seeded from the clock when text is empty
+func ParseSeed(text string) (*RNG, error) {
+	if text == "" {
+		return NewRNG(time.Now().UnixNano()), nil
+	}
+	seed, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("seed %q is not an integer", text)
+	}
+	return NewRNG(seed), nil
+}
+
+// Seed of the game
+func (r *RNG) Seed() int64 {
+	if r == nil {
+		return 0
+	}
+	return r.seed
+}
+
+// Line the bot logs its seed on at startup, read back by LoggedSeed
+func (r *RNG) LogLine() string {
+	return fmt.Sprintf("Seed %d", r.Seed())
+}
+
+// Seed of the LogLine in a stderr log, false when the log holds none
+func LoggedSeed(log string) (string, bool) {
+	for _, line := range strings.Split(log, "\n") {
+		if m := seedLine.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			return m[1], true
+		}
+	}
+	return "", false
+}
+
+// LogLine as written to the log, the turn prefix present once turns started
+var seedLine = regexp.MustCompile(`^(?:\[\d+\] )?Seed (-?\d+)$`)
+
+// Seed of the draws of turn, mixed from the game seed with splitmix64 so
+// consecutive turns get unrelated streams
+func (r *RNG) TurnSeed(turn int) int64 {
+	z := uint64(r.Seed()) + uint64(turn)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return int64(z ^ z>>31)
+}
+
+// Generator of the draws of turn
+func (r *RNG) Turn(turn int) *rand.Rand {
+	return rand.New(rand.NewSource(r.TurnSeed(turn)))
+}
@@ -0,0 +1,38 @@
+package random_test
+
+import (
+	"testing"
+
+	"spring2020/internal/random"
+)
+
+func TestRNG(t *testing.T) {
+	var none *random.RNG
+	if none.Seed() != 0 || none.TurnSeed(3) != random.NewRNG(0).TurnSeed(3) {
+		t.Error("nil RNG does not play seed 0")
+	}
+	a, err := random.ParseSeed("42")
+	if err != nil || a.Seed() != 42 {
+		t.Fatalf("got seed %d, %v, want 42", a.Seed(), err)
+	}
+	b := random.NewRNG(42)
+	if a.Turn(5).Int63() != b.Turn(5).Int63() {
+		t.Error("same seed and turn draw different numbers")
+	}
+	if a.TurnSeed(5) == a.TurnSeed(6) || a.TurnSeed(5) == random.NewRNG(43).TurnSeed(5) {
+		t.Error("turns or seeds share a stream")
+	}
+	if _, err := random.ParseSeed("x"); err == nil {
+		t.Error("parsed a seed that is not a number")
+	}
+	if clock, err := random.ParseSeed(""); err != nil || clock.Seed() == 0 {
+		t.Errorf("no seed from the clock: %v", err)
+	}
+	log := "<< 3 1\n" + random.NewRNG(-42).LogLine() + "\n[1] Turn 1\n"
+	if seed, ok := random.LoggedSeed(log); !ok || seed != "-42" {
+		t.Errorf("read seed %q, %v from the log, want -42", seed, ok)
+	}
+	if _, ok := random.LoggedSeed("<< 3 1\n"); ok {
+		t.Error("read a seed from a log without one")
+	}
+}
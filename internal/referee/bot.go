@@ -0,0 +1,90 @@
+package referee
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Bot answered no line before the turn deadline
+var ErrTimeout = errors.New("timeout")
+
+// Bot running as a subprocess, fed turn input on stdin and answering one
+// command line per turn on stdout
+type Bot struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string
+}
+
+// Start the bot command line, a program followed by its arguments, sending
+// its stderr to stderr when not nil
+func StartBot(command string, stderr io.Writer) (*Bot, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("empty bot command")
+	}
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", command, err)
+	}
+	b := &Bot{cmd: cmd, stdin: stdin, lines: make(chan string, 1)}
+	go func() {
+		defer close(b.lines)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 1000000), 1000000)
+		for scanner.Scan() {
+			b.lines <- scanner.Text()
+		}
+	}()
+	return b, nil
+}
+
+// Send input lines to the bot
+func (b *Bot) Send(lines []string) error {
+	_, err := io.WriteString(b.stdin, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// Read the next output line of the bot, failing when none came by deadline
+func (b *Bot) Receive(deadline time.Time) (string, error) {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case line, ok := <-b.lines:
+		if !ok {
+			return "", errors.New("bot exited")
+		}
+		return line, nil
+	case <-timer.C:
+		// a line may have come in at the deadline
+		select {
+		case line, ok := <-b.lines:
+			if ok {
+				return line, nil
+			}
+		default:
+		}
+		return "", ErrTimeout
+	}
+}
+
+// Stop the bot, which has no way to tell the game ended but the input closing
+func (b *Bot) Close() {
+	b.stdin.Close()
+	b.cmd.Process.Kill()
+	b.cmd.Wait()
+}
@@ -0,0 +1,147 @@
+package referee
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"spring2020/internal/arena"
+	"spring2020/internal/mapgen"
+)
+
+// Response time limits of the contest
+const (
+	FirstTurnTimeout = 1000 * time.Millisecond
+	TurnTimeout      = 50 * time.Millisecond
+)
+
+// Settings of a match between two bot commands
+type Match struct {
+	Seed     int64
+	Map      mapgen.Options
+	Commands [2]string
+	// Stderr of each bot, discarded when nil
+	Stderr [2]io.Writer
+	// Response time limits, the contest ones when zero
+	FirstTurnTimeout time.Duration
+	TurnTimeout      time.Duration
+}
+
+// Final state of a played match
+type Record struct {
+	Seed     int64
+	Map      *mapgen.Map
+	Turns    int
+	Scores   [2]int
+	PacsLost [2]int
+	Supers   [2]int
+	// Players disqualified for a timeout, a crash or an invalid command, and why
+	Disqualified [2]error
+}
+
+// Winner of the match, -1 on a draw. A disqualified player loses.
+func (r *Record) Winner() int {
+	switch {
+	case r.Disqualified[0] != nil && r.Disqualified[1] != nil:
+		return -1
+	case r.Disqualified[0] != nil:
+		return 1
+	case r.Disqualified[1] != nil:
+		return 0
+	case r.Scores[0] > r.Scores[1]:
+		return 0
+	case r.Scores[1] > r.Scores[0]:
+		return 1
+	}
+	return -1
+}
+
+// Arena result of the match for player against the other one
+func (r *Record) Result(player int, bot, opponent string) arena.Result {
+	other := 1 - player
+	result := arena.Result{
+		Seed:             r.Seed,
+		Width:            r.Map.Width,
+		Height:           r.Map.Height,
+		PacsPerPlayer:    len(r.Map.Pacs),
+		Bot:              bot,
+		Opponent:         opponent,
+		Outcome:          arena.Draw,
+		Turns:            r.Turns,
+		MyScore:          r.Scores[player],
+		OpponentScore:    r.Scores[other],
+		Timeout:          r.Disqualified[player] != nil,
+		MyPacsLost:       r.PacsLost[player],
+		OpponentPacsLost: r.PacsLost[other],
+		MySupers:         r.Supers[player],
+		OpponentSupers:   r.Supers[other],
+	}
+	switch r.Winner() {
+	case player:
+		result.Outcome = arena.Win
+	case other:
+		result.Outcome = arena.Loss
+	}
+	return result
+}
+
+// Play the match on the map generated from its seed, the bots starting and
+// stopping with it. The match ends early when a player is disqualified.
+func (m Match) Play() (*Record, error) {
+	first, turn := m.FirstTurnTimeout, m.TurnTimeout
+	if first == 0 {
+		first = FirstTurnTimeout
+	}
+	if turn == 0 {
+		turn = TurnTimeout
+	}
+	var bots [2]*Bot
+	for i, command := range m.Commands {
+		bot, err := StartBot(command, m.Stderr[i])
+		if err != nil {
+			return nil, err
+		}
+		defer bot.Close()
+		bots[i] = bot
+	}
+	game := NewGame(mapgen.Generate(m.Seed, m.Map))
+	record := &Record{Seed: m.Seed, Map: game.Map}
+	for player, bot := range bots {
+		if err := bot.Send(game.Header()); err != nil {
+			record.Disqualified[player] = err
+		}
+	}
+	for !game.Over() && record.Disqualified[0] == nil && record.Disqualified[1] == nil {
+		timeout := turn
+		if game.Turn == 0 {
+			timeout = first
+		}
+		// both players get their input before either answers, as they play
+		// simultaneously, each with its own clock
+		var deadlines [2]time.Time
+		for player, bot := range bots {
+			if err := bot.Send(game.Input(player)); err != nil {
+				record.Disqualified[player] = err
+			}
+			deadlines[player] = time.Now().Add(timeout)
+		}
+		for player, bot := range bots {
+			if record.Disqualified[player] != nil {
+				continue
+			}
+			line, err := bot.Receive(deadlines[player])
+			if err == nil {
+				err = game.Command(player, line)
+			}
+			if err != nil {
+				record.Disqualified[player] = fmt.Errorf("turn %d: %w", game.Turn+1, err)
+			}
+		}
+		game.Step()
+	}
+	record.Turns = game.Turn
+	record.Scores = game.Scores
+	record.PacsLost = game.PacsLost
+	record.Supers = game.Supers
+	return record, nil
+}
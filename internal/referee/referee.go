@@ -0,0 +1,422 @@
+// Package referee plays Spring Challenge 2020 games locally: the rules of
+// the contest referee (movement, collisions, type battles, pellets,
+// abilities and fog of war) over maps from mapgen, and matches between two
+// bot processes on top of them.
+package referee
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"spring2020/internal/mapgen"
+	"spring2020/internal/protocol"
+)
+
+// Rule constants of the contest
+const (
+	MaxTurns = 200
+	// Turns a SPEED lasts, counting the turn it is activated
+	SpeedDuration = 6
+	// Turns before an ability can be used again, counting the turn it is used
+	AbilityCooldown = 10
+	SuperValue      = 10
+	// Type a dead pac is reported with
+	DeadType = "DEAD"
+)
+
+// Type each type beats
+var beats = map[string]string{"ROCK": "SCISSORS", "PAPER": "ROCK", "SCISSORS": "PAPER"}
+
+// Pac of one player
+type Pac struct {
+	Id              int
+	Owner           int
+	Pos             mapgen.Point
+	TypeId          string
+	SpeedTurnsLeft  int
+	AbilityCooldown int
+	Dead            bool
+
+	// command for the turn being played
+	target  *mapgen.Point
+	ability bool
+	// position before the current move step
+	from mapgen.Point
+}
+
+// Game between players 0 and 1 on a generated map
+type Game struct {
+	Map     *mapgen.Map
+	Turn    int
+	Scores  [2]int
+	Pacs    []*Pac
+	Pellets map[mapgen.Point]int
+	// Pacs each player lost and super pellets each player ate
+	PacsLost [2]int
+	Supers   [2]int
+}
+
+// Create a game on m with a pellet on every floor cell without a pac, pacs
+// of player 1 mirrored from those of player 0
+func NewGame(m *mapgen.Map) *Game {
+	g := &Game{Map: m, Pellets: make(map[mapgen.Point]int)}
+	occupied := make(map[mapgen.Point]bool)
+	for _, spawn := range m.Pacs {
+		for owner, pos := range []mapgen.Point{spawn.Point, m.Mirror(spawn.Point)} {
+			g.Pacs = append(g.Pacs, &Pac{Id: spawn.Id, Owner: owner, Pos: pos, TypeId: spawn.TypeId})
+			occupied[pos] = true
+		}
+	}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if p := (mapgen.Point{X: x, Y: y}); m.IsFloor(x, y) && !occupied[p] {
+				g.Pellets[p] = 1
+			}
+		}
+	}
+	for _, p := range m.Supers {
+		g.Pellets[p] = SuperValue
+	}
+	return g
+}
+
+// Living pacs of player
+func (g *Game) living(player int) []*Pac {
+	var pacs []*Pac
+	for _, pac := range g.Pacs {
+		if pac.Owner == player && !pac.Dead {
+			pacs = append(pacs, pac)
+		}
+	}
+	return pacs
+}
+
+// Ids of the living pacs of player
+func (g *Game) PacIds(player int) []int {
+	var ids []int
+	for _, pac := range g.living(player) {
+		ids = append(ids, pac.Id)
+	}
+	return ids
+}
+
+// Lines sent to both players before the first turn
+func (g *Game) Header() []string {
+	return append([]string{fmt.Sprintf("%d %d", g.Map.Width, g.Map.Height)}, g.Map.Lines()...)
+}
+
+// Cells in sight of the living pacs of player: their own cells and the
+// straight lines from them up to the first wall, wrapping through tunnels
+func (g *Game) Sight(player int) map[mapgen.Point]bool {
+	sight := make(map[mapgen.Point]bool)
+	for _, pac := range g.living(player) {
+		sight[pac.Pos] = true
+		for _, d := range []mapgen.Point{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}} {
+			for p := g.step(pac.Pos, d); g.Map.IsFloor(p.X, p.Y) && p != pac.Pos; p = g.step(p, d) {
+				sight[p] = true
+			}
+		}
+	}
+	return sight
+}
+
+// Cell next to p in direction d, wrapping horizontally
+func (g *Game) step(p, d mapgen.Point) mapgen.Point {
+	return mapgen.Point{X: (p.X + d.X + g.Map.Width) % g.Map.Width, Y: p.Y + d.Y}
+}
+
+// Lines sent to player at the start of the current turn: scores, its pacs
+// and the opponent pacs in sight, the pellets in sight and every super
+// pellet. Dead pacs are reported as DEAD to their owner.
+func (g *Game) Input(player int) []string {
+	sight := g.Sight(player)
+	lines := []string{fmt.Sprintf("%d %d", g.Scores[player], g.Scores[1-player])}
+	var pacs []string
+	for _, mine := range []bool{true, false} {
+		for _, pac := range g.Pacs {
+			if (pac.Owner == player) != mine || (!mine && (pac.Dead || !sight[pac.Pos])) {
+				continue
+			}
+			typeId := pac.TypeId
+			if pac.Dead {
+				typeId = DeadType
+			}
+			flag := 0
+			if mine {
+				flag = 1
+			}
+			pacs = append(pacs, fmt.Sprintf("%d %d %d %d %s %d %d", pac.Id, flag, pac.Pos.X, pac.Pos.Y,
+				typeId, pac.SpeedTurnsLeft, pac.AbilityCooldown))
+		}
+	}
+	lines = append(lines, fmt.Sprint(len(pacs)))
+	lines = append(lines, pacs...)
+	var pellets []mapgen.Point
+	for p, value := range g.Pellets {
+		if sight[p] || value == SuperValue {
+			pellets = append(pellets, p)
+		}
+	}
+	sort.Slice(pellets, func(i, j int) bool {
+		if pellets[i].Y != pellets[j].Y {
+			return pellets[i].Y < pellets[j].Y
+		}
+		return pellets[i].X < pellets[j].X
+	})
+	lines = append(lines, fmt.Sprint(len(pellets)))
+	for _, p := range pellets {
+		lines = append(lines, fmt.Sprintf("%d %d %d", p.X, p.Y, g.Pellets[p]))
+	}
+	return lines
+}
+
+// Parse and apply the command line of player for the current turn. Any
+// invalid command is an error, as the contest referee disqualifies players
+// for them. Pacs without a command stand still.
+func (g *Game) Command(player int, line string) error {
+	commands, errs := protocol.ParseCommands(line)
+	valid, invalid := protocol.ValidateCommands(commands, g.PacIds(player), g.Map.Width, g.Map.Height)
+	errs = append(errs, invalid...)
+	if len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, err := range errs {
+			msgs[i] = err.Error()
+		}
+		return fmt.Errorf("player %d: %s", player, strings.Join(msgs, "; "))
+	}
+	for _, command := range valid {
+		pac := g.pac(player, command.PacId)
+		switch command.Verb {
+		case protocol.VerbMove:
+			pac.target = &mapgen.Point{X: command.X, Y: command.Y}
+		case protocol.VerbSpeed, protocol.VerbSwitch:
+			if pac.AbilityCooldown > 0 {
+				continue
+			}
+			pac.ability = true
+			pac.AbilityCooldown = AbilityCooldown
+			if command.Verb == protocol.VerbSpeed {
+				pac.SpeedTurnsLeft = SpeedDuration
+			} else {
+				pac.TypeId = command.TypeId
+			}
+		}
+	}
+	return nil
+}
+
+// Pac id of player
+func (g *Game) pac(player, id int) *Pac {
+	for _, pac := range g.Pacs {
+		if pac.Owner == player && pac.Id == id {
+			return pac
+		}
+	}
+	return nil
+}
+
+// Play the current turn with the commands given: abilities were applied by
+// Command, pacs that did not use one move a cell towards their target, then
+// fast pacs move a second cell. Each step resolves collisions, type battles
+// and eaten pellets.
+func (g *Game) Step() {
+	g.Turn++
+	for step := 0; step < 2; step++ {
+		var moving []*Pac
+		for _, pac := range g.Pacs {
+			pac.from = pac.Pos
+			if pac.Dead || pac.ability || pac.target == nil || (step == 1 && pac.SpeedTurnsLeft == 0) {
+				continue
+			}
+			moving = append(moving, pac)
+		}
+		for _, pac := range moving {
+			pac.Pos = g.next(pac.Pos, *pac.target)
+		}
+		g.resolveCollisions()
+		g.resolveBattles()
+		g.eat()
+	}
+	for _, pac := range g.Pacs {
+		pac.target = nil
+		pac.ability = false
+		if pac.SpeedTurnsLeft > 0 {
+			pac.SpeedTurnsLeft--
+		}
+		if pac.AbilityCooldown > 0 {
+			pac.AbilityCooldown--
+		}
+	}
+	for player := 0; player < 2; player++ {
+		if len(g.living(player)) == 0 && len(g.living(1-player)) > 0 {
+			// the surviving player gets the pellets left
+			for p, value := range g.Pellets {
+				g.Scores[1-player] += value
+				delete(g.Pellets, p)
+			}
+		}
+	}
+}
+
+// Cell after one step from p on a shortest path to target, or towards the
+// reachable cell closest to target when it cannot be reached
+func (g *Game) next(p, target mapgen.Point) mapgen.Point {
+	if p == target {
+		return p
+	}
+	dist := g.flood(p)
+	if _, ok := dist[target]; !ok {
+		best, bestDist := p, -1
+		for cell := range dist {
+			d := g.manhattan(cell, target)
+			if bestDist < 0 || d < bestDist || (d == bestDist && less(cell, best)) {
+				best, bestDist = cell, d
+			}
+		}
+		if best == p {
+			return p
+		}
+		target = best
+	}
+	// walk back from the target to the cell next to p
+	back := g.flood(target)
+	for _, d := range []mapgen.Point{{Y: -1}, {X: 1}, {Y: 1}, {X: -1}} {
+		n := g.step(p, d)
+		if d, ok := back[n]; ok && d == back[p]-1 {
+			return n
+		}
+	}
+	return p
+}
+
+// Order of cells by row then column for deterministic tie breaks
+func less(a, b mapgen.Point) bool {
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	return a.X < b.X
+}
+
+// Distance from start to every reachable floor cell
+func (g *Game) flood(start mapgen.Point) map[mapgen.Point]int {
+	dist := map[mapgen.Point]int{start: 0}
+	queue := []mapgen.Point{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, d := range []mapgen.Point{{Y: -1}, {X: 1}, {Y: 1}, {X: -1}} {
+			n := g.step(current, d)
+			if _, seen := dist[n]; seen || !g.Map.IsFloor(n.X, n.Y) {
+				continue
+			}
+			dist[n] = dist[current] + 1
+			queue = append(queue, n)
+		}
+	}
+	return dist
+}
+
+// Manhattan distance wrapping horizontally
+func (g *Game) manhattan(a, b mapgen.Point) int {
+	dx := a.X - b.X
+	if dx < 0 {
+		dx = -dx
+	}
+	if g.Map.Width-dx < dx {
+		dx = g.Map.Width - dx
+	}
+	dy := a.Y - b.Y
+	if dy < 0 {
+		dy = -dy
+	}
+	return dx + dy
+}
+
+// Check if a and b ended the step on the same cell or crossed each other
+func met(a, b *Pac) bool {
+	return a.Pos == b.Pos || (a.Pos == b.from && b.Pos == a.from)
+}
+
+// Send back pacs that collide with a pac of their own or of the same type,
+// until no collisions are left as a pac sent back may collide again
+func (g *Game) resolveCollisions() {
+	for changed := true; changed; {
+		changed = false
+		for i, a := range g.Pacs {
+			for _, b := range g.Pacs[i+1:] {
+				if a.Dead || b.Dead || !met(a, b) || (a.Owner != b.Owner && a.TypeId != b.TypeId) {
+					continue
+				}
+				for _, pac := range []*Pac{a, b} {
+					if pac.Pos != pac.from {
+						pac.Pos = pac.from
+						changed = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// Kill pacs that met an opponent pac beating their type
+func (g *Game) resolveBattles() {
+	var eaten []*Pac
+	for i, a := range g.Pacs {
+		for _, b := range g.Pacs[i+1:] {
+			if a.Dead || b.Dead || a.Owner == b.Owner || !met(a, b) {
+				continue
+			}
+			if beats[a.TypeId] == b.TypeId {
+				eaten = append(eaten, b)
+			} else if beats[b.TypeId] == a.TypeId {
+				eaten = append(eaten, a)
+			}
+		}
+	}
+	for _, pac := range eaten {
+		if !pac.Dead {
+			pac.Dead = true
+			g.PacsLost[pac.Owner]++
+		}
+	}
+}
+
+// Score the pellets under living pacs, both players scoring a pellet their
+// pacs reach in the same step
+func (g *Game) eat() {
+	eaters := make(map[mapgen.Point][2]bool)
+	for _, pac := range g.Pacs {
+		if _, ok := g.Pellets[pac.Pos]; ok && !pac.Dead {
+			owners := eaters[pac.Pos]
+			owners[pac.Owner] = true
+			eaters[pac.Pos] = owners
+		}
+	}
+	for p, owners := range eaters {
+		for player, ate := range owners {
+			if !ate {
+				continue
+			}
+			g.Scores[player] += g.Pellets[p]
+			if g.Pellets[p] == SuperValue {
+				g.Supers[player]++
+			}
+		}
+		delete(g.Pellets, p)
+	}
+}
+
+// Check if the game is over: the turn limit is reached, no pellets are
+// left or the trailing player cannot catch up with the pellets left
+func (g *Game) Over() bool {
+	left := 0
+	for _, value := range g.Pellets {
+		left += value
+	}
+	lead := g.Scores[0] - g.Scores[1]
+	if lead < 0 {
+		lead = -lead
+	}
+	return g.Turn >= MaxTurns || left == 0 || lead > left
+}
@@ -0,0 +1,112 @@
+package state
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/pathfind"
+)
+
+// Share of the danger left on cells an opponent pac reaches a turn later
+const DangerDecay = 0.5
+
+// Turns ahead the danger map looks
+const DangerTurns = 2
+
+// Danger to a pac of type t on each cell: 1 where an opponent pac in sight
+// beating t reaches next turn, DangerDecay where it reaches the turn after,
+// and DangerDecay where one that may SWITCH to the counter of t reaches the
+// turn after switching
+func (g *Game) dangerTo(t string) map[*grid.Cell]float64 {
+	danger := make(map[*grid.Cell]float64)
+	mark := func(cell *grid.Cell, value float64) {
+		if value > danger[cell] {
+			danger[cell] = value
+		}
+	}
+	for _, enemy := range g.VisibleEnemies() {
+		beats := Matchup(enemy.TypeId, t) == 1
+		if !beats && !g.EnemyMayCounter(enemy, DangerTurns) {
+			continue
+		}
+		start := grid.GetCell(enemy.X, enemy.Y, g.Grid)
+		for cell, d := range cellsWithin(start, enemy.Reach(DangerTurns)) {
+			switch {
+			case beats && d <= enemy.Reach(1):
+				mark(cell, 1)
+			case beats:
+				mark(cell, DangerDecay)
+			case d <= enemy.Reach(2)-enemy.Reach(1):
+				// switching takes its turn, it moves on the next one
+				mark(cell, DangerDecay)
+			}
+		}
+	}
+	return danger
+}
+
+// Danger maps for the types of my pacs
+func (g *Game) ComputeDanger() map[string]map[*grid.Cell]float64 {
+	danger := make(map[string]map[*grid.Cell]float64)
+	for _, pac := range g.MyPacs {
+		if _, ok := danger[pac.TypeId]; !ok {
+			danger[pac.TypeId] = g.dangerTo(pac.TypeId)
+		}
+	}
+	return danger
+}
+
+// Danger to pac on cell
+func (g *Game) DangerTo(pac *Pac, cell *grid.Cell) float64 {
+	return g.Danger[pac.TypeId][cell]
+}
+
+// Extra steps pac is charged for entering cells in danger within the turns
+// the danger map looks ahead, cells where an enemy eating it arrives next
+// turn forbidden
+func (g *Game) DangerCost(pac *Pac) pathfind.CostFunc {
+	start := grid.GetCell(pac.X, pac.Y, g.Grid)
+	return func(cell *grid.Cell) int {
+		danger := g.DangerTo(pac, cell)
+		if danger == 0 {
+			return 0
+		}
+		if d, ok := g.Dist.Between(start, cell); !ok || d > pac.Reach(DangerTurns) {
+			return 0
+		}
+		if danger >= 1 {
+			return -1
+		}
+		return int(danger * g.Params.DangerWeight)
+	}
+}
+
+// Steps added to the distance of a pellet pac reaches within the turns the
+// danger map looks ahead, for target scoring, by the danger on its cell
+func (g *Game) DangerAdjustment(pac *Pac, pallet *Pellet, dist int) int {
+	if pac.TurnsFor(dist) > DangerTurns {
+		return 0
+	}
+	return int(g.DangerTo(pac, grid.GetCell(pallet.X, pallet.Y, g.Grid)) * g.Params.DangerWeight)
+}
+
+// Steps added to the distance of a pellet pac reaches in dist steps, for
+// target scoring, when it lies in a dead-end branch whose mouth an opponent
+// pac in sight able to eat pac reaches before pac is back out: TrapWeight
+// per step of depth, as deeper pellets leave the pac longer to be shut in
+func (g *Game) TrapAdjustment(pac *Pac, pallet *Pellet, dist int) int {
+	cell := grid.GetCell(pallet.X, pallet.Y, g.Grid)
+	depth := g.DeadEnds.Depth(cell)
+	if depth == 0 {
+		return 0
+	}
+	mouth := g.DeadEnds.Mouth(cell)
+	out := pac.TurnsFor(dist + depth)
+	for _, enemy := range g.VisibleEnemies() {
+		if Matchup(enemy.TypeId, pac.TypeId) != 1 && !g.EnemyMayCounter(enemy, out) {
+			continue
+		}
+		if d, ok := g.Dist.Between(grid.GetCell(enemy.X, enemy.Y, g.Grid), mouth); ok && enemy.TurnsFor(d) <= out {
+			return int(float64(depth) * g.Params.TrapWeight)
+		}
+	}
+	return 0
+}
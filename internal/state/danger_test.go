@@ -0,0 +1,95 @@
+package state_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+)
+
+// Loop around a wall with an opponent pac on the short way to its far end
+var loop = []string{
+	"#########",
+	"#0  a   #",
+	"# ##### #",
+	"#       #",
+	"#########",
+}
+
+func TestDangerTo(t *testing.T) {
+	g := fixture.Game(loop...)
+	pac := fixture.Pac(g, 0)
+	enemy := g.OpponentPacs[0]
+	enemy.TypeId = "PAPER"
+	enemy.AbilityCooldown = 5
+	g.Danger = g.ComputeDanger()
+	tests := []struct {
+		x, y int
+		want float64
+	}{
+		{3, 1, 1},
+		{5, 1, 1},
+		{2, 1, 0.5},
+		{6, 1, 0.5},
+		{1, 1, 0},
+		{7, 3, 0},
+	}
+	for _, tt := range tests {
+		if got := g.DangerTo(pac, g.Grid[tt.y][tt.x]); got != tt.want {
+			t.Errorf("danger on (%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+	// the same type is only a danger once it can switch, a turn later
+	enemy.TypeId = pac.TypeId
+	enemy.AbilityCooldown = 0
+	g.Danger = g.ComputeDanger()
+	if got := g.DangerTo(pac, g.Grid[1][3]); got != 0.5 {
+		t.Errorf("danger next to an enemy able to switch = %v, want 0.5", got)
+	}
+	if got := g.DangerTo(pac, g.Grid[1][2]); got != 0 {
+		t.Errorf("danger two steps from an enemy able to switch = %v, want 0", got)
+	}
+}
+
+func TestPathForAvoidsDanger(t *testing.T) {
+	g := fixture.Game(loop...)
+	pac := fixture.Pac(g, 0)
+	// an enemy pac eats is neither a danger nor in the way
+	g.OpponentPacs[0].TypeId = "SCISSORS"
+	if path := g.PathFor(pac, 7, 1); len(path) != 7 {
+		t.Fatalf("path without danger has %d cells, want 7", len(path))
+	}
+	g.OpponentPacs[0].TypeId = "PAPER"
+	g.Danger = g.ComputeDanger()
+	path := g.PathFor(pac, 7, 1)
+	if len(path) != 11 || path[1] != g.Grid[2][1] {
+		t.Fatalf("got path of %d cells, want the 11 cells around the wall", len(path))
+	}
+}
+
+func TestTrapAdjustmentOfDeadEndPellet(t *testing.T) {
+	g := fixture.Game(
+		"#########",
+		"#       #",
+		"# ##### #",
+		"#0  a   #",
+		"###.#####",
+		"###.#####",
+		"###.#####",
+		"#########",
+	)
+	pac, enemy := fixture.Pac(g, 0), g.OpponentPacs[0]
+	pellet := g.Pellet.At(3, 6)
+	enemy.TypeId = "PAPER"
+	if got := g.TrapAdjustment(pac, pellet, 5); got != 3 {
+		t.Errorf("got %d, want 3 for a pellet 3 deep with paper at the mouth", got)
+	}
+	// a rock that cannot switch in time only blocks the way
+	enemy.TypeId, enemy.AbilityCooldown = "ROCK", 10
+	if got := g.TrapAdjustment(pac, pellet, 5); got != 0 {
+		t.Errorf("got %d against a rock on cooldown, want 0", got)
+	}
+	enemy.AbilityCooldown = 5
+	if got := g.TrapAdjustment(pac, pellet, 5); got != 3 {
+		t.Errorf("got %d against a rock able to switch, want 3", got)
+	}
+}
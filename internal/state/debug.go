@@ -0,0 +1,201 @@
+//go:build !submit
+
+// Debug subsystems compiled into local builds only, see debug_submit.go for
+// their stand-ins in the submission build.
+
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"spring2020/internal/logger"
+)
+
+// Pellet considered by target selection
+type Candidate struct {
+	Kind  string `json:"kind"`
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Value int    `json:"value"`
+	Dist  int    `json:"dist"`
+}
+
+// Decision of one pac in one turn
+type Decision struct {
+	Turn            int           `json:"turn"`
+	Pac             int           `json:"pac"`
+	X               int           `json:"x"`
+	Y               int           `json:"y"`
+	Type            string        `json:"type"`
+	SpeedTurnsLeft  int           `json:"speed_turns_left"`
+	AbilityCooldown int           `json:"ability_cooldown"`
+	MyScore         int           `json:"my_score"`
+	OpponentScore   int           `json:"opponent_score"`
+	TargetX         int           `json:"target_x"`
+	TargetY         int           `json:"target_y"`
+	ViaX            int           `json:"via_x"`
+	ViaY            int           `json:"via_y"`
+	Trigger         ReplanTrigger `json:"trigger,omitempty"`
+	Candidates      []Candidate   `json:"candidates,omitempty"`
+	Action          string        `json:"action"`
+	Micros          int64         `json:"micros"`
+}
+
+// Writer of one JSON line per pac decision
+type DecisionLog struct {
+	file *os.File
+	enc  *json.Encoder
+}
+
+// Create decision log writing to file name
+func NewDecisionLog(name string) (*DecisionLog, error) {
+	file, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &DecisionLog{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Write decision as a JSON line
+func (l *DecisionLog) Write(d *Decision) {
+	if err := l.enc.Encode(d); err != nil {
+		logger.Log("Decision log:", err)
+	}
+}
+
+// Start recording the decision of pac when the decision log is enabled
+func (g *Game) BeginDecision(pac *Pac) {
+	if g.DecisionLog == nil {
+		return
+	}
+	g.decision = &Decision{
+		Turn:            g.Turn,
+		Pac:             pac.Id,
+		X:               pac.X,
+		Y:               pac.Y,
+		Type:            pac.TypeId,
+		SpeedTurnsLeft:  pac.SpeedTurnsLeft,
+		AbilityCooldown: pac.AbilityCooldown,
+		MyScore:         g.MyScore,
+		OpponentScore:   g.OpponentScore,
+	}
+}
+
+// Record the replan trigger of the current decision
+func (g *Game) NoteTrigger(trigger ReplanTrigger) {
+	if g.decision != nil {
+		g.decision.Trigger = trigger
+	}
+}
+
+// Record a pellet considered for the current decision
+func (g *Game) NoteCandidate(kind string, pellet *Pellet, dist int) {
+	if g.decision != nil {
+		g.decision.Candidates = append(g.decision.Candidates, Candidate{kind, pellet.X, pellet.Y, pellet.Value, dist})
+	}
+}
+
+// Finish the current decision with the chosen command and write it out
+func (g *Game) EndDecision(action string, took time.Duration) {
+	if g.decision == nil {
+		return
+	}
+	// the pellet aimed at, and apart from it the cell steered through
+	g.decision.TargetX, g.decision.TargetY = -1, -1
+	g.decision.ViaX, g.decision.ViaY = -1, -1
+	for _, pac := range g.MyPacs {
+		if pac.Id != g.decision.Pac || pac.Plan == nil {
+			continue
+		}
+		g.decision.TargetX, g.decision.TargetY = pac.Plan.Target.X, pac.Plan.Target.Y
+		if via := pac.Plan.Via; via != nil {
+			g.decision.ViaX, g.decision.ViaY = via.X, via.Y
+		}
+	}
+	g.decision.Action = strings.TrimSuffix(action, "|")
+	g.decision.Micros = took.Microseconds()
+	g.DecisionLog.Write(g.decision)
+	g.decision = nil
+}
+
+// Failed invariant check
+type InvariantError string
+
+func (e InvariantError) Error() string {
+	return "invariant failed: " + string(e)
+}
+
+// Panic with an InvariantError unless cond holds
+func Check(cond bool, format string, a ...any) {
+	if !cond {
+		panic(InvariantError(fmt.Sprintf(format, a...)))
+	}
+}
+
+// Start a CPU profile written to cpu and have the returned stop write an
+// allocation profile to mem, each left out when its name is empty
+func StartProfiles(cpu, mem string) (stop func(), err error) {
+	var cpuFile *os.File
+	if cpu != "" {
+		if cpuFile, err = os.Create(cpu); err != nil {
+			return nil, err
+		}
+		if err = pprof.StartCPUProfile(cpuFile); err != nil {
+			cpuFile.Close()
+			return nil, err
+		}
+	}
+	return func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			cpuFile.Close()
+		}
+		if mem == "" {
+			return
+		}
+		file, err := os.Create(mem)
+		if err != nil {
+			logger.Info("Allocation profile:", err)
+			return
+		}
+		defer file.Close()
+		if err := pprof.Lookup("allocs").WriteTo(file, 0); err != nil {
+			logger.Info("Allocation profile:", err)
+		}
+	}, nil
+}
+
+// Per turn allocation and garbage collection report
+type MemReport struct {
+	last runtime.MemStats
+}
+
+// Create memory report with the current stats as baseline
+func NewMemReport() *MemReport {
+	m := &MemReport{}
+	runtime.ReadMemStats(&m.last)
+	return m
+}
+
+// Log allocations and heap growth since the last call, flagging turns in
+// which the garbage collector ran. Reading the stats stops the world, so
+// nothing is read while debug lines are not written.
+func (m *MemReport) Turn(turn int) {
+	if !logger.Enabled(logger.LevelDebug) {
+		return
+	}
+	var now runtime.MemStats
+	runtime.ReadMemStats(&now)
+	logger.Log("Mem turn", turn, "alloc", now.TotalAlloc-m.last.TotalAlloc, "bytes in", now.Mallocs-m.last.Mallocs,
+		"objects, heap", now.HeapAlloc, "growth", int64(now.HeapAlloc)-int64(m.last.HeapAlloc), "sys", now.Sys)
+	if gcs := now.NumGC - m.last.NumGC; gcs > 0 {
+		logger.Log("GC ran", gcs, "times in turn", turn, "pausing", time.Duration(now.PauseTotalNs-m.last.PauseTotalNs))
+	}
+	m.last = now
+}
@@ -0,0 +1,56 @@
+//go:build submit
+
+// Stand-ins for the debug subsystems of debug.go, compiled into the
+// submission so it stays small and spends no time on diagnostics.
+
+package state
+
+import (
+	"errors"
+	"time"
+)
+
+type Decision struct{}
+
+type DecisionLog struct{}
+
+func NewDecisionLog(name string) (*DecisionLog, error) {
+	return nil, errors.New("decision log is not compiled into submissions")
+}
+
+func (g *Game) BeginDecision(pac *Pac) {}
+
+func (g *Game) NoteTrigger(trigger ReplanTrigger) {}
+
+func (g *Game) NoteCandidate(kind string, pellet *Pellet, dist int) {}
+
+func (g *Game) EndDecision(action string, took time.Duration) {}
+
+type StateExport struct{}
+
+func NewStateExport(name string) (*StateExport, error) {
+	return nil, errors.New("state export is not compiled into submissions")
+}
+
+func (e *StateExport) Write(g *Game, commands string, took time.Duration) {}
+
+func StartProfiles(cpu, mem string) (stop func(), err error) {
+	if cpu != "" || mem != "" {
+		return nil, errors.New("profiling is not compiled into submissions")
+	}
+	return func() {}, nil
+}
+
+type MemReport struct{}
+
+func NewMemReport() *MemReport {
+	return &MemReport{}
+}
+
+func (m *MemReport) Turn(turn int) {}
+
+func Check(cond bool, format string, a ...any) {}
+
+func (g *Game) Render(color bool) string {
+	return ""
+}
@@ -0,0 +1,153 @@
+//go:build !submit
+
+package state
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"spring2020/internal/logger"
+)
+
+// Pellet the bot believes is on the map
+type PelletExport struct {
+	X       int  `json:"x"`
+	Y       int  `json:"y"`
+	Value   int  `json:"value"`
+	Visible bool `json:"visible"`
+}
+
+// Pac as the bot knows it, opponent pacs where last seen
+type PacExport struct {
+	Id              int     `json:"id"`
+	Mine            bool    `json:"mine"`
+	X               int     `json:"x"`
+	Y               int     `json:"y"`
+	Type            string  `json:"type"`
+	SpeedTurnsLeft  int     `json:"speed_turns_left"`
+	AbilityCooldown int     `json:"ability_cooldown"`
+	Seen            int     `json:"seen"`
+	Target          *[2]int `json:"target,omitempty"`
+	// Planned waypoints to the target
+	Path [][2]int `json:"path,omitempty"`
+}
+
+// Danger to a pac type on a cell
+type DangerExport struct {
+	X      int     `json:"x"`
+	Y      int     `json:"y"`
+	Danger float64 `json:"danger"`
+}
+
+// Game state of one turn with the commands chosen in it
+type TurnExport struct {
+	Turn          int `json:"turn"`
+	Width         int `json:"width"`
+	Height        int `json:"height"`
+	MyScore       int `json:"my_score"`
+	OpponentScore int `json:"opponent_score"`
+	// Maze rows, # for walls
+	Rows    []string       `json:"rows"`
+	Pellets []PelletExport `json:"pellets"`
+	Pacs    []PacExport    `json:"pacs"`
+	// Danger map of each type of my pacs
+	Danger   map[string][]DangerExport `json:"danger,omitempty"`
+	Commands string                    `json:"commands"`
+	Micros   int64                     `json:"micros"`
+}
+
+// Writer of one JSON line per turn with the full game state
+type StateExport struct {
+	enc *json.Encoder
+}
+
+// Create state export writing to file name, or to stderr when name is
+// "stderr"
+func NewStateExport(name string) (*StateExport, error) {
+	w := io.Writer(os.Stderr)
+	if name != "stderr" {
+		file, err := os.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		w = file
+	}
+	return &StateExport{enc: json.NewEncoder(w)}, nil
+}
+
+// State of g this turn with the commands it printed and the time it took
+func (g *Game) Export(commands string, took time.Duration) *TurnExport {
+	t := &TurnExport{
+		Turn:          g.Turn,
+		Width:         g.Width,
+		Height:        g.Height,
+		MyScore:       g.MyScore,
+		OpponentScore: g.OpponentScore,
+		Rows:          make([]string, g.Height),
+		Commands:      commands,
+		Micros:        took.Microseconds(),
+	}
+	for y, row := range g.Grid {
+		var b strings.Builder
+		for _, cell := range row {
+			if cell.IsWall {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		t.Rows[y] = b.String()
+	}
+	for _, pellet := range g.Pellet.Remaining(0) {
+		if pellet.Value > 0 {
+			visible := g.Visible.Has(pellet.X, pellet.Y)
+			t.Pellets = append(t.Pellets, PelletExport{pellet.X, pellet.Y, pellet.Value, visible})
+		}
+	}
+	for _, pacs := range [][]*Pac{g.MyPacs, g.OpponentPacs} {
+		for _, pac := range pacs {
+			p := PacExport{
+				Id:              pac.Id,
+				Mine:            pac.Mine,
+				X:               pac.X,
+				Y:               pac.Y,
+				Type:            pac.TypeId,
+				SpeedTurnsLeft:  pac.SpeedTurnsLeft,
+				AbilityCooldown: pac.AbilityCooldown,
+				Seen:            pac.Seen,
+			}
+			if pac.Plan != nil {
+				p.Target = &[2]int{pac.Plan.Target.X, pac.Plan.Target.Y}
+				for _, cell := range pac.Plan.Waypoints {
+					p.Path = append(p.Path, [2]int{cell.X, cell.Y})
+				}
+			}
+			t.Pacs = append(t.Pacs, p)
+		}
+	}
+	for typeId, danger := range g.Danger {
+		if t.Danger == nil {
+			t.Danger = make(map[string][]DangerExport)
+		}
+		cells := make([]DangerExport, 0, len(danger))
+		for cell, d := range danger {
+			cells = append(cells, DangerExport{cell.X, cell.Y, d})
+		}
+		sort.Slice(cells, func(a, b int) bool {
+			return cells[a].Y < cells[b].Y || (cells[a].Y == cells[b].Y && cells[a].X < cells[b].X)
+		})
+		t.Danger[typeId] = cells
+	}
+	return t
+}
+
+// Write the state of g this turn as a JSON line
+func (e *StateExport) Write(g *Game, commands string, took time.Duration) {
+	if err := e.enc.Encode(g.Export(commands, took)); err != nil {
+		logger.Log("State export:", err)
+	}
+}
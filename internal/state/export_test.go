@@ -0,0 +1,31 @@
+//go:build !submit
+
+package state_test
+
+import (
+	"testing"
+	"time"
+
+	"spring2020/internal/fixture"
+)
+
+func TestExport(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0 .oa#",
+		"#######",
+	)
+	g.UpdateVisibility()
+	pac := fixture.Pac(g, 0)
+	pac.Plan = g.NewPlan(pac, g.Pellet.At(4, 1))
+	got := g.Export("MOVE 0 4 1", 3*time.Millisecond)
+	if got.Rows[1] != "#     #" || got.Commands != "MOVE 0 4 1" || got.Micros != 3000 {
+		t.Errorf("got rows %q commands %q micros %d", got.Rows, got.Commands, got.Micros)
+	}
+	if len(got.Pellets) != 2 || !got.Pellets[0].Visible {
+		t.Errorf("got pellets %+v, want the two in sight", got.Pellets)
+	}
+	if len(got.Pacs) != 2 || got.Pacs[0].Target == nil || *got.Pacs[0].Target != [2]int{4, 1} || got.Pacs[1].Mine {
+		t.Errorf("got pacs %+v, want mine targeting (4, 1) then the opponent's", got.Pacs)
+	}
+}
@@ -0,0 +1,44 @@
+package state
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/parallel"
+	"spring2020/internal/pathfind"
+)
+
+// Flow fields of my pacs flooded from where they stand around their
+// Obstacles, weighted by the danger to each and the pellets on the way, so
+// every path a pac plans this turn is read off one flood.
+// The pacs are flooded in parallel, reusing the fields of the last turn.
+func (g *Game) ComputeFlows() map[int]*pathfind.FlowField {
+	fields := make([]*pathfind.FlowField, len(g.MyPacs))
+	for i, pac := range g.MyPacs {
+		if old := g.Flows[pac.Id]; old != nil && old.Fits(g.Grid) {
+			fields[i] = old
+		}
+	}
+	parallel.ForEach(len(g.MyPacs), func(i int) {
+		pac := g.MyPacs[i]
+		start := grid.GetCell(pac.X, pac.Y, g.Grid)
+		cost := pathfind.Avoiding(g.RouteCost(pac), g.Obstacles(pac))
+		if fields[i] != nil {
+			fields[i].Flood(start, cost)
+		} else {
+			fields[i] = pathfind.NewFlowField(start, g.Grid, cost)
+		}
+	})
+	flows := make(map[int]*pathfind.FlowField, len(g.MyPacs))
+	for i, pac := range g.MyPacs {
+		flows[pac.Id] = fields[i]
+	}
+	return flows
+}
+
+// Flow field of pac this turn, nil when none was flooded from where it stands
+func (g *Game) FlowOf(pac *Pac) *pathfind.FlowField {
+	flow := g.Flows[pac.Id]
+	if flow == nil || flow.Start() != grid.GetCell(pac.X, pac.Y, g.Grid) {
+		return nil
+	}
+	return flow
+}
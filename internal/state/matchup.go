@@ -0,0 +1,30 @@
+package state
+
+// Pac type each pac type beats
+var beats = map[string]string{
+	"ROCK":     "SCISSORS",
+	"SCISSORS": "PAPER",
+	"PAPER":    "ROCK",
+}
+
+// Outcome of pac types meeting: 1 when a eats b, -1 when b eats a, 0 on a
+// tie where both are blocked
+func Matchup(a, b string) int {
+	switch {
+	case beats[a] == b:
+		return 1
+	case beats[b] == a:
+		return -1
+	}
+	return 0
+}
+
+// Pac type beating t
+func Counter(t string) string {
+	for winner, loser := range beats {
+		if loser == t {
+			return winner
+		}
+	}
+	return t
+}
@@ -0,0 +1,102 @@
+package state
+
+import "spring2020/internal/grid"
+
+// Pellets indexed by cell for constant time lookup, keeping the order they
+// were first added in for iteration
+type PelletStore struct {
+	width  int
+	byCell []*Pellet
+	all    []*Pellet
+	// cells of the pellets not consumed
+	live grid.Bitboard
+}
+
+// Create an empty pellet store for a width by height map
+func NewPelletStore(width, height int) *PelletStore {
+	return &PelletStore{width: width, byCell: make([]*Pellet, width*height), live: grid.NewBitboard(width, height)}
+}
+
+// Pellet ever known on x, y, consumed or not; nil when there never was one
+func (s *PelletStore) At(x, y int) *Pellet {
+	return s.byCell[y*s.width+x]
+}
+
+// Add a pellet on x, y, or restore and revalue the one known there
+func (s *PelletStore) Add(x, y, value int) *Pellet {
+	if pellet := s.At(x, y); pellet != nil {
+		pellet.Value = value
+		pellet.Consumed, pellet.Doubtful = false, false
+		s.live.Set(x, y)
+		return pellet
+	}
+	pellet := &Pellet{X: x, Y: y, Value: value}
+	s.byCell[y*s.width+x] = pellet
+	s.live.Set(x, y)
+	s.all = append(s.all, pellet)
+	return pellet
+}
+
+// Put back a consumed pellet as saved before it was consumed, its flags
+// included
+func (s *PelletStore) restore(saved Pellet) {
+	if pellet := s.At(saved.X, saved.Y); pellet != nil {
+		*pellet = saved
+		if !saved.Consumed {
+			s.live.Set(saved.X, saved.Y)
+		}
+	}
+}
+
+// Mark the pellet on x, y consumed, returning it or nil when there is none
+func (s *PelletStore) Consume(x, y int) *Pellet {
+	pellet := s.At(x, y)
+	if pellet == nil || pellet.Consumed {
+		return nil
+	}
+	pellet.Consumed, pellet.Doubtful = true, false
+	s.live.Unset(x, y)
+	return pellet
+}
+
+// Check if a pellet not consumed is on x, y
+func (s *PelletStore) Has(x, y int) bool {
+	return s.live.Has(x, y)
+}
+
+// Number of pellets not consumed
+func (s *PelletStore) Count() int {
+	return s.live.Count()
+}
+
+// All pellets ever known, consumed ones included
+func (s *PelletStore) All() []*Pellet {
+	return s.all
+}
+
+// Pellets not consumed yet worth value, or worth anything when value is 0
+func (s *PelletStore) Remaining(value int) []*Pellet {
+	var pellets []*Pellet
+	for _, pellet := range s.all {
+		if !pellet.Consumed && (value == 0 || pellet.Value == value) {
+			pellets = append(pellets, pellet)
+		}
+	}
+	return pellets
+}
+
+// Pellets not consumed yet on cells, in their order
+func (s *PelletStore) Along(cells []*grid.Cell) []*Pellet {
+	var pellets []*Pellet
+	for _, cell := range cells {
+		if pellet := s.At(cell.X, cell.Y); pellet != nil && !pellet.Consumed {
+			pellets = append(pellets, pellet)
+		}
+	}
+	return pellets
+}
+
+// Pellets believed left inside corridor c, from its From junction to its To
+func (g *Game) CorridorPellets(c *grid.Corridor) []*Pellet {
+	return g.Pellet.Along(c.Cells)
+}
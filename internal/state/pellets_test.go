@@ -0,0 +1,217 @@
+package state_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/state"
+)
+
+func TestPelletStore(t *testing.T) {
+	s := state.NewPelletStore(5, 3)
+	if s.At(1, 1) != nil {
+		t.Fatal("empty store has a pellet")
+	}
+	a := s.Add(1, 1, 1)
+	s.Add(3, 1, 10)
+	if s.At(1, 1) != a {
+		t.Error("At does not find the added pellet")
+	}
+	if got := len(s.Remaining(0)); got != 2 {
+		t.Errorf("%d pellets remaining, want 2", got)
+	}
+	if got := len(s.Remaining(10)); got != 1 {
+		t.Errorf("%d super pellets remaining, want 1", got)
+	}
+	if s.Consume(1, 1) != a || !a.Consumed {
+		t.Error("Consume does not mark the pellet consumed")
+	}
+	if s.Consume(1, 1) != nil {
+		t.Error("pellet consumed twice")
+	}
+	if s.Consume(2, 1) != nil {
+		t.Error("consumed a pellet on an empty cell")
+	}
+	if got := len(s.Remaining(1)); got != 0 {
+		t.Errorf("%d regular pellets remaining, want 0", got)
+	}
+	if s.Has(1, 1) || !s.Has(3, 1) || s.Count() != 1 {
+		t.Errorf("live pellets %d, want only (3, 1)", s.Count())
+	}
+	// a pellet seen again is restored in place
+	if s.Add(1, 1, 1) != a || a.Consumed || !s.Has(1, 1) {
+		t.Error("Add does not restore the known pellet")
+	}
+	if got := len(s.All()); got != 2 {
+		t.Errorf("%d pellets known, want 2", got)
+	}
+}
+
+func TestRemovePallet(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0.. o#",
+		"#######",
+	)
+	pac := fixture.Pac(g, 0)
+	g.AddPellet(0, pac.X, pac.Y, 1)
+	g.RemovePallet(pac)
+	if !g.Pellet.At(pac.X, pac.Y).Consumed {
+		t.Error("pellet under the pac not consumed")
+	}
+	if got := len(g.Pellet.Remaining(0)); got != 3 {
+		t.Errorf("%d pellets remaining, want 3", got)
+	}
+}
+
+func TestCheckTargetEaten(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0.. o#",
+		"#######",
+	)
+	pac := fixture.Pac(g, 0)
+	target := g.Pellet.At(5, 1)
+	pac.Plan = g.NewPlan(pac, target)
+	if owner, ok := g.Reservations.Owner(target); !ok || owner != pac.Id {
+		t.Fatal("planned pellet not reserved for the pac")
+	}
+	if g.CheckTargetEaten(pac) {
+		t.Fatal("target reported eaten while it is there")
+	}
+	g.Pellet.Consume(5, 1)
+	if !g.CheckTargetEaten(pac) {
+		t.Fatal("eaten target not reported")
+	}
+	if pac.Plan != nil || g.Reservations.Reserved(target) {
+		t.Error("plan on an eaten target kept")
+	}
+	// the pac eating its target itself reached it
+	pac.Plan = g.NewPlan(pac, g.Pellet.At(2, 1))
+	pac.X = 2
+	g.RemovePallet(pac)
+	if g.CheckTargetEaten(pac) || pac.Plan == nil {
+		t.Error("target the pac ate itself reported eaten")
+	}
+}
+
+func TestSeedPellets(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0   a#",
+		"## # ##",
+		"#     #",
+		"#######",
+	)
+	// seeding comes before the pellets of the first turn input are read
+	g.SeedPellets()
+	g.AddPellet(0, 1, 3, 10)
+	g.MirrorSuperPellets()
+	for _, spawn := range [][2]int{{1, 1}, {5, 1}} {
+		if g.Pellet.At(spawn[0], spawn[1]) != nil {
+			t.Errorf("pellet seeded on spawn (%d, %d)", spawn[0], spawn[1])
+		}
+	}
+	if p := g.Pellet.At(2, 2); p == nil || p.Value != 1 {
+		t.Error("floor cell not seeded")
+	}
+	if p := g.Pellet.At(1, 2); p != nil {
+		t.Error("wall seeded")
+	}
+	if p := g.Pellet.At(5, 3); p == nil || p.Value != 10 {
+		t.Error("super pellet not mirrored")
+	}
+}
+
+func TestInferHiddenHarvest(t *testing.T) {
+	g := fixture.Game(
+		"#########",
+		"#0#..a..#",
+		"#########",
+	)
+	// the enemy was last seen a turn ago, two cells it may have reached
+	// since hold pellets
+	g.Turn = 3
+	g.OpponentPacs[0].Seen = 2
+	g.UpdateVisibility()
+	g.OpponentGain = 3
+	g.InferHiddenHarvest(0)
+	for _, x := range []int{4, 6} {
+		if !g.Pellet.At(x, 1).Consumed {
+			t.Errorf("pellet (%d, 1) next to the enemy not inferred eaten", x)
+		}
+	}
+	for _, x := range []int{3, 7} {
+		if g.Pellet.At(x, 1).Consumed {
+			t.Errorf("pellet (%d, 1) out of the enemy's reach inferred eaten", x)
+		}
+	}
+}
+
+func TestInferHiddenHarvestDoubtsLongGaps(t *testing.T) {
+	g := fixture.Game(
+		"#########",
+		"#0#..a..#",
+		"#########",
+	)
+	// out of sight for long, the enemy may have eaten any pellet
+	g.Turn = 30
+	g.OpponentPacs[0].Seen = 2
+	g.UpdateVisibility()
+	g.OpponentGain = 1
+	g.InferHiddenHarvest(0)
+	if got := len(g.Pellet.Remaining(0)); got != 4 {
+		t.Fatalf("%d pellets remaining, want all 4 kept", got)
+	}
+	var doubtful []*state.Pellet
+	for _, pellet := range g.Pellet.Remaining(0) {
+		if pellet.Doubtful {
+			doubtful = append(doubtful, pellet)
+		}
+	}
+	if len(doubtful) != 1 {
+		t.Fatalf("%d pellets doubtful, want the point's 1", len(doubtful))
+	}
+	if g.Pellet.Add(doubtful[0].X, doubtful[0].Y, 1).Doubtful {
+		t.Error("pellet seen again still doubtful")
+	}
+}
+
+func TestInferHiddenHarvestExplainedInSight(t *testing.T) {
+	g := fixture.Game(
+		"#########",
+		"#0#..a..#",
+		"#########",
+	)
+	g.Turn = 3
+	g.OpponentPacs[0].Seen = 2
+	g.UpdateVisibility()
+	// a pellet worth the opponent's point vanished in sight, my pacs scored none
+	g.OpponentGain = 1
+	g.InferHiddenHarvest(1)
+	if got := len(g.Pellet.Remaining(0)); got != 4 {
+		t.Errorf("%d pellets remaining, want 4", got)
+	}
+}
+
+func TestCorridorPellets(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0.. .#",
+		"###.###",
+		"#######",
+	)
+	junction := g.Corridors.JunctionAt(g.Grid[1][3])
+	if junction == nil {
+		t.Fatal("(3, 1) is not a junction")
+	}
+	for _, c := range junction.Corridors {
+		pellets := g.CorridorPellets(c)
+		if c.To.Cell.X > 3 && len(pellets) != 0 {
+			t.Errorf("corridor to the east has %d pellets inside, want 0", len(pellets))
+		}
+		if c.From.Cell.X < 3 && (len(pellets) != 1 || pellets[0].X != 2) {
+			t.Errorf("corridor from the west has pellets %v, want (2, 1)", pellets)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/pathfind"
+)
+
+// Waypoints ahead of a pac checked for pacs obstructing its route
+const ObstructionLookahead = 4
+
+// Check if pac target has been eaten by another pac and abandon the plan if
+// so. A pac standing on its target ate it itself and keeps the plan for the
+// replan check to find it reached.
+func (g *Game) CheckTargetEaten(pac *Pac) bool {
+	if pac.Plan == nil {
+		return false
+	}
+	logger.Trace("Checking target", pac.Plan.Target)
+	if pac.Plan.Target.Consumed && !pac.Plan.Reached(pac) {
+		logger.Log("Target eaten", pac.Plan.Target)
+		pac.Plan.Abandon(g, pac)
+		pac.Plan = nil
+		return true
+	}
+	return false
+}
+
+// Reason for a pac to run target selection this turn
+type ReplanTrigger string
+
+// Replan triggers
+const (
+	TriggerNone        ReplanTrigger = ""
+	TriggerNoPlan      ReplanTrigger = "no plan"
+	TriggerReached     ReplanTrigger = "target reached"
+	TriggerInvalidated ReplanTrigger = "target invalidated"
+	TriggerThreat      ReplanTrigger = "threat appeared"
+	TriggerBlocked     ReplanTrigger = "plan blocked"
+	TriggerBetter      ReplanTrigger = "better option"
+	TriggerConceded    ReplanTrigger = "race conceded"
+	TriggerElapsed     ReplanTrigger = "plan expired"
+)
+
+// Get the path from x, y to the target x, y as cells of the game grid,
+// routed on the corridor graph once it is built
+func (g *Game) PathTo(x, y, targetX, targetY int) []*grid.Cell {
+	if g.Corridors != nil {
+		return g.Corridors.Path(grid.GetCell(x, y, g.Grid), grid.GetCell(targetX, targetY, g.Grid))
+	}
+	return pathfind.AStar(x, y, targetX, targetY, g.Grid)
+}
+
+// Fractions a step is split into by RouteCost to discount pellet cells
+const routeUnit = 10
+
+// Cost of a step of pac in tenths of a step: DangerCost with the steps onto
+// cells holding a pellet no other pac reserved made cheaper by
+// PelletDiscount, so routes to distant targets sweep up pellets on the way
+func (g *Game) RouteCost(pac *Pac) pathfind.CostFunc {
+	danger := g.DangerCost(pac)
+	discount := grid.MinInt(int(g.Params.PelletDiscount*routeUnit+0.5), routeUnit-1)
+	if discount <= 0 {
+		return danger
+	}
+	return func(cell *grid.Cell) int {
+		extra := danger(cell)
+		if extra < 0 {
+			return -1
+		}
+		// the search adds the one step itself
+		cost := (1+extra)*routeUnit - 1
+		if g.Pellet.Has(cell.X, cell.Y) && !g.Reservations.TakenFrom(g.Pellet.At(cell.X, cell.Y), pac.Id) {
+			cost -= discount
+		}
+		return cost
+	}
+}
+
+// Cells other pacs stand on or step onto next within ObstructionLookahead
+// steps of pac, which its routes go around rather than bounce off: those of
+// my pacs that did not move last turn and the next waypoints of my pacs,
+// and those of the opponent pacs in sight pac cannot eat with the cells they
+// head on to. Nil when there are none.
+func (g *Game) Obstacles(pac *Pac) pathfind.Blocked {
+	start := grid.GetCell(pac.X, pac.Y, g.Grid)
+	blocked := make(map[*grid.Cell]bool)
+	add := func(cell *grid.Cell) {
+		if d, ok := g.Dist.Between(start, cell); ok && d > 0 && d <= ObstructionLookahead {
+			blocked[cell] = true
+		}
+	}
+	for _, other := range g.MyPacs {
+		if other == pac {
+			continue
+		}
+		if other.X == other.LastX && other.Y == other.LastY {
+			add(grid.GetCell(other.X, other.Y, g.Grid))
+		}
+		if other.Plan != nil && len(other.Plan.Waypoints) > 0 {
+			add(other.Plan.Waypoints[0])
+		}
+	}
+	for _, enemy := range g.VisibleEnemies() {
+		if Matchup(pac.TypeId, enemy.TypeId) == 1 {
+			continue
+		}
+		add(grid.GetCell(enemy.X, enemy.Y, g.Grid))
+		if next := g.enemyNext(enemy); next != nil {
+			add(next)
+		}
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+	return func(cell *grid.Cell) bool {
+		return blocked[cell]
+	}
+}
+
+// Get the path of pac to the target x, y around the cells in danger to it
+// and the Obstacles, through the pellets RouteCost favors. Falls back to the
+// way through the obstacles when they close every other, and to the
+// shortest path when danger does. The path is read off the flow field of
+// pac when one was flooded this turn.
+func (g *Game) PathFor(pac *Pac, targetX, targetY int) []*grid.Cell {
+	cost := g.RouteCost(pac)
+	if flow := g.FlowOf(pac); flow != nil {
+		if path := flow.PathTo(grid.GetCell(targetX, targetY, g.Grid)); path != nil {
+			return path
+		}
+		// the flood went around the obstacles
+		if path := pathfind.AStarWeighted(pac.X, pac.Y, targetX, targetY, g.Grid, cost); path != nil {
+			return path
+		}
+	} else if path := pathfind.AStarAround(pac.X, pac.Y, targetX, targetY, g.Grid, cost, g.Obstacles(pac)); path != nil {
+		return path
+	}
+	return g.PathTo(pac.X, pac.Y, targetX, targetY)
+}
+
+// Cell to steer through for the referee, walking a shortest path to it, to
+// follow path from its first cell: the farthest cell path reaches on a
+// shortest path, nil when the whole path is a shortest path
+func (g *Game) steerCell(path []*grid.Cell) *grid.Cell {
+	k := 0
+	for i := 1; i < len(path); i++ {
+		if d, ok := g.Dist.Between(path[0], path[i]); !ok || d != i {
+			break
+		}
+		k = i
+	}
+	if k == len(path)-1 {
+		return nil
+	}
+	return path[k]
+}
+
+// Plan of a pac: the waypoints still to walk, the last one holding the
+// target pellet, the pellets expected on the way and when the plan expires
+type Plan struct {
+	Target    *Pellet
+	Waypoints []*grid.Cell
+	Pellets   []*Pellet
+	Created   int
+	Expires   int
+	// Waypoint to steer through before heading to the target, set when the
+	// route differs from the shortest path the referee would walk
+	Via *grid.Cell
+}
+
+// Create a plan walking pac to pellet and reserve the pellet for it
+func (g *Game) NewPlan(pac *Pac, pellet *Pellet) *Plan {
+	plan := &Plan{Target: pellet, Created: g.Turn, Expires: g.Turn + g.Params.ReplanInterval}
+	plan.route(g, pac)
+	g.Reservations.Reserve(pellet, pac.Id)
+	return plan
+}
+
+// Set the waypoints from the pac's position to the target and collect the
+// pellets expected on the way
+func (p *Plan) route(g *Game, pac *Pac) bool {
+	path := g.PathFor(pac, p.Target.X, p.Target.Y)
+	if path == nil {
+		return false
+	}
+	p.follow(g, path)
+	p.Via = g.steerCell(path)
+	return true
+}
+
+// Set the waypoints to path, which starts at the pac, and collect the
+// pellets expected on the way
+func (p *Plan) follow(g *Game, path []*grid.Cell) {
+	p.Waypoints = path[1:]
+	p.Via = nil
+	p.Pellets = nil
+	for _, cell := range p.Waypoints {
+		if pellet := g.Pellet.At(cell.X, cell.Y); pellet != nil && !pellet.Consumed {
+			p.Pellets = append(p.Pellets, pellet)
+		}
+	}
+}
+
+func (p *Plan) String() string {
+	return fmt.Sprintf("to (%d, %d) in %d steps expecting %d pellets until turn %d",
+		p.Target.X, p.Target.Y, len(p.Waypoints), len(p.Pellets), p.Expires)
+}
+
+// Goal cell of the plan
+func (p *Plan) Goal() (int, int) {
+	if p.Via != nil {
+		return p.Via.X, p.Via.Y
+	}
+	return p.Target.X, p.Target.Y
+}
+
+// Cell pac moves to this turn: two waypoints ahead while it is sped up, so
+// both its steps follow the route rather than the referee's own shortest
+// path to a farther goal, the goal otherwise
+func (p *Plan) Next(pac *Pac) (int, int) {
+	if pac.SpeedTurnsLeft > 0 && len(p.Waypoints) >= 2 {
+		return p.Waypoints[1].X, p.Waypoints[1].Y
+	}
+	return p.Goal()
+}
+
+// Steer through the cell keeping the pac on its waypoints when the way to
+// the target is not a shortest path and no cell to steer through is set
+func (p *Plan) Aim(g *Game, pac *Pac) {
+	if p.Via == nil {
+		p.Via = g.steerCell(p.Path(g, pac))
+	}
+}
+
+// Check if pac stands on the goal
+func (p *Plan) Reached(pac *Pac) bool {
+	return pac.X == p.Target.X && pac.Y == p.Target.Y
+}
+
+// Check if the plan ran for its allotted turns
+func (p *Plan) Expired(turn int) bool {
+	return turn >= p.Expires
+}
+
+// Path from the pac's cell along the remaining waypoints
+func (p *Plan) Path(g *Game, pac *Pac) []*grid.Cell {
+	return append([]*grid.Cell{grid.GetCell(pac.X, pac.Y, g.Grid)}, p.Waypoints...)
+}
+
+// Advance the plan to the pac's position. Returns false when the pac left
+// the planned path and the plan needs a repair.
+func (p *Plan) Execute(pac *Pac) bool {
+	for i, cell := range p.Waypoints {
+		if cell.X == pac.X && cell.Y == pac.Y {
+			if p.Via != nil && !onRoute(p.Waypoints[i+1:], p.Via) {
+				p.Via = nil
+			}
+			p.Waypoints = p.Waypoints[i+1:]
+			return true
+		}
+	}
+	// still on the first waypoint's predecessor, or moved off the path
+	if len(p.Waypoints) == 0 {
+		return false
+	}
+	for _, neighbor := range p.Waypoints[0].Neighbors {
+		if neighbor.X == pac.X && neighbor.Y == pac.Y {
+			return true
+		}
+	}
+	return false
+}
+
+// Remaining waypoints from cell on, nil when cell is not one of them
+func (p *Plan) from(cell *grid.Cell) []*grid.Cell {
+	for i, waypoint := range p.Waypoints {
+		if waypoint == cell {
+			return p.Waypoints[i:]
+		}
+	}
+	return nil
+}
+
+// Check if cell is one of the waypoints
+func onRoute(waypoints []*grid.Cell, cell *grid.Cell) bool {
+	for _, waypoint := range waypoints {
+		if waypoint == cell {
+			return true
+		}
+	}
+	return false
+}
+
+// Route the plan around the cell blocking pac, see Unblock
+func (p *Plan) Reroute(g *Game, pac *Pac) bool {
+	if len(p.Waypoints) == 0 {
+		return false
+	}
+	return p.Unblock(g, pac, p.Waypoints[0])
+}
+
+// First of the next ObstructionLookahead waypoints a pac stands on that pac
+// can neither eat nor expect to move away: an opponent pac in sight or a pac
+// of mine that did not move last turn. Nil when the way is clear.
+func (p *Plan) Obstruction(g *Game, pac *Pac) *grid.Cell {
+	occupied := make(map[*grid.Cell]bool)
+	for _, other := range g.MyPacs {
+		if other != pac && other.X == other.LastX && other.Y == other.LastY {
+			occupied[grid.GetCell(other.X, other.Y, g.Grid)] = true
+		}
+	}
+	for _, enemy := range g.VisibleEnemies() {
+		if Matchup(pac.TypeId, enemy.TypeId) != 1 {
+			occupied[grid.GetCell(enemy.X, enemy.Y, g.Grid)] = true
+		}
+	}
+	for i, cell := range p.Waypoints {
+		if i == ObstructionLookahead {
+			break
+		}
+		if occupied[cell] {
+			return cell
+		}
+	}
+	return nil
+}
+
+// Route the plan around the obstructed cell of the route and the cells of the other pacs,
+// steering through the first cell off the blocked route. Returns false when
+// there is no detour within LoopMargin extra steps.
+func (p *Plan) Unblock(g *Game, pac *Pac, obstructed *grid.Cell) bool {
+	blocked := map[*grid.Cell]bool{obstructed: true}
+	for _, pacs := range [][]*Pac{g.MyPacs, g.VisibleEnemies()} {
+		for _, other := range pacs {
+			if other != pac {
+				blocked[grid.GetCell(other.X, other.Y, g.Grid)] = true
+			}
+		}
+	}
+	target := grid.GetCell(p.Target.X, p.Target.Y, g.Grid)
+	path := grid.BfsFind(grid.GetCell(pac.X, pac.Y, g.Grid), func(c *grid.Cell) bool {
+		return !blocked[c]
+	}, func(c *grid.Cell) bool {
+		return c == target
+	})
+	if path == nil || len(path) > len(p.Waypoints)+1+g.Params.LoopMargin {
+		return false
+	}
+	via := path[len(path)-1]
+	for _, cell := range path[1:] {
+		if !onRoute(p.Waypoints, cell) {
+			via = cell
+			break
+		}
+	}
+	p.follow(g, path)
+	p.Via = via
+	return true
+}
+
+// Route the plan along route, which starts at the pac, and on to the target,
+// steering through via. Returns false when the target cannot be reached from
+// the end of the route.
+func (p *Plan) SteerThrough(g *Game, route []*grid.Cell, via *grid.Cell) bool {
+	end := route[len(route)-1]
+	rest := p.from(end)
+	if rest == nil {
+		rest = g.PathTo(end.X, end.Y, p.Target.X, p.Target.Y)
+	}
+	if rest == nil {
+		return false
+	}
+	path := append(append([]*grid.Cell{}, route...), rest[1:]...)
+	p.follow(g, path)
+	p.Via = via
+	return true
+}
+
+// Route the plan again from the pac's position. Returns false when the
+// target can no longer be reached.
+func (p *Plan) Repair(g *Game, pac *Pac) bool {
+	logger.Log("Pac", pac.Id, "repairs plan to", p.Target.X, p.Target.Y)
+	return p.route(g, pac)
+}
+
+// Give up the plan of pac, releasing the target for other pacs unless
+// another plan of pac reserved it since
+func (p *Plan) Abandon(g *Game, pac *Pac) {
+	g.Reservations.Release(p.Target, pac.Id)
+}
+
+// Plan summary for state dumps, cells link their neighbors and cannot be
+// serialized themselves
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		TargetX, TargetY int
+		Waypoints        int
+		Pellets          int
+		Created, Expires int
+	}{p.Target.X, p.Target.Y, len(p.Waypoints), len(p.Pellets), p.Created, p.Expires})
+}
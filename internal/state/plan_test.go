@@ -0,0 +1,121 @@
+package state_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+)
+
+func TestPlanNextWhileSped(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0    #",
+		"#  ## #",
+		"#    .#",
+		"#######",
+	)
+	pac := fixture.Pac(g, 0)
+	plan := g.NewPlan(pac, g.Pellet.At(5, 3))
+	if x, y := plan.Next(pac); x != 5 || y != 3 {
+		t.Errorf("got (%d, %d), want the target", x, y)
+	}
+	// a sped pac is sent along its route two cells at a time
+	pac.SpeedTurnsLeft = 3
+	x, y := plan.Next(pac)
+	if want := plan.Waypoints[1]; x != want.X || y != want.Y {
+		t.Errorf("got (%d, %d), want the second waypoint (%d, %d)", x, y, want.X, want.Y)
+	}
+	plan.Waypoints = plan.Waypoints[len(plan.Waypoints)-1:]
+	if x, y := plan.Next(pac); x != 5 || y != 3 {
+		t.Errorf("got (%d, %d) a step before the target, want the target", x, y)
+	}
+}
+
+func TestPlanRoutesAroundObstruction(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0 1 .#",
+		"# ### #",
+		"#     #",
+		"#######",
+	)
+	pac := fixture.Pac(g, 0)
+	other := fixture.Pac(g, 1)
+	// planned while the other pac was moving, the plan takes the short way
+	other.LastX = 2
+	plan := g.NewPlan(pac, g.Pellet.At(5, 1))
+	if len(plan.Waypoints) != 4 {
+		t.Fatalf("plan has %d waypoints, want 4", len(plan.Waypoints))
+	}
+	// the pac of mine standing still on the short way blocks it
+	other.LastX = 3
+	cell := plan.Obstruction(g, pac)
+	if cell == nil || cell.X != 3 || cell.Y != 1 {
+		t.Fatalf("got obstruction %v, want (3, 1)", cell)
+	}
+	if !plan.Unblock(g, pac, cell) {
+		t.Fatal("no way around the obstruction")
+	}
+	if len(plan.Waypoints) != 8 || plan.Obstruction(g, pac) != nil {
+		t.Errorf("got %d waypoints, want the 8 around the wall", len(plan.Waypoints))
+	}
+	// a pac that moved last turn is expected to clear the way
+	other.LastX = 2
+	plan = g.NewPlan(pac, g.Pellet.At(5, 1))
+	if cell := plan.Obstruction(g, pac); cell != nil {
+		t.Errorf("got obstruction (%d, %d) by a moving pac", cell.X, cell.Y)
+	}
+}
+
+func TestPathForAvoidsObstacles(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0 1 .#",
+		"# ### #",
+		"#     #",
+		"#######",
+	)
+	pac := fixture.Pac(g, 0)
+	// the pac of mine standing still on the short way is routed around
+	if path := g.PathFor(pac, 5, 1); len(path) != 9 || path[1] != g.Grid[2][1] {
+		t.Fatalf("got path of %d cells, want the 9 cells around the wall", len(path))
+	}
+	g.Flows = g.ComputeFlows()
+	if path := g.PathFor(pac, 5, 1); len(path) != 9 {
+		t.Errorf("got flow path of %d cells, want 9", len(path))
+	}
+	// with the way around walled off the pac walks through it
+	g = fixture.Game(
+		"#######",
+		"#0 1 .#",
+		"#######",
+	)
+	pac = fixture.Pac(g, 0)
+	if path := g.PathFor(pac, 5, 1); len(path) != 5 {
+		t.Errorf("got path of %d cells, want the 5 through the pac", len(path))
+	}
+	g.Flows = g.ComputeFlows()
+	if path := g.PathFor(pac, 5, 1); len(path) != 5 {
+		t.Errorf("got flow path of %d cells, want 5", len(path))
+	}
+}
+
+func TestPlanSweepsPelletsOnTheWay(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0....#",
+		"# ### #",
+		"#    .#",
+		"#######",
+	)
+	pac := fixture.Pac(g, 0)
+	// both ways to the target are six steps, the one over the wall eats
+	// every pellet on the way
+	plan := g.NewPlan(pac, g.Pellet.At(5, 3))
+	if first := plan.Waypoints[0]; first.X != 2 || first.Y != 1 {
+		t.Errorf("plan starts to (%d, %d), want along the pellet row", first.X, first.Y)
+	}
+	if len(plan.Waypoints) != 6 || len(plan.Pellets) != 5 {
+		t.Errorf("got %d waypoints expecting %d pellets, want 6 and 5", len(plan.Waypoints), len(plan.Pellets))
+	}
+}
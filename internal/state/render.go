@@ -0,0 +1,96 @@
+//go:build !submit
+
+package state
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ANSI colors of the pac types
+var typeColors = map[string]string{
+	"ROCK":     "31",
+	"PAPER":    "36",
+	"SCISSORS": "35",
+	"DEAD":     "90",
+}
+
+// ANSI escape codes of the maze drawing
+const (
+	ansiReset  = "\x1b[0m"
+	ansiDim    = "\x1b[2m"
+	ansiSuper  = "\x1b[1;33m"
+	ansiTarget = "\x1b[1;32m"
+)
+
+// Character and color of one cell of the drawing
+type glyph struct {
+	char  byte
+	color string
+}
+
+// Draw the maze as known now, one character per cell: walls #, pellets .,
+// doubtful pellets ?, super pellets o, the targets of my pacs *, my pacs by
+// id and opponent pacs where last seen by letter, a for id 0. A legend lists
+// each pac below. With color, pacs take the color of their type, opponent
+// pacs reversed, and the cells out of sight are dimmed.
+func (g *Game) Render(color bool) string {
+	cells := make([][]glyph, g.Height)
+	for y := range cells {
+		cells[y] = make([]glyph, g.Width)
+		for x := range cells[y] {
+			cell := g.Grid[y][x]
+			switch pellet := g.Pellet.At(x, y); {
+			case cell.IsWall:
+				cells[y][x] = glyph{'#', ansiDim}
+			case pellet != nil && !pellet.Consumed && pellet.Value > 1:
+				cells[y][x] = glyph{'o', ansiSuper}
+			case pellet != nil && !pellet.Consumed && pellet.Doubtful:
+				cells[y][x] = glyph{'?', ""}
+			case pellet != nil && !pellet.Consumed && pellet.Value == 1:
+				cells[y][x] = glyph{'.', ""}
+			default:
+				cells[y][x] = glyph{' ', ""}
+			}
+			if !cell.IsWall && !g.Visible.Has(cell.X, cell.Y) && cells[y][x].color == "" {
+				cells[y][x].color = ansiDim
+			}
+		}
+	}
+	for _, pac := range g.MyPacs {
+		if pac.Plan != nil {
+			cells[pac.Plan.Target.Y][pac.Plan.Target.X] = glyph{'*', ansiTarget}
+		}
+	}
+	for _, enemy := range g.OpponentPacs {
+		cells[enemy.Y][enemy.X] = glyph{byte('a' + enemy.Id), "\x1b[7;" + typeColors[enemy.TypeId] + "m"}
+	}
+	for _, pac := range g.MyPacs {
+		cells[pac.Y][pac.X] = glyph{byte('0' + pac.Id), "\x1b[1;" + typeColors[pac.TypeId] + "m"}
+	}
+
+	var b strings.Builder
+	for _, row := range cells {
+		for _, c := range row {
+			if color && c.color != "" {
+				b.WriteString(c.color)
+				b.WriteByte(c.char)
+				b.WriteString(ansiReset)
+			} else {
+				b.WriteByte(c.char)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	for _, pac := range g.MyPacs {
+		fmt.Fprintf(&b, "%c %-8s (%d, %d) speed %d cooldown %d", '0'+pac.Id, pac.TypeId, pac.X, pac.Y, pac.SpeedTurnsLeft, pac.AbilityCooldown)
+		if pac.Plan != nil {
+			fmt.Fprintf(&b, " target (%d, %d)", pac.Plan.Target.X, pac.Plan.Target.Y)
+		}
+		b.WriteByte('\n')
+	}
+	for _, enemy := range g.OpponentPacs {
+		fmt.Fprintf(&b, "%c %-8s (%d, %d) seen turn %d\n", 'a'+enemy.Id, enemy.TypeId, enemy.X, enemy.Y, enemy.Seen)
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+//go:build !submit
+
+package state_test
+
+import (
+	"strings"
+	"testing"
+
+	"spring2020/internal/fixture"
+)
+
+func TestRender(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0 .oa#",
+		"#######",
+	)
+	g.UpdateVisibility()
+	pac := fixture.Pac(g, 0)
+	pac.Plan = g.NewPlan(pac, g.Pellet.At(3, 1))
+	got := g.Render(false)
+	want := "#######\n#0 *oa#\n#######\n"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("got\n%s\nwant the maze\n%s", got, want)
+	}
+	if !strings.Contains(got, "0 ROCK     (1, 1) speed 0 cooldown 0 target (3, 1)\n") {
+		t.Errorf("legend of pac 0 missing from\n%s", got)
+	}
+}
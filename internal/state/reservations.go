@@ -0,0 +1,85 @@
+package state
+
+// Pellets my pacs are heading for, each reserved by one pac and every pac
+// holding at most one reservation. The zero value is empty and ready.
+type Reservations struct {
+	byPellet map[*Pellet]int
+	byPac    map[int]*Pellet
+}
+
+// Reserve pellet for the pac of id, releasing what it held before
+func (r *Reservations) Reserve(pellet *Pellet, pacId int) {
+	if r.byPellet == nil {
+		r.byPellet = make(map[*Pellet]int)
+		r.byPac = make(map[int]*Pellet)
+	}
+	if old := r.byPac[pacId]; old != nil {
+		delete(r.byPellet, old)
+	}
+	if owner, ok := r.byPellet[pellet]; ok {
+		delete(r.byPac, owner)
+	}
+	r.byPellet[pellet] = pacId
+	r.byPac[pacId] = pellet
+}
+
+// Release pellet if the pac of id holds it, leaving it to a pac that took
+// it over since
+func (r *Reservations) Release(pellet *Pellet, pacId int) {
+	if owner, ok := r.byPellet[pellet]; ok && owner == pacId {
+		delete(r.byPellet, pellet)
+		delete(r.byPac, pacId)
+	}
+}
+
+// Release whatever the pac of id holds
+func (r *Reservations) ReleasePac(pacId int) {
+	if pellet := r.byPac[pacId]; pellet != nil {
+		r.Release(pellet, pacId)
+	}
+}
+
+// Check if any pac reserved pellet
+func (r *Reservations) Reserved(pellet *Pellet) bool {
+	_, ok := r.byPellet[pellet]
+	return ok
+}
+
+// Check if a pac other than the one of id reserved pellet
+func (r *Reservations) TakenFrom(pellet *Pellet, pacId int) bool {
+	owner, ok := r.byPellet[pellet]
+	return ok && owner != pacId
+}
+
+// Pac holding pellet, false when it is free
+func (r *Reservations) Owner(pellet *Pellet) (int, bool) {
+	owner, ok := r.byPellet[pellet]
+	return owner, ok
+}
+
+// Pellet the pac of id holds, nil when none
+func (r *Reservations) Of(pacId int) *Pellet {
+	return r.byPac[pacId]
+}
+
+// Number of pellets reserved
+func (r *Reservations) Len() int {
+	return len(r.byPellet)
+}
+
+// Release the reservations of pacs no longer alive and of pellets already
+// consumed, returning how many were dropped
+func (g *Game) PruneReservations() int {
+	alive := make(map[int]bool, len(g.MyPacs))
+	for _, pac := range g.MyPacs {
+		alive[pac.Id] = true
+	}
+	dropped := 0
+	for pellet, owner := range g.Reservations.byPellet {
+		if pellet.Consumed || !alive[owner] {
+			g.Reservations.Release(pellet, owner)
+			dropped++
+		}
+	}
+	return dropped
+}
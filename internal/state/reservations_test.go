@@ -0,0 +1,57 @@
+package state_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/state"
+)
+
+func TestReservations(t *testing.T) {
+	var r state.Reservations
+	a, b := &state.Pellet{X: 1, Y: 1, Value: 1}, &state.Pellet{X: 2, Y: 1, Value: 1}
+	if r.Reserved(a) || r.Of(0) != nil || r.Len() != 0 {
+		t.Fatal("zero reservations not empty")
+	}
+	r.Reserve(a, 0)
+	if owner, ok := r.Owner(a); !ok || owner != 0 || r.Of(0) != a {
+		t.Fatal("reservation not recorded")
+	}
+	if r.TakenFrom(a, 0) || !r.TakenFrom(a, 1) || r.TakenFrom(b, 1) {
+		t.Error("TakenFrom wrong about the owner")
+	}
+	// retargeting releases the old pellet
+	r.Reserve(b, 0)
+	if r.Reserved(a) || r.Of(0) != b || r.Len() != 1 {
+		t.Error("old reservation kept after retargeting")
+	}
+	// another pac taking the pellet over is not undone by the first releasing it
+	r.Reserve(b, 1)
+	r.Release(b, 0)
+	if owner, _ := r.Owner(b); owner != 1 || r.Of(0) != nil {
+		t.Error("release by a former owner dropped the new one")
+	}
+	r.ReleasePac(1)
+	if r.Len() != 0 {
+		t.Errorf("%d reservations left, want 0", r.Len())
+	}
+}
+
+func TestPruneReservations(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0. .1#",
+		"#######",
+	)
+	eaten, kept := g.Pellet.At(2, 1), g.Pellet.At(4, 1)
+	g.Reservations.Reserve(eaten, 0)
+	g.Reservations.Reserve(kept, 1)
+	g.Reservations.Reserve(&state.Pellet{X: 3, Y: 1, Value: 1}, 7)
+	g.Pellet.Consume(2, 1)
+	if dropped := g.PruneReservations(); dropped != 2 {
+		t.Errorf("dropped %d reservations, want the eaten pellet and the missing pac", dropped)
+	}
+	if !g.Reservations.Reserved(kept) || g.Reservations.Len() != 1 {
+		t.Error("live reservation dropped")
+	}
+}
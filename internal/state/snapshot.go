@@ -0,0 +1,253 @@
+package state
+
+import "spring2020/internal/grid"
+
+// Copy of the pellet store whose pellets change independently of s
+func (s *PelletStore) Clone() *PelletStore {
+	c := &PelletStore{width: s.width, byCell: make([]*Pellet, len(s.byCell)), all: make([]*Pellet, len(s.all)), live: s.live.Clone()}
+	for i, pellet := range s.all {
+		copied := *pellet
+		c.all[i] = &copied
+		c.byCell[pellet.Y*s.width+pellet.X] = &copied
+	}
+	return c
+}
+
+// Copy of the plan pointing into the pellets of store
+func (p *Plan) clone(store *PelletStore) *Plan {
+	c := *p
+	if p.Target != nil {
+		c.Target = store.At(p.Target.X, p.Target.Y)
+	}
+	c.Waypoints = append([]*grid.Cell(nil), p.Waypoints...)
+	c.Pellets = make([]*Pellet, len(p.Pellets))
+	for i, pellet := range p.Pellets {
+		c.Pellets[i] = store.At(pellet.X, pellet.Y)
+	}
+	return &c
+}
+
+// Copy of the reservations pointing into the pellets of store
+func (r *Reservations) clone(store *PelletStore) Reservations {
+	var c Reservations
+	for pellet, pacId := range r.byPellet {
+		c.Reserve(store.At(pellet.X, pellet.Y), pacId)
+	}
+	return c
+}
+
+// Copies of pacs with their plans pointing into the pellets of store
+func clonePacs(pacs []*Pac, store *PelletStore) []*Pac {
+	c := make([]*Pac, len(pacs))
+	for i, pac := range pacs {
+		copied := *pac
+		if pac.Plan != nil {
+			copied.Plan = pac.Plan.clone(store)
+		}
+		c[i] = &copied
+	}
+	return c
+}
+
+// Deep copy of the game a search can play turns on without touching g: its
+// own pacs, plans, pellets, reservations and sight. The maze, distances and
+// the maps recomputed every turn are shared as nothing changes them in
+// place; flow fields are left out since they are reflooded in place, and
+// the copy logs no decisions.
+func (g *Game) Clone() *Game {
+	c := *g
+	c.Pellet = g.Pellet.Clone()
+	c.MyPacs = clonePacs(g.MyPacs, c.Pellet)
+	c.OpponentPacs = clonePacs(g.OpponentPacs, c.Pellet)
+	c.Visible = g.Visible.Clone()
+	c.LastSeen = make(map[*grid.Cell]int, len(g.LastSeen))
+	for cell, turn := range g.LastSeen {
+		c.LastSeen[cell] = turn
+	}
+	c.Reservations = g.Reservations.clone(c.Pellet)
+	c.Flows = nil
+	c.DecisionLog, c.decision = nil, nil
+	return &c
+}
+
+// Order of one pac in a simulated turn: walk towards Target, or use SPEED,
+// or SWITCH to Switch
+type Action struct {
+	Pac    *Pac
+	Target *grid.Cell
+	Speed  bool
+	Switch string
+}
+
+// What Apply changed, for Undo to take back
+type Undo struct {
+	turn, myScore, opponentScore int
+	pacs                         []*Pac
+	saved                        []Pac
+	// pellets eaten as they were before, flags included
+	eaten []Pellet
+}
+
+// Cell next to cell on a shortest path to target, trying up, right, down
+// and left in the order the referee does; cell itself once on target or
+// when target cannot be reached
+func (g *Game) StepToward(cell, target *grid.Cell) *grid.Cell {
+	d, ok := g.Dist.Between(cell, target)
+	if !ok || d == 0 {
+		return cell
+	}
+	for _, dir := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		y := cell.Y + dir[1]
+		if y < 0 || y >= g.Height {
+			continue
+		}
+		next := g.Grid[y][(cell.X+dir[0]+g.Width)%g.Width]
+		if n, ok := g.Dist.Between(next, target); ok && !next.IsWall && n == d-1 {
+			return next
+		}
+	}
+	return cell
+}
+
+// Play one turn of actions the way the referee does: abilities first, then
+// a step for every walking pac and a second one for the sped up ones. Pacs
+// landing on the same cell or crossing each other go back when they are
+// of one player or one type, until no such meeting is left, then the
+// losers of the other meetings die; pellets go to the pacs entering their
+// cells. Pacs without an action hold. Returns the change to Undo.
+func (g *Game) Apply(actions []Action) *Undo {
+	u := &Undo{turn: g.Turn, myScore: g.MyScore, opponentScore: g.OpponentScore}
+	for _, pacs := range [][]*Pac{g.MyPacs, g.OpponentPacs} {
+		for _, pac := range pacs {
+			u.pacs = append(u.pacs, pac)
+			u.saved = append(u.saved, *pac)
+		}
+	}
+	targets := make(map[*Pac]*grid.Cell, len(actions))
+	for _, a := range actions {
+		switch {
+		case a.Pac.TypeId == DeadType:
+		case a.Speed || a.Switch != "":
+			if a.Pac.AbilityCooldown > 0 {
+				continue
+			}
+			if a.Speed {
+				a.Pac.SpeedTurnsLeft = SpeedDuration
+			} else {
+				a.Pac.TypeId = a.Switch
+			}
+			a.Pac.AbilityCooldown = AbilityCooldown
+		case a.Target != nil:
+			targets[a.Pac] = a.Target
+		}
+	}
+	for step := 0; step < 2; step++ {
+		from := make(map[*Pac]*grid.Cell, len(targets))
+		for pac, target := range targets {
+			if step == 1 && pac.SpeedTurnsLeft == 0 {
+				continue
+			}
+			cell := g.Grid[pac.Y][pac.X]
+			from[pac] = cell
+			next := g.StepToward(cell, target)
+			pac.X, pac.Y = next.X, next.Y
+		}
+		g.resolveMeetings(u.pacs, from)
+		// both players score a pellet their pacs enter in the same step
+		eaters := make(map[*Pellet][2]bool)
+		for _, pac := range u.pacs {
+			if pellet := g.Pellet.At(pac.X, pac.Y); pellet != nil && !pellet.Consumed && pac.TypeId != DeadType {
+				players := eaters[pellet]
+				if pac.Mine {
+					players[0] = true
+				} else {
+					players[1] = true
+				}
+				eaters[pellet] = players
+			}
+		}
+		for pellet, players := range eaters {
+			u.eaten = append(u.eaten, *pellet)
+			g.Pellet.Consume(pellet.X, pellet.Y)
+			if players[0] {
+				g.MyScore += pellet.Value
+			}
+			if players[1] {
+				g.OpponentScore += pellet.Value
+			}
+		}
+	}
+	for _, pac := range u.pacs {
+		if pac.SpeedTurnsLeft > 0 {
+			pac.SpeedTurnsLeft--
+		}
+		if pac.AbilityCooldown > 0 {
+			pac.AbilityCooldown--
+		}
+	}
+	g.Turn++
+	return u
+}
+
+// Send back the pacs that moved onto the same cell or through a pac of
+// their own or of their type, until none is left as a pac sent back may
+// meet another, then kill the pacs that met an opponent pac beating them
+func (g *Game) resolveMeetings(pacs []*Pac, from map[*Pac]*grid.Cell) {
+	cellOf := func(pac *Pac) *grid.Cell {
+		if cell, ok := from[pac]; ok {
+			return cell
+		}
+		return g.Grid[pac.Y][pac.X]
+	}
+	met := func(a, b *Pac) bool {
+		if a.TypeId == DeadType || b.TypeId == DeadType {
+			return false
+		}
+		same := a.X == b.X && a.Y == b.Y
+		return same || (cellOf(a) == g.Grid[b.Y][b.X] && cellOf(b) == g.Grid[a.Y][a.X])
+	}
+	for changed := true; changed; {
+		changed = false
+		for i, a := range pacs {
+			for _, b := range pacs[i+1:] {
+				if !met(a, b) || (a.Mine != b.Mine && Matchup(a.TypeId, b.TypeId) != 0) {
+					continue
+				}
+				for _, pac := range []*Pac{a, b} {
+					if back := cellOf(pac); back != g.Grid[pac.Y][pac.X] {
+						pac.X, pac.Y = back.X, back.Y
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	var eaten []*Pac
+	for i, a := range pacs {
+		for _, b := range pacs[i+1:] {
+			if a.Mine == b.Mine || !met(a, b) {
+				continue
+			}
+			switch Matchup(a.TypeId, b.TypeId) {
+			case 1:
+				eaten = append(eaten, b)
+			case -1:
+				eaten = append(eaten, a)
+			}
+		}
+	}
+	for _, pac := range eaten {
+		pac.TypeId = DeadType
+	}
+}
+
+// Take back the turn u was returned for, which must be the last one applied
+func (g *Game) Undo(u *Undo) {
+	for i, pac := range u.pacs {
+		*pac = u.saved[i]
+	}
+	for _, pellet := range u.eaten {
+		g.Pellet.restore(pellet)
+	}
+	g.Turn, g.MyScore, g.OpponentScore = u.turn, u.myScore, u.opponentScore
+}
@@ -0,0 +1,108 @@
+package state_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/state"
+)
+
+func TestCloneIsIndependent(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0.. a#",
+		"#######",
+	)
+	pac := fixture.Pac(g, 0)
+	pac.Plan = g.NewPlan(pac, g.Pellet.At(3, 1))
+	c := g.Clone()
+	mine := c.MyPacs[0]
+	if mine == pac || mine.Plan == pac.Plan || mine.Plan.Target != c.Pellet.At(3, 1) {
+		t.Fatal("clone shares the pac or plan of the original")
+	}
+	if owner, ok := c.Reservations.Owner(c.Pellet.At(3, 1)); !ok || owner != pac.Id {
+		t.Error("clone lost the reservation of the plan target")
+	}
+	mine.X = 2
+	c.Pellet.Consume(2, 1)
+	c.OpponentPacs[0].TypeId = "DEAD"
+	if pac.X != 1 || !g.Pellet.Has(2, 1) || g.Pellet.At(2, 1).Consumed || g.OpponentPacs[0].TypeId == "DEAD" {
+		t.Error("changing the clone changed the original")
+	}
+	if c.Pellet.Has(2, 1) || c.Pellet.Count() != 1 {
+		t.Errorf("clone has %d pellets, want only (3, 1)", c.Pellet.Count())
+	}
+}
+
+func TestApplyUndo(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0...a#",
+		"#######",
+	)
+	pac, enemy := fixture.Pac(g, 0), g.OpponentPacs[0]
+	enemy.TypeId = "SCISSORS"
+	u := g.Apply([]state.Action{{Pac: pac, Target: g.Grid[1][4]}, {Pac: enemy, Speed: true}})
+	if pac.X != 2 || g.MyScore != 1 || g.Pellet.Has(2, 1) {
+		t.Errorf("pac on (%d, %d) scoring %d, want it to eat (2, 1)", pac.X, pac.Y, g.MyScore)
+	}
+	if enemy.SpeedTurnsLeft != state.SpeedDuration-1 || enemy.AbilityCooldown != state.AbilityCooldown-1 {
+		t.Errorf("enemy speed %d cooldown %d after SPEED", enemy.SpeedTurnsLeft, enemy.AbilityCooldown)
+	}
+	// the sped up scissors eats (4, 1) and walks on onto the rock
+	v := g.Apply([]state.Action{{Pac: pac, Target: g.Grid[1][4]}, {Pac: enemy, Target: g.Grid[1][1]}})
+	if pac.X != 3 || enemy.TypeId != "DEAD" || g.MyScore != 2 || g.OpponentScore != 1 || g.Turn != 3 {
+		t.Errorf("pac on (%d, %d), enemy %s, scores %d-%d on turn %d", pac.X, pac.Y, enemy.TypeId, g.MyScore, g.OpponentScore, g.Turn)
+	}
+	g.Undo(v)
+	g.Undo(u)
+	if pac.X != 1 || enemy.X != 5 || enemy.TypeId != "SCISSORS" || enemy.AbilityCooldown != 0 {
+		t.Error("Undo did not restore the pacs")
+	}
+	if g.MyScore != 0 || g.OpponentScore != 0 || g.Turn != 1 || g.Pellet.Count() != 3 || !g.Pellet.Has(2, 1) || !g.Pellet.Has(3, 1) {
+		t.Errorf("Undo left score %d turn %d and %d pellets", g.MyScore, g.Turn, g.Pellet.Count())
+	}
+}
+
+func TestApplyBouncesTies(t *testing.T) {
+	g := fixture.Game(
+		"#####",
+		"#0 a#",
+		"#####",
+	)
+	pac, enemy := fixture.Pac(g, 0), g.OpponentPacs[0]
+	// both rocks step onto the middle cell and go back
+	g.Apply([]state.Action{{Pac: pac, Target: g.Grid[1][3]}, {Pac: enemy, Target: g.Grid[1][1]}})
+	if pac.X != 1 || enemy.X != 3 || pac.TypeId == "DEAD" || enemy.TypeId == "DEAD" {
+		t.Errorf("pacs on %d and %d, want them back on 1 and 3", pac.X, enemy.X)
+	}
+}
+
+func TestApplyBouncesUntilSettled(t *testing.T) {
+	g := fixture.Game(
+		"######",
+		"#01 a#",
+		"######",
+	)
+	first, second, enemy := fixture.Pac(g, 0), fixture.Pac(g, 1), g.OpponentPacs[0]
+	// the second rock bounces off the enemy back onto the cell the first
+	// one stepped into, which sends the first one back as well
+	g.Apply([]state.Action{{Pac: first, Target: g.Grid[1][2]}, {Pac: second, Target: g.Grid[1][3]}, {Pac: enemy, Target: g.Grid[1][3]}})
+	if first.X != 1 || second.X != 2 || enemy.X != 4 {
+		t.Errorf("pacs on %d, %d and %d, want them back on 1, 2 and 4", first.X, second.X, enemy.X)
+	}
+}
+
+func TestUndoKeepsPelletFlags(t *testing.T) {
+	g := fixture.Game(
+		"#####",
+		"#0. #",
+		"#####",
+	)
+	pac := fixture.Pac(g, 0)
+	g.Pellet.At(2, 1).Doubtful = true
+	g.Undo(g.Apply([]state.Action{{Pac: pac, Target: g.Grid[1][3]}}))
+	if pellet := g.Pellet.At(2, 1); pellet.Consumed || !pellet.Doubtful || !g.Pellet.Has(2, 1) {
+		t.Errorf("pellet %v doubtful %v after Undo, want it back as doubtful", pellet, pellet.Doubtful)
+	}
+}
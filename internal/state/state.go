@@ -0,0 +1,267 @@
+// Package state keeps what is known about the game: pacs, pellets, the
+// plans of my pacs and what is inferred about the cells out of sight.
+package state
+
+import (
+	"fmt"
+
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/params"
+	"spring2020/internal/pathfind"
+	"spring2020/internal/random"
+	"spring2020/internal/telemetry"
+)
+
+// Pac structs
+type Pac struct {
+	Id              int
+	Mine            bool
+	X               int
+	Y               int
+	TypeId          string
+	SpeedTurnsLeft  int
+	AbilityCooldown int
+	LastX           int
+	LastY           int
+	Threatened      bool
+	// Spent the last turn on an ability instead of moving
+	Idle bool
+	// Turns in a row the pac failed to move
+	Stuck int
+	// Last turn the pac was in the input and the turn it was seen before
+	Seen     int
+	PrevSeen int
+	Plan     *Plan
+	// Type beating my closest pac when this opponent pac was last in
+	// contact with mine able to switch, empty out of contact
+	CounterType string
+	// Turns this opponent pac could switch in contact with my pacs, and how
+	// often it switched then and to the type beating mine
+	Contacts, Switches, Counters int
+}
+
+// Cells pac covers in the given number of turns, two per turn while sped up
+func (p *Pac) Reach(turns int) int {
+	return turns + grid.MinInt(turns, p.SpeedTurnsLeft)
+}
+
+// Turns pac needs to walk the given number of cells
+func (p *Pac) TurnsFor(steps int) int {
+	if steps <= 2*p.SpeedTurnsLeft {
+		return (steps + 1) / 2
+	}
+	return steps - p.SpeedTurnsLeft
+}
+
+// Pellet structs
+type Pellet struct {
+	X        int
+	Y        int
+	Value    int
+	Consumed bool
+	// Maybe eaten out of sight, by an opponent pac gone too long to tell
+	// where it went
+	Doubtful bool
+}
+
+// String
+func (p Pellet) String() string {
+	return fmt.Sprintf("Pellet (%d, %d) %d %v", p.X, p.Y, p.Value, p.Consumed)
+}
+
+// Game state structs
+type Game struct {
+	Turn         int
+	Width        int
+	Height       int
+	MyPacs       []*Pac
+	OpponentPacs []*Pac
+	Pellet       *PelletStore
+	Grid         [][]*grid.Cell
+	Walls        grid.Bitboard
+	Dist         *grid.DistanceTable
+	// Junctions and corridors of the maze to route long paths on
+	Corridors *grid.CorridorGraph
+	// Dead-end branches of the maze a pac may be trapped in
+	DeadEnds *grid.DeadEnds
+	// Keys hashing the states of the game
+	Zobrist             *Zobrist
+	MyScore             int
+	OpponentScore       int
+	VisiblePacCount     int
+	VisiblePalleteCount int
+	// Points each player scored in the last turn
+	MyGain       int
+	OpponentGain int
+	// Cells in sight of my pacs this turn and the turn each cell was last seen
+	Visible  grid.Bitboard
+	LastSeen map[*grid.Cell]int
+	// Risk of meeting an opponent pac per cell
+	Risk        map[*grid.Cell]float64
+	Params      params.Params
+	DecisionLog *DecisionLog
+	decision    *Decision
+	// Danger of each cell to my pacs by their type
+	Danger map[string]map[*grid.Cell]float64
+	// Danger weighted flow fields of my pacs by id
+	Flows map[int]*pathfind.FlowField
+	// Target pellets my pacs reserved
+	Reservations Reservations
+	// Source of every random choice, nil playing seed 0
+	Random *random.RNG
+}
+
+// Manhattan distance between two positions, wrapping through the tunnels
+func (g *Game) Distance(x1, y1, x2, y2 int) int {
+	return grid.ManhattanDistance(&grid.Cell{X: x1, Y: y1}, &grid.Cell{X: x2, Y: y2}, g.Width)
+}
+
+// Add pac or update existing pac location data to state mine or opponent
+func (g *Game) AddPac(id, mine, x, y int, typeId string, speedTurnsLeft, abilityCooldown int) {
+	var pacs []*Pac
+	if mine == 1 {
+		pacs = g.MyPacs
+	} else {
+		pacs = g.OpponentPacs
+	}
+	for _, pac := range pacs {
+		if pac.Id == id {
+			if mine != 1 {
+				g.noteHiddenAbility(pac, abilityCooldown)
+				g.noteSwitch(pac, typeId)
+			}
+			pac.LastX = pac.X
+			pac.LastY = pac.Y
+			pac.X = x
+			pac.Y = y
+			pac.TypeId = typeId
+			pac.SpeedTurnsLeft = speedTurnsLeft
+			pac.AbilityCooldown = abilityCooldown
+			pac.PrevSeen = pac.Seen
+			pac.Seen = g.Turn
+			return
+		}
+	}
+	pacs = append(pacs, &Pac{
+		Id:              id,
+		Mine:            mine == 1,
+		X:               x,
+		Y:               y,
+		TypeId:          typeId,
+		SpeedTurnsLeft:  speedTurnsLeft,
+		AbilityCooldown: abilityCooldown,
+		LastX:           x,
+		LastY:           y,
+		Seen:            g.Turn,
+		PrevSeen:        g.Turn,
+	})
+	if mine == 1 {
+		g.MyPacs = pacs
+	} else {
+		g.OpponentPacs = pacs
+	}
+}
+
+// Type the referee reports for pacs that died this game
+const DeadType = "DEAD"
+
+// Drop pacs that died: my pacs missing from the turn input, as all my living
+// pacs are always in it, and pacs of both teams reported dead
+func (g *Game) RemoveDeadPacs() {
+	alive := func(pacs []*Pac, mine bool) []*Pac {
+		var living []*Pac
+		for _, pac := range pacs {
+			if (mine && pac.Seen != g.Turn) || pac.TypeId == DeadType {
+				// the referee keeps reporting a dead pac, which comes back
+				// as a pac first seen this turn; note the turn it died only
+				if pac.PrevSeen != g.Turn {
+					logger.Log("Pac", pac.Id, "mine", mine, "died")
+					if mine {
+						telemetry.DuelLost()
+					}
+				}
+				if mine {
+					g.Reservations.ReleasePac(pac.Id)
+				}
+				continue
+			}
+			living = append(living, pac)
+		}
+		return living
+	}
+	g.MyPacs = alive(g.MyPacs, true)
+	g.OpponentPacs = alive(g.OpponentPacs, false)
+}
+
+// Add pellet or update existing pellet location data to state
+func (g *Game) AddPellet(id, x, y, value int) {
+	g.Pellet.Add(x, y, value)
+}
+
+// Check if the map is mirrored around its vertical center line
+func (g *Game) Symmetric() bool {
+	for y, row := range g.Grid {
+		for x, cell := range row {
+			if cell.IsWall != g.Grid[y][g.Width-1-x].IsWall {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Seed a pellet on every floor cell but the spawn cells, where the game
+// starts with one, so unseen parts of the map are known before they come in
+// sight. Opponent pacs spawn mirrored to mine.
+func (g *Game) SeedPellets() {
+	spawns := make(map[*grid.Cell]bool)
+	for _, pac := range g.MyPacs {
+		spawns[grid.GetCell(pac.X, pac.Y, g.Grid)] = true
+		spawns[grid.GetCell(g.Width-1-pac.X, pac.Y, g.Grid)] = true
+	}
+	for _, row := range g.Grid {
+		for _, cell := range row {
+			if !cell.IsWall && !spawns[cell] {
+				g.Pellet.Add(cell.X, cell.Y, 1)
+			}
+		}
+	}
+}
+
+// Mirror the known super pellets so the ones out of view are known too
+func (g *Game) MirrorSuperPellets() {
+	if !g.Symmetric() {
+		logger.Log("Map is not symmetric, super pellets not mirrored")
+		return
+	}
+	for _, pallet := range g.Pellet.Remaining(10) {
+		g.Pellet.Add(g.Width-1-pallet.X, pallet.Y, 10)
+	}
+}
+
+// Steps from pac to a cell, looked up in the distance table; false when
+// unreachable
+func (g *Game) StepsTo(pac *Pac, x, y int) (int, bool) {
+	return g.Dist.Between(grid.GetCell(pac.X, pac.Y, g.Grid), grid.GetCell(x, y, g.Grid))
+}
+
+// Remove pallet from game o  current Pac cordinates
+func (g *Game) RemovePallet(pac *Pac) {
+	if pallet := g.Pellet.Consume(pac.X, pac.Y); pallet != nil {
+		logger.Log("Pac", pac.Id, "ate pallet", pallet.X, pallet.Y, pallet.Value)
+	}
+}
+
+// Serializable summary of the game state
+func (g *Game) Snapshot() any {
+	pellets := g.Pellet.Remaining(0)
+	return struct {
+		Turn          int
+		MyScore       int
+		OpponentScore int
+		MyPacs        []*Pac
+		OpponentPacs  []*Pac
+		Pellets       []*Pellet
+	}{g.Turn, g.MyScore, g.OpponentScore, g.MyPacs, g.OpponentPacs, pellets}
+}
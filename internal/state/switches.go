@@ -0,0 +1,76 @@
+package state
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/protocol"
+)
+
+// Mark the opponent pacs in sight that may switch this turn within
+// DuelRadius steps of one of my pacs with the type beating the closest one,
+// so next turn's input tells whether they switched in contact
+func (g *Game) MarkContacts() {
+	for _, enemy := range g.OpponentPacs {
+		enemy.CounterType = ""
+		if enemy.Seen != g.Turn || enemy.AbilityCooldown > 0 {
+			continue
+		}
+		closest := g.Params.DuelRadius + 1
+		for _, pac := range g.MyPacs {
+			d, ok := g.Dist.Between(grid.GetCell(pac.X, pac.Y, g.Grid), grid.GetCell(enemy.X, enemy.Y, g.Grid))
+			if ok && d < closest {
+				enemy.CounterType, closest = Counter(pac.TypeId), d
+			}
+		}
+	}
+}
+
+// Count the turn of an opponent pac marked in contact last turn into its
+// switch history, now that its type this turn is known
+func (g *Game) noteSwitch(enemy *Pac, typeId string) {
+	if enemy.CounterType == "" || enemy.Seen != g.Turn-1 || typeId == DeadType {
+		return
+	}
+	enemy.Contacts++
+	if typeId == enemy.TypeId {
+		return
+	}
+	enemy.Switches++
+	if typeId == enemy.CounterType {
+		enemy.Counters++
+	}
+	logger.Log("Enemy", enemy.Id, "switched to", typeId, "in contact,", enemy.Switches, "of", enemy.Contacts, "times")
+}
+
+// Chance of each type an opponent pac may have next turn facing my pac of
+// type against. A pac that cannot switch keeps its type; one that can
+// switches as often as it did in contact before, starting from SwitchPrior,
+// and to the type beating mine as often as it did, starting from
+// CounterPrior, both priors counting as SwitchPriorWeight turns of history.
+func (g *Game) PredictSwitch(enemy *Pac, against string) map[string]float64 {
+	odds := map[string]float64{enemy.TypeId: 1}
+	if g.EnemyCooldown(enemy) > 0 {
+		return odds
+	}
+	weight := g.Params.SwitchPriorWeight
+	switched := (float64(enemy.Switches) + g.Params.SwitchPrior*weight) / (float64(enemy.Contacts) + weight)
+	countered := (float64(enemy.Counters) + g.Params.CounterPrior*weight) / (float64(enemy.Switches) + weight)
+	odds[enemy.TypeId] = 1 - switched
+	counter := Counter(against)
+	var others []string
+	for _, t := range protocol.PacTypes {
+		if t != enemy.TypeId && t != counter {
+			others = append(others, t)
+		}
+	}
+	if counter == enemy.TypeId {
+		// already beating mine, a switch goes to either other type
+		countered = 0
+	} else {
+		odds[counter] = switched * countered
+	}
+	for _, t := range others {
+		odds[t] = switched * (1 - countered) / float64(len(others))
+	}
+	return odds
+}
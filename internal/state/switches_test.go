@@ -0,0 +1,55 @@
+package state_test
+
+import (
+	"math"
+	"testing"
+
+	"spring2020/internal/fixture"
+)
+
+func TestPredictSwitchLearnsFromContact(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0 a  #",
+		"#######",
+	)
+	enemy := g.OpponentPacs[0]
+	enemy.TypeId = "SCISSORS"
+	g.MarkContacts()
+	if enemy.CounterType != "PAPER" {
+		t.Fatalf("counter type %q, want PAPER against my rock", enemy.CounterType)
+	}
+	prior := g.PredictSwitch(enemy, "ROCK")
+	if p := prior["SCISSORS"]; math.Abs(p-(1-g.Params.SwitchPrior)) > 1e-9 {
+		t.Errorf("keeps its type with %v before any contact, want %v", p, 1-g.Params.SwitchPrior)
+	}
+	// the enemy switches to paper next turn in contact
+	g.Turn++
+	g.AddPac(0, 0, 3, 1, "PAPER", 0, 10)
+	if enemy.Contacts != 1 || enemy.Switches != 1 || enemy.Counters != 1 {
+		t.Fatalf("history %d %d %d, want one countering switch", enemy.Contacts, enemy.Switches, enemy.Counters)
+	}
+	if odds := g.PredictSwitch(enemy, "ROCK"); odds["PAPER"] != 1 {
+		t.Errorf("odds %v, want a pac on cooldown to keep its type", odds)
+	}
+	enemy.AbilityCooldown = 0
+	odds := g.PredictSwitch(enemy, "SCISSORS")
+	if odds["ROCK"] <= prior["PAPER"] {
+		t.Errorf("counter odds %v after a counter, want above the prior %v", odds["ROCK"], prior["PAPER"])
+	}
+	sum := 0.0
+	for _, p := range odds {
+		sum += p
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("odds %v sum to %v", odds, sum)
+	}
+	// out of contact nothing is counted
+	g.OpponentPacs[0].X = 5
+	g.MarkContacts()
+	g.Turn++
+	g.AddPac(0, 0, 5, 1, "ROCK", 0, 0)
+	if enemy.Contacts != 1 {
+		t.Errorf("%d contacts, want the switch out of contact left out", enemy.Contacts)
+	}
+}
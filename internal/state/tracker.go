@@ -0,0 +1,224 @@
+package state
+
+import (
+	"math"
+
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/telemetry"
+)
+
+// Turns a SPEED lasts
+const SpeedDuration = 5
+
+// Turns before a pac may use an ability again
+const AbilityCooldown = 10
+
+// Cooldown of the pacs of leagues without abilities, longer than any game so
+// no ability is ever expected of them
+const NoAbilityCooldown = 1000
+
+// Opponent pacs in the input this turn
+func (g *Game) VisibleEnemies() []*Pac {
+	var visible []*Pac
+	for _, enemy := range g.OpponentPacs {
+		if enemy.Seen == g.Turn {
+			visible = append(visible, enemy)
+		}
+	}
+	return visible
+}
+
+// Confidence in what is known about an opponent pac, decaying every turn it
+// is out of sight
+func (g *Game) Confidence(enemy *Pac) float64 {
+	return math.Pow(g.Params.TrackingDecay, float64(g.Turn-enemy.Seen))
+}
+
+// Turns until an opponent pac may use an ability again, counted down from
+// the cooldown it had when last seen; 0 when it may use one this turn. The
+// cooldown of a pac in sight is the one the referee reports.
+func (g *Game) EnemyCooldown(enemy *Pac) int {
+	if left := enemy.AbilityCooldown - (g.Turn - enemy.Seen); left > 0 {
+		return left
+	}
+	return 0
+}
+
+// Turns an opponent pac may still be sped up by the SPEED it had when last
+// seen, not counting one it may have activated since
+func (g *Game) EnemySpeedLeft(enemy *Pac) int {
+	if left := enemy.SpeedTurnsLeft - (g.Turn - enemy.Seen); left > 0 {
+		return left
+	}
+	return 0
+}
+
+// Check if an opponent pac may SWITCH or SPEED within turns turns from now,
+// 0 for this turn
+func (g *Game) EnemyAbilityWithin(enemy *Pac, turns int) bool {
+	return g.EnemyCooldown(enemy) <= turns
+}
+
+// Check if an opponent pac may SWITCH to the counter of my pac and still
+// move on it within the next turns turns, this one included, the switch
+// taking a turn of its own
+func (g *Game) EnemyMayCounter(enemy *Pac, turns int) bool {
+	return turns >= 2 && g.EnemyAbilityWithin(enemy, turns-2)
+}
+
+// Most cells an opponent pac may have covered since it was last seen,
+// counting the speed it had and a SPEED it may have activated once its
+// cooldown ran out
+func (g *Game) EnemyReach(enemy *Pac) int {
+	elapsed := g.Turn - enemy.Seen
+	fast := grid.MinInt(elapsed, enemy.SpeedTurnsLeft)
+	if ready := enemy.AbilityCooldown + 1; ready < elapsed {
+		fast += grid.MinInt(elapsed-ready, SpeedDuration)
+	}
+	return elapsed + fast
+}
+
+// Note an opponent pac seen again with more cooldown than counted down
+// since it was last seen, which means it used an ability out of sight
+func (g *Game) noteHiddenAbility(enemy *Pac, abilityCooldown int) {
+	if enemy.Seen < g.Turn-1 && abilityCooldown <= AbilityCooldown && abilityCooldown > g.EnemyCooldown(enemy) {
+		logger.Log("Enemy", enemy.Id, "used an ability out of sight about turn", g.Turn-(AbilityCooldown-abilityCooldown))
+	}
+}
+
+// Cells an opponent pac may stand on now with their distance from where it
+// was last seen
+func (g *Game) PredictEnemy(enemy *Pac) map[*grid.Cell]int {
+	return cellsWithin(grid.GetCell(enemy.X, enemy.Y, g.Grid), g.EnemyReach(enemy))
+}
+
+// Cells an opponent pac in sight may stand on after its next move, its own
+// cell included, with their distance from it
+func (g *Game) EnemyMoves(enemy *Pac) map[*grid.Cell]int {
+	return cellsWithin(grid.GetCell(enemy.X, enemy.Y, g.Grid), enemy.Reach(1))
+}
+
+// Cell an opponent pac in sight most likely steps onto next: on along the
+// way it came last turn. Nil when it stood still, was out of sight last turn
+// or has more than one way on.
+func (g *Game) enemyNext(enemy *Pac) *grid.Cell {
+	if enemy.Seen != g.Turn || enemy.PrevSeen != g.Turn-1 || (enemy.X == enemy.LastX && enemy.Y == enemy.LastY) {
+		return nil
+	}
+	last, cell := grid.GetCell(enemy.LastX, enemy.LastY, g.Grid), grid.GetCell(enemy.X, enemy.Y, g.Grid)
+	came, _ := g.Dist.Between(last, cell)
+	var next *grid.Cell
+	for _, n := range cell.Neighbors {
+		if d, ok := g.Dist.Between(last, n); ok && d > came {
+			if next != nil {
+				return nil
+			}
+			next = n
+		}
+	}
+	return next
+}
+
+// Best estimate of the turns the closest opponent pac needs to reach cell:
+// walking at its speed from its cell when in sight, from where it was last
+// seen straight towards cell when out of sight. False when no opponent pac
+// known well enough reaches it.
+func (g *Game) EnemyTurnsTo(cell *grid.Cell) (int, bool) {
+	best, found := 0, false
+	for _, enemy := range g.OpponentPacs {
+		if enemy.Seen != g.Turn && g.Confidence(enemy) < g.Params.RaceConfidence {
+			continue
+		}
+		d, ok := g.Dist.Between(grid.GetCell(enemy.X, enemy.Y, g.Grid), cell)
+		if !ok {
+			continue
+		}
+		turns := enemy.TurnsFor(d)
+		if enemy.Seen != g.Turn {
+			turns = d - g.EnemyReach(enemy)
+			if turns < 0 {
+				turns = 0
+			}
+		}
+		if !found || turns < best {
+			best, found = turns, true
+		}
+	}
+	return best, found
+}
+
+// Floor cells at most reach steps from start with their distance
+func cellsWithin(start *grid.Cell, reach int) map[*grid.Cell]int {
+	dist := map[*grid.Cell]int{start: 0}
+	queue := []*grid.Cell{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if dist[current] == reach {
+			continue
+		}
+		for _, neighbor := range current.Neighbors {
+			if _, seen := dist[neighbor]; seen || neighbor.IsWall {
+				continue
+			}
+			dist[neighbor] = dist[current] + 1
+			queue = append(queue, neighbor)
+		}
+	}
+	return dist
+}
+
+// Risk of meeting an opponent pac on each cell: 1 where one is in sight, the
+// tracking confidence on every cell an unseen one may have reached. Cells in
+// sight of my pacs without an enemy are safe.
+func (g *Game) ComputeRisk() map[*grid.Cell]float64 {
+	risk := make(map[*grid.Cell]float64)
+	for _, enemy := range g.OpponentPacs {
+		if enemy.Seen == g.Turn {
+			risk[grid.GetCell(enemy.X, enemy.Y, g.Grid)] = 1
+			continue
+		}
+		confidence := g.Confidence(enemy)
+		for cell := range g.PredictEnemy(enemy) {
+			if !g.Visible.Has(cell.X, cell.Y) && confidence > risk[cell] {
+				risk[cell] = confidence
+			}
+		}
+	}
+	return risk
+}
+
+// Steps added to the distance of a pellet for target scoring by the risk of
+// meeting an opponent pac on its cell
+func (g *Game) RiskAdjustment(pallet *Pellet) int {
+	return int(g.Risk[grid.GetCell(pallet.X, pallet.Y, g.Grid)] * g.Params.RiskWeight)
+}
+
+// Drop opponent pacs that most likely lost a type battle: seen last turn
+// next to one of my pacs beating their type, and gone from their cell now
+// that it is in sight
+func (g *Game) InferEnemyDeaths() {
+	var living []*Pac
+	for _, enemy := range g.OpponentPacs {
+		if enemy.Seen == g.Turn-1 && g.Visible.Has(enemy.X, enemy.Y) {
+			if pac := g.eatenBy(enemy); pac != nil {
+				logger.Log("Enemy", enemy.Id, "eaten by pac", pac.Id)
+				telemetry.DuelWon()
+				continue
+			}
+		}
+		living = append(living, enemy)
+	}
+	g.OpponentPacs = living
+}
+
+// My pac standing next to where enemy was that beats its type, if any
+func (g *Game) eatenBy(enemy *Pac) *Pac {
+	for _, pac := range g.MyPacs {
+		if Matchup(pac.TypeId, enemy.TypeId) == 1 && g.Distance(pac.X, pac.Y, enemy.X, enemy.Y) <= 1 {
+			return pac
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package state_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+)
+
+func TestEnemyCooldownCountsDownOutOfSight(t *testing.T) {
+	g := fixture.Game(loop...)
+	enemy := g.OpponentPacs[0]
+	enemy.AbilityCooldown, enemy.SpeedTurnsLeft = 4, 3
+	if g.EnemyCooldown(enemy) != 4 || g.EnemySpeedLeft(enemy) != 3 {
+		t.Fatalf("in sight: cooldown %d speed %d, want the reported 4 and 3", g.EnemyCooldown(enemy), g.EnemySpeedLeft(enemy))
+	}
+	if g.EnemyAbilityWithin(enemy, 3) || !g.EnemyAbilityWithin(enemy, 4) {
+		t.Error("ability window does not open after the cooldown")
+	}
+	if g.EnemyMayCounter(enemy, 5) || !g.EnemyMayCounter(enemy, 6) {
+		t.Error("counter window does not leave a turn for the switch")
+	}
+	g.Turn += 3
+	if got := g.EnemyCooldown(enemy); got != 1 {
+		t.Errorf("cooldown %d three turns out of sight, want 1", got)
+	}
+	if got := g.EnemySpeedLeft(enemy); got != 0 {
+		t.Errorf("speed %d three turns out of sight, want 0", got)
+	}
+	g.Turn += 5
+	if got := g.EnemyCooldown(enemy); got != 0 || !g.EnemyAbilityWithin(enemy, 0) {
+		t.Errorf("cooldown %d long out of sight, want 0", got)
+	}
+}
@@ -0,0 +1,63 @@
+package state
+
+// Slot of the transposition table
+type ttEntry struct {
+	hash  uint64
+	gen   uint32
+	depth int
+	value float64
+}
+
+// Bounded table of the values a search found for positions by their
+// Zobrist hash, so a position reached again by another order of moves is
+// not searched again. Every hash has one slot and a newer entry replaces
+// the one there. A search starts afresh by bumping the generation, which
+// makes all entries stale without clearing them. A nil table stores
+// nothing. Not safe for concurrent searches.
+type TranspositionTable struct {
+	entries []ttEntry
+	mask    uint64
+	gen     uint32
+	// Lookups and lookups answered since the last generation
+	Probes, Hits int
+}
+
+// Create table of 2^bits slots, nil when bits is 0
+func NewTranspositionTable(bits int) *TranspositionTable {
+	if bits <= 0 {
+		return nil
+	}
+	return &TranspositionTable{entries: make([]ttEntry, 1<<bits), mask: 1<<bits - 1, gen: 1}
+}
+
+// Make every entry stale
+func (t *TranspositionTable) NextGeneration() {
+	if t == nil {
+		return
+	}
+	t.gen++
+	t.Probes, t.Hits = 0, 0
+}
+
+// Value stored for the position of hash searched at least depth deep in
+// this generation
+func (t *TranspositionTable) Lookup(hash uint64, depth int) (float64, bool) {
+	if t == nil {
+		return 0, false
+	}
+	t.Probes++
+	e := &t.entries[hash&t.mask]
+	if e.gen != t.gen || e.hash != hash || e.depth < depth {
+		return 0, false
+	}
+	t.Hits++
+	return e.value, true
+}
+
+// Store the value of the position of hash searched depth deep
+func (t *TranspositionTable) Store(hash uint64, depth int, value float64) {
+	if t == nil {
+		return
+	}
+	t.entries[hash&t.mask] = ttEntry{hash: hash, gen: t.gen, depth: depth, value: value}
+}
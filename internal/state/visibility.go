@@ -0,0 +1,175 @@
+package state
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/telemetry"
+)
+
+// Cells pac sees: its own and every cell along the four straight lines from
+// it up to the first wall, wrapping through the tunnels
+func (g *Game) LineOfSight(pac *Pac) []*grid.Cell {
+	start := grid.GetCell(pac.X, pac.Y, g.Grid)
+	cells := []*grid.Cell{start}
+	for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		x, y := pac.X, pac.Y
+		for {
+			x, y = (x+d[0]+g.Width)%g.Width, y+d[1]
+			if y < 0 || y >= g.Height {
+				break
+			}
+			cell := grid.GetCell(x, y, g.Grid)
+			if g.Walls.Has(x, y) || cell == start {
+				break
+			}
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+// Mark the cells my pacs see this turn as visible and remember when each
+// cell was last seen
+func (g *Game) UpdateVisibility() {
+	g.Visible = grid.NewBitboard(g.Width, g.Height)
+	if g.LastSeen == nil {
+		g.LastSeen = make(map[*grid.Cell]int)
+	}
+	for _, pac := range g.MyPacs {
+		if pac.Seen != g.Turn {
+			continue
+		}
+		for _, cell := range g.LineOfSight(pac) {
+			g.Visible.Set(cell.X, cell.Y)
+			g.LastSeen[cell] = g.Turn
+		}
+	}
+}
+
+// Count the pellets my pacs ate last turn into the telemetry: those still
+// believed on the cells they walked, before the pellets in sight are
+// forgotten
+func (g *Game) CountMyHarvest() {
+	for _, pac := range g.MyPacs {
+		if pac.PrevSeen != g.Turn-1 || pac.Seen != g.Turn {
+			continue
+		}
+		from, to := grid.GetCell(pac.LastX, pac.LastY, g.Grid), grid.GetCell(pac.X, pac.Y, g.Grid)
+		walked := []*grid.Cell{to}
+		if d, ok := g.Dist.Between(from, to); ok && d == 2 {
+			walked = append(walked, g.StepToward(from, to))
+		}
+		for _, cell := range walked {
+			if pallet := g.Pellet.At(cell.X, cell.Y); pallet != nil && !pallet.Consumed {
+				telemetry.PelletEaten(pac.Id, pallet.Value)
+			}
+		}
+	}
+}
+
+// Mark the pellets that would be in sight as consumed, so that only the
+// ones listed again in the input stay. Super pellets are visible from
+// everywhere, pellets out of sight are kept as last seen.
+func (g *Game) ForgetObservedPellets() {
+	for _, pallet := range g.Pellet.Remaining(0) {
+		if pallet.Value == 10 || g.Visible.Has(pallet.X, pallet.Y) {
+			g.Pellet.Consume(pallet.X, pallet.Y)
+		}
+	}
+}
+
+// Infer pellets eaten out of sight: an opponent pac seen again after a gap
+// that moved at least a cell per turn most likely walked straight to where
+// it is now, eating the pellets on the way
+func (g *Game) InferEnemyHarvest() {
+	for _, enemy := range g.OpponentPacs {
+		gap := enemy.Seen - enemy.PrevSeen
+		if enemy.Seen != g.Turn || gap < 2 {
+			continue
+		}
+		end := grid.GetCell(enemy.X, enemy.Y, g.Grid)
+		path := grid.BfsFind(grid.GetCell(enemy.LastX, enemy.LastY, g.Grid), func(*grid.Cell) bool {
+			return true
+		}, func(c *grid.Cell) bool {
+			return c == end
+		})
+		if len(path)-1 < gap {
+			continue
+		}
+		for _, cell := range path {
+			if g.Visible.Has(cell.X, cell.Y) {
+				continue
+			}
+			if pallet := g.Pellet.Consume(cell.X, cell.Y); pallet != nil {
+				logger.Trace("Pellet", pallet.X, pallet.Y, "inferred eaten by enemy", enemy.Id)
+			}
+		}
+	}
+}
+
+// Points of the pellets believed on the cells in sight, and of the super
+// pellets anywhere as they are always in sight
+func (g *Game) ValueInSight() int {
+	value := 0
+	for _, pallet := range g.Pellet.Remaining(0) {
+		if pallet.Value == 10 || g.Visible.Has(pallet.X, pallet.Y) {
+			value += pallet.Value
+		}
+	}
+	return value
+}
+
+// Infer pellets eaten out of sight from the opponent's score: the points it
+// scored beyond those of the pellets that vanished in sight without my pacs
+// eating them were scored by its pacs out of sight. Each of those eats, in
+// turn, the pellet closest to where it was last seen that it may have
+// reached, at most two, until the points are explained. The guess is only
+// good while the pac's reach is small: pacs out of sight for longer mark
+// the pellet doubtful instead, leaving it to be checked.
+func (g *Game) InferHiddenHarvest(vanished int) {
+	hidden := g.OpponentGain - (vanished - g.MyGain)
+	if hidden <= 0 {
+		return
+	}
+	type reach struct {
+		enemy *Pac
+		dist  map[*grid.Cell]int
+		left  int
+	}
+	var hiders []*reach
+	for _, enemy := range g.OpponentPacs {
+		if enemy.Seen != g.Turn {
+			// a pac moves two cells a turn at most, sped up
+			hiders = append(hiders, &reach{enemy, g.PredictEnemy(enemy), 2})
+		}
+	}
+	for progress := true; hidden > 0 && progress; {
+		progress = false
+		for _, h := range hiders {
+			if hidden <= 0 || h.left == 0 {
+				continue
+			}
+			var closest *Pellet
+			closestDist := 0
+			for _, pallet := range g.Pellet.Remaining(1) {
+				cell := grid.GetCell(pallet.X, pallet.Y, g.Grid)
+				if d, ok := h.dist[cell]; ok && !pallet.Doubtful && !g.Visible.Has(cell.X, cell.Y) && (closest == nil || d < closestDist) {
+					closest, closestDist = pallet, d
+				}
+			}
+			if closest == nil {
+				continue
+			}
+			if g.EnemyReach(h.enemy) <= g.Params.HiddenHarvestReach {
+				g.Pellet.Consume(closest.X, closest.Y)
+				logger.Trace("Pellet", closest.X, closest.Y, "inferred eaten out of sight by enemy", h.enemy.Id)
+			} else {
+				closest.Doubtful = true
+				logger.Trace("Pellet", closest.X, closest.Y, "maybe eaten out of sight by enemy", h.enemy.Id)
+			}
+			hidden -= closest.Value
+			h.left--
+			progress = true
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package state
+
+import (
+	"math/rand"
+
+	"spring2020/internal/grid"
+	"spring2020/internal/protocol"
+)
+
+// Seed of the Zobrist keys, the same every game so hashes are reproducible
+const zobristSeed = 2020
+
+// Random keys of the Zobrist hash of game states: one per cell for a pac of
+// either team and each type, one per count of SPEED turns and of cooldown
+// turns of either team, and one per cell for a pellet. The hash of a state
+// XORs the keys of what is in it, so it is updated by XORing the keys of
+// what changed and equal states hash the same whatever moves led to them.
+type Zobrist struct {
+	width int
+	// by team, mine first, type and cell
+	pacs     [2][][]uint64
+	speed    [2][]uint64
+	cooldown [2][]uint64
+	pellets  []uint64
+}
+
+// Draw the keys of a width by height maze
+func NewZobrist(width, height int) *Zobrist {
+	rng := rand.New(rand.NewSource(zobristSeed))
+	keys := func(n int) []uint64 {
+		k := make([]uint64, n)
+		for i := range k {
+			k[i] = rng.Uint64()
+		}
+		return k
+	}
+	z := &Zobrist{width: width, pellets: keys(width * height)}
+	for team := range z.pacs {
+		// the types of the league, then any other type
+		z.pacs[team] = make([][]uint64, len(protocol.PacTypes)+1)
+		for t := range z.pacs[team] {
+			z.pacs[team][t] = keys(width * height)
+		}
+		z.speed[team] = keys(SpeedDuration + 1)
+		z.cooldown[team] = keys(AbilityCooldown + 1)
+	}
+	return z
+}
+
+func zobristTeam(mine bool) int {
+	if mine {
+		return 0
+	}
+	return 1
+}
+
+// Key of a pac of typeId on cell
+func (z *Zobrist) Pac(mine bool, typeId string, cell *grid.Cell) uint64 {
+	t := len(protocol.PacTypes)
+	for i, name := range protocol.PacTypes {
+		if name == typeId {
+			t = i
+		}
+	}
+	return z.pacs[zobristTeam(mine)][t][cell.Y*z.width+cell.X]
+}
+
+// Key of the SPEED turns and cooldown turns left to a pac, the cooldown
+// counted as AbilityCooldown at most
+func (z *Zobrist) Abilities(mine bool, speed, cooldown int) uint64 {
+	return z.speed[zobristTeam(mine)][grid.MinInt(speed, SpeedDuration)] ^ z.cooldown[zobristTeam(mine)][grid.MinInt(cooldown, AbilityCooldown)]
+}
+
+// Key of a pellet on cell
+func (z *Zobrist) Pellet(cell *grid.Cell) uint64 {
+	return z.pellets[cell.Y*z.width+cell.X]
+}
+
+// Zobrist hash of the living pacs where they were last seen with their
+// types and abilities, and of the pellets believed left. Scores and the turn
+// are left out, as searches compare states at the same depth. 0 without
+// keys.
+func (g *Game) Hash() uint64 {
+	z := g.Zobrist
+	if z == nil {
+		return 0
+	}
+	var h uint64
+	for _, pacs := range [][]*Pac{g.MyPacs, g.OpponentPacs} {
+		for _, pac := range pacs {
+			if pac.TypeId == DeadType {
+				continue
+			}
+			h ^= z.Pac(pac.Mine, pac.TypeId, grid.GetCell(pac.X, pac.Y, g.Grid))
+			h ^= z.Abilities(pac.Mine, pac.SpeedTurnsLeft, pac.AbilityCooldown)
+		}
+	}
+	for _, pellet := range g.Pellet.Remaining(0) {
+		h ^= z.Pellet(grid.GetCell(pellet.X, pellet.Y, g.Grid))
+	}
+	return h
+}
@@ -0,0 +1,77 @@
+package state_test
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/state"
+)
+
+func TestHashIgnoresMoveOrder(t *testing.T) {
+	g := fixture.Game(
+		"#######",
+		"#0    #",
+		"#    .#",
+		"#    a#",
+		"#######",
+	)
+	pac := fixture.Pac(g, 0)
+	start := g.Hash()
+	if start == 0 {
+		t.Fatal("no hash with keys")
+	}
+	// right then down and down then right end on the same cell
+	u := g.Apply([]state.Action{{Pac: pac, Target: g.Grid[1][2]}})
+	v := g.Apply([]state.Action{{Pac: pac, Target: g.Grid[2][2]}})
+	across := g.Hash()
+	g.Undo(v)
+	g.Undo(u)
+	if g.Hash() != start {
+		t.Fatal("Undo did not restore the hash")
+	}
+	u = g.Apply([]state.Action{{Pac: pac, Target: g.Grid[2][1]}})
+	v = g.Apply([]state.Action{{Pac: pac, Target: g.Grid[2][2]}})
+	if g.Hash() != across {
+		t.Error("the same position hashes differently after another move order")
+	}
+	g.Undo(v)
+	g.Undo(u)
+	// eating a pellet or switching type is another position
+	g.Pellet.Consume(5, 2)
+	if g.Hash() == start {
+		t.Error("hash unchanged by the pellet eaten")
+	}
+	g.Pellet.Add(5, 2, 1)
+	pac.TypeId = "PAPER"
+	if g.Hash() == start {
+		t.Error("hash unchanged by the switch")
+	}
+}
+
+func TestTranspositionTable(t *testing.T) {
+	var none *state.TranspositionTable
+	none.Store(1, 1, 1)
+	if _, ok := none.Lookup(1, 1); ok || state.NewTranspositionTable(0) != nil {
+		t.Error("nil table stores values")
+	}
+	table := state.NewTranspositionTable(4)
+	table.Store(42, 2, 0.5)
+	if v, ok := table.Lookup(42, 2); !ok || v != 0.5 {
+		t.Errorf("got %v, %v, want 0.5", v, ok)
+	}
+	if _, ok := table.Lookup(42, 3); ok {
+		t.Error("shallower value answers a deeper lookup")
+	}
+	// 42+16 takes the slot of 42
+	table.Store(42+16, 1, 0.25)
+	if _, ok := table.Lookup(42, 1); ok {
+		t.Error("replaced entry still answers")
+	}
+	if table.Hits != 1 || table.Probes != 3 {
+		t.Errorf("got %d hits of %d probes, want 1 of 3", table.Hits, table.Probes)
+	}
+	table.NextGeneration()
+	if _, ok := table.Lookup(42+16, 1); ok {
+		t.Error("entry of an earlier generation answers")
+	}
+}
@@ -0,0 +1,44 @@
+package strategy
+
+import (
+	"fmt"
+	"strings"
+
+	"spring2020/internal/grid"
+	"spring2020/internal/state"
+)
+
+// Short note on the decision of a pac shown next to its command in the
+// replay viewer: the first letter of its role, the cell it heads for, the
+// steps left to it and ! when the cell it stands on is in danger, like
+// "c 12,4 7 !"
+func (g *Bot) Annotate(pac *state.Pac, part Part) string {
+	role := part.Role
+	if role == "" {
+		role = RoleCollector
+	}
+	fields := []string{string(role[:1])}
+	var target *grid.Cell
+	steps := -1
+	switch {
+	case part.Intercept != nil:
+		target = part.Intercept
+	case part.Prey != nil:
+		target = grid.GetCell(part.Prey.X, part.Prey.Y, g.Grid)
+	case part.Post != nil:
+		target = part.Post
+	case pac.Plan != nil && pac.Plan.Target != nil:
+		target = grid.GetCell(pac.Plan.Target.X, pac.Plan.Target.Y, g.Grid)
+		steps = len(pac.Plan.Waypoints)
+	}
+	if target != nil {
+		if steps < 0 {
+			steps, _ = g.StepsTo(pac, target.X, target.Y)
+		}
+		fields = append(fields, fmt.Sprintf("%d,%d", target.X, target.Y), fmt.Sprint(steps))
+	}
+	if g.Danger != nil && g.DangerTo(pac, grid.GetCell(pac.X, pac.Y, g.Grid)) > 0 {
+		fields = append(fields, "!")
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,46 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+
+	"spring2020/internal/budget"
+	"spring2020/internal/fixture"
+	"spring2020/internal/gameio"
+	"spring2020/internal/protocol"
+)
+
+func TestAnnotateNamesRoleTargetAndSteps(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"###########",
+		"#0  a    1#",
+		"###########",
+	))
+	enemy := bot.OpponentPacs[0]
+	enemy.TypeId, enemy.AbilityCooldown = "SCISSORS", 5
+	part := bot.AssignRoles()[0]
+	if got := bot.Annotate(fixture.Pac(bot.Game, 0), part); got != "h 4,1 3" {
+		t.Errorf("hunter annotated %q, want \"h 4,1 3\"", got)
+	}
+	if got := bot.Annotate(fixture.Pac(bot.Game, 1), Part{}); got != "c" {
+		t.Errorf("collector without plan annotated %q, want \"c\"", got)
+	}
+}
+
+func TestPlayTurnPublishesMessages(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#0 . . o#",
+		"#########",
+	))
+	pub := gameio.NewPublisher(bot.MyPacs, bot.Width, bot.Height)
+	bot.PlayTurn(pub, budget.NewTurnBudget(0))
+	line := pub.Publish()
+	commands, errs := protocol.ParseCommands(line)
+	if len(errs) > 0 || len(commands) != 1 {
+		t.Fatalf("published %q, errors %v", line, errs)
+	}
+	if !strings.HasPrefix(commands[0].Message, "c ") {
+		t.Errorf("message %q, want the collector annotation", commands[0].Message)
+	}
+}
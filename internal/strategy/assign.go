@@ -0,0 +1,196 @@
+package strategy
+
+import (
+	"math"
+	"sort"
+
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/parallel"
+	"spring2020/internal/state"
+)
+
+// Most candidate pellets per pac considered by the target assignment
+const AssignCandidates = 8
+
+// Cost of an assignment that must not be made
+const Unassignable = 1 << 30
+
+// Score of pellet as a target of pac at dist steps, lower is better: the
+// distance with the territory, risk, danger, trap and influence
+// adjustments, less ValueWeight steps per point above a regular pellet
+func (g *Bot) targetCost(pac *state.Pac, pallet *state.Pellet, dist int) int {
+	return dist + g.TerritoryAdjustment(pallet) + g.RiskAdjustment(pallet) + g.DangerAdjustment(pac, pallet, dist) + g.TrapAdjustment(pac, pallet, dist) - g.InfluenceAdjustment(pallet) - (pallet.Value-1)*g.Params.ValueWeight
+}
+
+// Assign distinct target pellets to pacs minimizing their summed target
+// cost, so pacs spread over the map instead of converging on the pellets
+// closest to all of them. Each pac considers its AssignCandidates cheapest
+// free pellets in its own region, or anywhere once its region is empty,
+// the pacs priced in parallel. Pacs left without a reachable pellet are
+// missing, as are the pacs not priced before the turn budget ran low.
+func (g *Bot) AssignTargets(pacs []*state.Pac) map[int]*state.Pellet {
+	if len(pacs) == 0 {
+		return nil
+	}
+	type option struct {
+		pellet *state.Pellet
+		cost   int
+	}
+	// price the candidates of every pac in parallel, then merge them in pac
+	// order into the columns of the matrix
+	candidates := make([][]option, len(pacs))
+	priced := make([]bool, len(pacs))
+	pellets := g.Pellet.Remaining(0)
+	parallel.ForEach(len(pacs), func(i int) {
+		if g.Budget.Low() {
+			return
+		}
+		pac := pacs[i]
+		var options, regional []option
+		for _, pallet := range pellets {
+			if g.Reservations.Reserved(pallet) || pallet.Value == 0 {
+				continue
+			}
+			if d, ok := g.StepsTo(pac, pallet.X, pallet.Y); ok {
+				if g.conceded(pac, pallet, d) || g.cutOff(pac, pallet, d) {
+					continue
+				}
+				o := option{pallet, g.targetCost(pac, pallet, d)}
+				options = append(options, o)
+				if region, ok := g.Regions[grid.GetCell(pallet.X, pallet.Y, g.Grid)]; !ok || region == pac.Id {
+					regional = append(regional, o)
+				}
+			}
+		}
+		// a pac keeps to its own region while there is something left in it
+		if len(regional) > 0 {
+			options = regional
+		}
+		sort.Slice(options, func(a, b int) bool {
+			return options[a].cost < options[b].cost
+		})
+		if len(options) > AssignCandidates {
+			options = options[:AssignCandidates]
+		}
+		candidates[i], priced[i] = options, true
+	})
+	var costs []map[*state.Pellet]int
+	var columns []*state.Pellet
+	index := make(map[*state.Pellet]int)
+	var pricedPacs []*state.Pac
+	for i, pac := range pacs {
+		if !priced[i] {
+			continue
+		}
+		pricedPacs = append(pricedPacs, pac)
+		row := make(map[*state.Pellet]int)
+		for _, o := range candidates[i] {
+			row[o.pellet] = o.cost
+			if _, ok := index[o.pellet]; !ok {
+				index[o.pellet] = len(columns)
+				columns = append(columns, o.pellet)
+			}
+		}
+		costs = append(costs, row)
+	}
+	if len(pricedPacs) < len(pacs) {
+		logger.Info("Assignment out of time after", len(pricedPacs), "of", len(pacs), "pacs")
+		pacs = pricedPacs
+	}
+	matrix := make([][]int, len(pacs))
+	for i := range pacs {
+		matrix[i] = make([]int, len(columns))
+		for j, pallet := range columns {
+			if cost, ok := costs[i][pallet]; ok {
+				matrix[i][j] = cost
+			} else {
+				matrix[i][j] = Unassignable
+			}
+		}
+	}
+	assigned := make(map[int]*state.Pellet)
+	for i, j := range hungarian(matrix) {
+		if j >= 0 && matrix[i][j] < Unassignable {
+			assigned[pacs[i].Id] = columns[j]
+		}
+	}
+	return assigned
+}
+
+// Solve the assignment problem on a cost matrix with the Hungarian
+// algorithm, returning the column assigned to each row or -1 when there are
+// more rows than columns
+func hungarian(cost [][]int) []int {
+	n := len(cost)
+	if n == 0 {
+		return nil
+	}
+	// rows beyond the columns get dummy columns
+	m := len(cost[0])
+	if m < n {
+		m = n
+	}
+	at := func(i, j int) int {
+		if j < len(cost[i]) {
+			return cost[i][j]
+		}
+		return Unassignable
+	}
+	// potentials, the row matched to each column and the previous column on
+	// the augmenting path, with column 0 as the virtual start
+	u := make([]int, n+1)
+	v := make([]int, m+1)
+	p := make([]int, m+1)
+	way := make([]int, m+1)
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]int, m+1)
+		used := make([]bool, m+1)
+		for j := range minv {
+			minv[j] = math.MaxInt
+		}
+		for p[j0] != 0 {
+			used[j0] = true
+			i0, delta, j1 := p[j0], math.MaxInt, 0
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+				if cur := at(i0-1, j-1) - u[i0] - v[j]; cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+	result := make([]int, n)
+	for i := range result {
+		result[i] = -1
+	}
+	for j := 1; j <= m; j++ {
+		if p[j] != 0 && j-1 < len(cost[p[j]-1]) {
+			result[p[j]-1] = j - 1
+		}
+	}
+	return result
+}
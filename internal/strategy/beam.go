@@ -0,0 +1,222 @@
+package strategy
+
+import (
+	"math"
+	"sort"
+
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// Walk of a pac over the next turns kept by the beam search: the cells from
+// the pac's cell on, one or two per turn, and their score
+type Beam struct {
+	Cells []*grid.Cell
+	// Discounted points collected less the risk taken
+	Collected float64
+	// Collected less the progress lost towards the plan target
+	Score float64
+}
+
+// Check if the walk passed cell before its last step
+func (b *Beam) visited(cell *grid.Cell) bool {
+	for _, c := range b.Cells[:len(b.Cells)-1] {
+		if c == cell {
+			return true
+		}
+	}
+	return false
+}
+
+// Turns the closest visible opponent pac needs to reach cell, -1 when none can
+func (g *Bot) enemyArrival(cell *grid.Cell) int {
+	arrival := -1
+	for _, enemy := range g.VisibleEnemies() {
+		d, ok := g.Dist.Between(grid.GetCell(enemy.X, enemy.Y, g.Grid), cell)
+		if !ok {
+			continue
+		}
+		if turns := enemy.TurnsFor(d); arrival < 0 || turns < arrival {
+			arrival = turns
+		}
+	}
+	return arrival
+}
+
+// Check if a visible opponent pac reaches cell within turns, ties included
+func (g *Bot) raced(cell *grid.Cell, turns int) bool {
+	arrival := g.enemyArrival(cell)
+	return arrival >= 0 && arrival <= turns
+}
+
+// Points pac collects entering cell on the given turn of walk b: the
+// pellet there unless another pac targets it or walks over it or the walk
+// ate it before, discounted by the turn, and a super pellet only when the
+// pac wins the race to it against the visible opponent pacs, half of it on
+// a tie and nothing with a safe lead. The risk of meeting an opponent pac on the cell
+// is taken off.
+func (g *Bot) cellGain(pac *state.Pac, b *Beam, cell *grid.Cell, turn int) float64 {
+	gain := -g.Risk[cell] * g.Params.BeamRiskCost
+	if !g.Pellet.Has(cell.X, cell.Y) {
+		return gain
+	}
+	pallet := g.Pellet.At(cell.X, cell.Y)
+	if pallet.Value == 0 || b.visited(cell) {
+		return gain
+	}
+	if g.Reservations.TakenFrom(pallet, pac.Id) {
+		return gain
+	}
+	if owner, ok := g.Walked[pallet]; ok && owner != pac.Id {
+		return gain
+	}
+	value := float64(pallet.Value)
+	if pallet.Value > 1 && g.Mode == ModeSafe && g.raced(cell, turn) {
+		value = 0
+	} else if pallet.Value > 1 {
+		switch arrival := g.enemyArrival(cell); {
+		case arrival < 0 || arrival > turn:
+		case arrival == turn:
+			value /= 2
+		default:
+			value = 0
+		}
+	}
+	return gain + value*math.Pow(g.Params.BeamDiscount, float64(turn-1))
+}
+
+// Score of a walk ending on its last cell: the points collected less
+// BeamProgressCost per step from there to target, if any
+func (g *Bot) scoreBeam(b *Beam, target *grid.Cell) {
+	b.Score = b.Collected
+	if target == nil {
+		return
+	}
+	if d, ok := g.Dist.Between(b.Cells[len(b.Cells)-1], target); ok {
+		b.Score -= float64(d) * g.Params.BeamProgressCost
+	}
+}
+
+// Extend walk b by steps cells on the given turn, never through the cells in
+// blocked
+func (g *Bot) extendBeam(pac *state.Pac, b *Beam, steps, turn int, blocked grid.Bitboard) []*Beam {
+	if steps == 0 {
+		return []*Beam{b}
+	}
+	var extended []*Beam
+	for _, neighbor := range b.Cells[len(b.Cells)-1].Neighbors {
+		if neighbor.IsWall || blocked.Has(neighbor.X, neighbor.Y) {
+			continue
+		}
+		next := &Beam{Cells: append(append([]*grid.Cell{}, b.Cells...), neighbor), Collected: b.Collected}
+		next.Collected += g.cellGain(pac, next, neighbor, turn)
+		extended = append(extended, g.extendBeam(pac, next, steps-1, turn, blocked)...)
+	}
+	return extended
+}
+
+// Search the walks of pac over the next BeamDepth turns, keeping the
+// BeamWidth best per turn with one walk per end cell, and return the best
+// one; nil when the pac cannot move. Walks are scored by the points they
+// collect and, when target is set, how close they end to it. Walks keep off
+// the cells of my other pacs and of the opponent pacs in sight the pac
+// cannot eat, and off every cell those opponents may move onto next. The
+// search stops at the depth reached when the turn budget runs low.
+func (g *Bot) BeamSearch(pac *state.Pac, target *grid.Cell) *Beam {
+	blocked := grid.NewBitboard(g.Width, g.Height)
+	for _, other := range g.MyPacs {
+		if other != pac {
+			blocked.Set(other.X, other.Y)
+		}
+	}
+	for _, enemy := range g.VisibleEnemies() {
+		// a walk into one bounces off it or is eaten
+		if state.Matchup(pac.TypeId, enemy.TypeId) == 1 {
+			continue
+		}
+		for cell := range g.EnemyMoves(enemy) {
+			blocked.Set(cell.X, cell.Y)
+		}
+	}
+	beams := []*Beam{{Cells: []*grid.Cell{grid.GetCell(pac.X, pac.Y, g.Grid)}}}
+	var best *Beam
+	for turn := 1; turn <= g.Params.BeamDepth; turn++ {
+		if g.Budget.Low() {
+			logger.Info("Beam search of pac", pac.Id, "out of time at turn", turn)
+			break
+		}
+		steps := 1
+		if turn <= pac.SpeedTurnsLeft {
+			steps = 2
+		}
+		var next []*Beam
+		for _, b := range beams {
+			next = append(next, g.extendBeam(pac, b, steps, turn, blocked)...)
+		}
+		for _, b := range next {
+			g.scoreBeam(b, target)
+		}
+		sort.SliceStable(next, func(a, b int) bool {
+			return next[a].Score > next[b].Score
+		})
+		ends := make(map[*grid.Cell]bool)
+		beams = beams[:0]
+		for _, b := range next {
+			end := b.Cells[len(b.Cells)-1]
+			if ends[end] {
+				continue
+			}
+			ends[end] = true
+			beams = append(beams, b)
+			if len(beams) == g.Params.BeamWidth {
+				break
+			}
+		}
+		if len(beams) == 0 {
+			break
+		}
+		best = beams[0]
+	}
+	return best
+}
+
+// Score path, which starts at the pac, walked as far as walk b reaches, the
+// same way the beam search scores its walks
+func (g *Bot) scorePath(pac *state.Pac, path []*grid.Cell, b *Beam, target *grid.Cell) float64 {
+	if len(path) > len(b.Cells) {
+		path = path[:len(b.Cells)]
+	}
+	walk := &Beam{Cells: path[:1]}
+	for i, cell := range path[1:] {
+		walk.Cells = path[:i+2]
+		turn := pac.TurnsFor(i + 1)
+		walk.Collected += g.cellGain(pac, walk, cell, turn)
+	}
+	g.scoreBeam(walk, target)
+	return walk.Score
+}
+
+// Steer pac off its planned path through the best walk of the beam search
+// when the walk collects at least BeamMinGain points more on the way to the
+// target. Returns the cell to move to this turn, nil to keep the plan.
+func (g *Bot) Steer(pac *state.Pac) *grid.Cell {
+	if g.Params.BeamDepth == 0 || pac.Plan == nil {
+		return nil
+	}
+	target := grid.GetCell(pac.Plan.Target.X, pac.Plan.Target.Y, g.Grid)
+	best := g.BeamSearch(pac, target)
+	if best == nil {
+		return nil
+	}
+	planned := g.scorePath(pac, pac.Plan.Path(g.Game, pac), best, target)
+	if best.Score < planned+g.Params.BeamMinGain {
+		return nil
+	}
+	via := best.Cells[pac.Reach(1)]
+	if !pac.Plan.SteerThrough(g.Game, best.Cells, via) {
+		return nil
+	}
+	logger.Log("Pac", pac.Id, "steers through", via.X, via.Y, "scoring", best.Score, "over", planned)
+	return via
+}
@@ -0,0 +1,82 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/grid"
+)
+
+func TestSteerThroughPellets(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#0     .#",
+		"# ##### #",
+		"#....   #",
+		"#########",
+	))
+	pac := fixture.Pac(bot.Game, 0)
+	pac.Plan = bot.NewPlan(pac, bot.Pellet.At(7, 1))
+	via := bot.Steer(pac)
+	if via == nil || via.X != 1 || via.Y != 2 {
+		t.Fatalf("got %v, want to steer through (1, 2)", via)
+	}
+	if x, y := pac.Plan.Goal(); x != 1 || y != 2 {
+		t.Errorf("plan goal (%d, %d), want (1, 2)", x, y)
+	}
+	if len(pac.Plan.Pellets) != 5 {
+		t.Errorf("plan expects %d pellets, want 5", len(pac.Plan.Pellets))
+	}
+}
+
+func TestSteerKeepsEmptyDetourOff(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#0     .#",
+		"# ##### #",
+		"#       #",
+		"#########",
+	))
+	pac := fixture.Pac(bot.Game, 0)
+	pac.Plan = bot.NewPlan(pac, bot.Pellet.At(7, 1))
+	if via := bot.Steer(pac); via != nil {
+		t.Fatalf("steered through (%d, %d) for nothing", via.X, via.Y)
+	}
+}
+
+func TestBeamLosesSuperPelletRace(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"########",
+		"#0  oa #",
+		"########",
+	))
+	pac := fixture.Pac(bot.Game, 0)
+	cell := bot.Grid[1][4]
+	walk := &Beam{Cells: []*grid.Cell{bot.Grid[1][1], bot.Grid[1][2], bot.Grid[1][3], cell}}
+	if gain := bot.cellGain(pac, walk, cell, 3); gain != 0 {
+		t.Errorf("super pellet the enemy reaches first scores %v", gain)
+	}
+	if gain := bot.cellGain(pac, walk, cell, 1); gain != 5 {
+		t.Errorf("super pellet reached on a tie scores %v, want 5", gain)
+	}
+}
+
+func TestBeamKeepsOutOfEnemyWay(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#0 ..a  #",
+		"# ##### #",
+		"#       #",
+		"#########",
+	))
+	// the enemy is of the pac's type, it can only bounce the pac
+	best := bot.BeamSearch(fixture.Pac(bot.Game, 0), nil)
+	if best == nil {
+		t.Fatal("no walk")
+	}
+	for _, cell := range best.Cells {
+		if cell.Y == 1 && cell.X >= 4 && cell.X <= 6 {
+			t.Fatalf("walk enters (%d, %d) the enemy may move onto", cell.X, cell.Y)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/budget"
+	"spring2020/internal/fixture"
+	"spring2020/internal/gameio"
+	"spring2020/internal/logger"
+)
+
+// Bot on the first turn of a contest size map, as after reading its input
+func contestBot() *Bot {
+	bot := NewBot(fixture.Game(fixture.ContestMaze(1)...))
+	bot.UpdateVisibility()
+	return bot
+}
+
+func BenchmarkClosestPellet(b *testing.B) {
+	bot := contestBot()
+	bot.Influence = bot.ComputeInfluence()
+	pac := fixture.Pac(bot.Game, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bot.GetClosestRegularPallet(pac)
+	}
+}
+
+// A whole turn of planning, which must stay well within the 50ms the
+// referee gives, with the deadline margin left to the input and output
+func BenchmarkPlayTurn(b *testing.B) {
+	logger.SetLevel(logger.LevelSilent)
+	defer logger.SetLevel(logger.DefaultLevel)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		bot := contestBot()
+		pub := gameio.NewPublisher(bot.MyPacs, bot.Width, bot.Height)
+		b.StartTimer()
+		bot.PlayTurn(pub, budget.NewTurnBudget(0))
+	}
+}
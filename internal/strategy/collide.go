@@ -0,0 +1,142 @@
+package strategy
+
+import (
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// Cells pac stands on after each of the two steps of the next turn under
+// command: its own cell while it uses an ability, else one cell towards the
+// target of its move and a second one while sped up
+func (g *Bot) forecast(pac *state.Pac, command gameio.Command) [2]*grid.Cell {
+	cell := grid.GetCell(pac.X, pac.Y, g.Grid)
+	var target *grid.Cell
+	switch c := command.(type) {
+	case gameio.Move:
+		target = grid.GetCell(c.X, c.Y, g.Grid)
+	case gameio.Wait:
+		target = grid.GetCell(c.X, c.Y, g.Grid)
+	default:
+		return [2]*grid.Cell{cell, cell}
+	}
+	first := g.StepToward(cell, target)
+	if pac.SpeedTurnsLeft == 0 {
+		return [2]*grid.Cell{first, first}
+	}
+	return [2]*grid.Cell{first, g.StepToward(first, target)}
+}
+
+// Check if two pacs starting on a and b and walking the forecasts fa and fb
+// land on the same cell or swap cells in one of the steps
+func collides(a, b *grid.Cell, fa, fb [2]*grid.Cell) bool {
+	prevA, prevB := a, b
+	for step := range fa {
+		if fa[step] == fb[step] || (fa[step] == prevB && fb[step] == prevA && fa[step] != prevA) {
+			return true
+		}
+		prevA, prevB = fa[step], fb[step]
+	}
+	return false
+}
+
+// Forecast where my pacs stand after each step of the next turn under their
+// commands, in the order of my pacs, and fix the commands that would make
+// two of them land on the same cell or swap cells, which the referee answers
+// by sending both back. The pac of lower lane priority, or else the later
+// one, gives way by stepping to the free neighbor closest to where it was
+// going or holding; the other one gives way when it cannot. Returns the
+// commands changed.
+func (g *Bot) ResolveCollisions(commands []gameio.Command) []gameio.Command {
+	pacs := make(map[int]*state.Pac, len(g.MyPacs))
+	for _, pac := range g.MyPacs {
+		pacs[pac.Id] = pac
+	}
+	var moved []*state.Pac
+	byPac := make(map[int]gameio.Command, len(commands))
+	forecasts := make(map[int][2]*grid.Cell, len(commands))
+	for _, command := range commands {
+		if pac := pacs[command.PacId()]; pac != nil {
+			moved = append(moved, pac)
+			byPac[pac.Id] = command
+			forecasts[pac.Id] = g.forecast(pac, command)
+		}
+	}
+	cellOf := func(pac *state.Pac) *grid.Cell {
+		return grid.GetCell(pac.X, pac.Y, g.Grid)
+	}
+	// first pac the forecast of pac would run into under the commands kept
+	conflict := func(pac *state.Pac, f [2]*grid.Cell) *state.Pac {
+		for _, other := range moved {
+			if other != pac && collides(cellOf(pac), cellOf(other), f, forecasts[other.Id]) {
+				return other
+			}
+		}
+		return nil
+	}
+	changed := make(map[int]bool)
+	for _, a := range moved {
+		b := conflict(a, forecasts[a.Id])
+		if b == nil {
+			continue
+		}
+		yielder, keeper := b, a
+		if lanePriority(b) > lanePriority(a) {
+			yielder, keeper = a, b
+		}
+		command := g.giveWay(yielder, byPac[yielder.Id], conflict)
+		if command == nil {
+			yielder, keeper = keeper, yielder
+			command = g.giveWay(yielder, byPac[yielder.Id], conflict)
+		}
+		if command == nil {
+			logger.Log("Pac", a.Id, "and pac", b.Id, "collide next turn, no way around")
+			continue
+		}
+		logger.Log("Pac", yielder.Id, "would collide with pac", keeper.Id, "next turn, plays", command, "instead")
+		byPac[yielder.Id] = command
+		forecasts[yielder.Id] = g.forecast(yielder, command)
+		changed[yielder.Id] = true
+	}
+	var fixed []gameio.Command
+	for _, pac := range moved {
+		if changed[pac.Id] {
+			fixed = append(fixed, byPac[pac.Id])
+		}
+	}
+	return fixed
+}
+
+// Command making pac give way: hold its cell or step to the neighbor
+// closest to where command was taking it, whichever is free of conflicts;
+// nil when pac is not moving or nothing is free
+func (g *Bot) giveWay(pac *state.Pac, command gameio.Command, conflict func(*state.Pac, [2]*grid.Cell) *state.Pac) gameio.Command {
+	move, ok := command.(gameio.Move)
+	if !ok {
+		return nil
+	}
+	goal := grid.GetCell(move.X, move.Y, g.Grid)
+	cell := grid.GetCell(pac.X, pac.Y, g.Grid)
+	var best gameio.Command
+	bestDist := -1
+	try := func(candidate gameio.Command, to *grid.Cell) {
+		if conflict(pac, g.forecast(pac, candidate)) != nil {
+			return
+		}
+		d, ok := g.Dist.Between(to, goal)
+		if !ok {
+			return
+		}
+		if best == nil || d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	for _, next := range cell.Neighbors {
+		if !next.IsWall {
+			try(gameio.Move{Pac: pac.Id, X: next.X, Y: next.Y}, next)
+		}
+	}
+	try(gameio.Wait{Pac: pac.Id, X: pac.X, Y: pac.Y}, cell)
+	return best
+}
@@ -0,0 +1,42 @@
+package strategy
+
+import (
+	"reflect"
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/gameio"
+)
+
+func TestResolveCollisionsHoldsLaterPac(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#####",
+		"#0 1#",
+		"##.##",
+		"#####",
+	))
+	commands := []gameio.Command{gameio.Move{Pac: 0, X: 2, Y: 1}, gameio.Move{Pac: 1, X: 2, Y: 1}}
+	want := []gameio.Command{gameio.Wait{Pac: 1, X: 3, Y: 1}}
+	if got := bot.ResolveCollisions(commands); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want pac 1 to hold", got)
+	}
+}
+
+func TestResolveCollisionsStepsAsideFromSwap(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#####",
+		"#01 #",
+		"# ###",
+		"#####",
+	))
+	commands := []gameio.Command{gameio.Move{Pac: 0, X: 2, Y: 1}, gameio.Move{Pac: 1, X: 1, Y: 1}}
+	want := []gameio.Command{gameio.Move{Pac: 1, X: 3, Y: 1}}
+	if got := bot.ResolveCollisions(commands); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want pac 1 to step aside", got)
+	}
+	// commands that keep the pacs apart are left alone
+	commands = []gameio.Command{gameio.Move{Pac: 0, X: 1, Y: 2}, gameio.Move{Pac: 1, X: 3, Y: 1}}
+	if got := bot.ResolveCollisions(commands); len(got) != 0 {
+		t.Errorf("got %v, want no change", got)
+	}
+}
@@ -0,0 +1,125 @@
+package strategy
+
+import (
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// Decide the combat action of pac against the visible opponent pacs. A pac
+// that cannot switch retreats from an enemy beating it within EvadeRadius
+// when its plan walks into the enemy's reach. The closest enemy within
+// DuelRadius is fought with the duel search; without
+// time for it, SWITCH to the counter of an enemy that would eat it next
+// turn, eat an enemy it beats that cannot switch away, or flee from one it
+// cannot counter. With a safe lead every opponent pac is avoided instead.
+// Returns the command, or nil to keep the planned one, and whether the pac
+// stands still for an ability.
+func (g *Bot) Fight(pac *state.Pac, planned gameio.Command) (gameio.Command, bool) {
+	if g.Mode == ModeSafe {
+		return g.avoid(pac), false
+	}
+	if retreat := g.evadeThreat(pac, planned); retreat != nil {
+		return retreat, false
+	}
+	if enemy := g.duelOpponent(pac); enemy != nil {
+		if command, idle, ok := g.Duel(pac, enemy, planned); ok {
+			return command, idle
+		}
+	}
+	for _, enemy := range g.VisibleEnemies() {
+		d, ok := g.StepsTo(enemy, pac.X, pac.Y)
+		if !ok {
+			continue
+		}
+		switch state.Matchup(pac.TypeId, enemy.TypeId) {
+		case -1:
+			if d > enemy.Reach(1)+1 {
+				continue
+			}
+			if pac.AbilityCooldown == 0 {
+				logger.Log("Pac", pac.Id, "switches against", enemy.Id)
+				return gameio.Switch{Pac: pac.Id, Type: state.Counter(enemy.TypeId)}, true
+			}
+			if away := g.escapeCell(pac, enemy); away != nil {
+				logger.Log("Pac", pac.Id, "flees from", enemy.Id, "to", away.X, away.Y)
+				return gameio.Move{Pac: pac.Id, X: away.X, Y: away.Y}, false
+			}
+		case 1:
+			if d <= pac.Reach(1) && !g.EnemyAbilityWithin(enemy, 0) {
+				logger.Log("Pac", pac.Id, "chases", enemy.Id)
+				return gameio.Move{Pac: pac.Id, X: enemy.X, Y: enemy.Y}, false
+			}
+		}
+	}
+	return nil, false
+}
+
+// Cell within a turn's reach of pac farthest from the enemy; nil when the
+// pac cannot gain distance
+func (g *Bot) flee(pac *state.Pac, enemy *state.Pac) *grid.Cell {
+	from := grid.GetCell(enemy.X, enemy.Y, g.Grid)
+	start := grid.GetCell(pac.X, pac.Y, g.Grid)
+	best, bestDist := start, -1
+	if d, ok := g.Dist.Between(from, start); ok {
+		bestDist = d
+	}
+	frontier := []*grid.Cell{start}
+	for step := 0; step < pac.Reach(1); step++ {
+		var next []*grid.Cell
+		for _, cell := range frontier {
+			for _, neighbor := range cell.Neighbors {
+				if neighbor.IsWall {
+					continue
+				}
+				next = append(next, neighbor)
+				if d, _ := g.Dist.Between(from, neighbor); d > bestDist {
+					best, bestDist = neighbor, d
+				}
+			}
+		}
+		frontier = next
+	}
+	if best == start {
+		return nil
+	}
+	return best
+}
+
+// Cell pac should end the turn on to get away from enemy: where it stays
+// out of the enemy's reach the longest, or the cell farthest from the enemy
+// within a turn's reach when the enemy may catch it anyway; nil when the
+// pac is best off where it is
+func (g *Bot) escapeCell(pac, enemy *state.Pac) *grid.Cell {
+	if g.Params.EvadeDepth > 0 {
+		if best, escape, stay := g.Evade(pac, enemy, grid.GetCell(pac.X, pac.Y, g.Grid)); best != nil && escape.turns == g.Params.EvadeDepth {
+			if !escape.better(stay) || (best.X == pac.X && best.Y == pac.Y) {
+				return nil
+			}
+			return best
+		}
+	}
+	return g.flee(pac, enemy)
+}
+
+// Move pac away from the closest visible opponent pac within ThreatRadius
+// steps whatever its type, as a pac eaten with a safe lead is the only way
+// left to lose; nil when none is close or there is nowhere farther to go
+func (g *Bot) avoid(pac *state.Pac) gameio.Command {
+	var closest *state.Pac
+	closestDist := g.Params.ThreatRadius + 1
+	for _, enemy := range g.VisibleEnemies() {
+		if d, ok := g.StepsTo(enemy, pac.X, pac.Y); ok && d < closestDist {
+			closest, closestDist = enemy, d
+		}
+	}
+	if closest == nil {
+		return nil
+	}
+	if away := g.escapeCell(pac, closest); away != nil {
+		logger.Log("Pac", pac.Id, "avoids", closest.Id, "to", away.X, away.Y)
+		return gameio.Move{Pac: pac.Id, X: away.X, Y: away.Y}
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package strategy
+
+import (
+	"math"
+
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/protocol"
+	"spring2020/internal/state"
+)
+
+// Pac as the duel search simulates it
+type duelist struct {
+	cell     *grid.Cell
+	typeId   string
+	speed    int
+	cooldown int
+}
+
+// Action of a pac in a duel: walk path, one cell per step, switch type or
+// activate SPEED; holding position is the empty action
+type duelAction struct {
+	path     []*grid.Cell
+	switchTo string
+	speed    bool
+}
+
+// Duelist of pac, with the speed and cooldown counted down since it was
+// last seen
+func newDuelist(g *state.Game, pac *state.Pac) duelist {
+	return duelist{grid.GetCell(pac.X, pac.Y, g.Grid), pac.TypeId, g.EnemySpeedLeft(pac), g.EnemyCooldown(pac)}
+}
+
+// Actions of d: holding, walking one cell or two while sped up, and the
+// abilities once its cooldown is over
+func duelActions(d duelist) []duelAction {
+	actions := []duelAction{{}}
+	for _, first := range d.cell.Neighbors {
+		if first.IsWall {
+			continue
+		}
+		actions = append(actions, duelAction{path: []*grid.Cell{first}})
+		if d.speed == 0 {
+			continue
+		}
+		for _, second := range first.Neighbors {
+			if !second.IsWall && second != d.cell {
+				actions = append(actions, duelAction{path: []*grid.Cell{first, second}})
+			}
+		}
+	}
+	if d.cooldown == 0 {
+		for _, t := range protocol.PacTypes {
+			if t != d.typeId {
+				actions = append(actions, duelAction{switchTo: t})
+			}
+		}
+		actions = append(actions, duelAction{speed: true})
+	}
+	return actions
+}
+
+// Apply action a to d
+func (d *duelist) use(a duelAction) {
+	switch {
+	case a.switchTo != "":
+		d.typeId = a.switchTo
+		d.cooldown = state.AbilityCooldown
+	case a.speed:
+		d.speed = state.SpeedDuration
+		d.cooldown = state.AbilityCooldown
+	}
+}
+
+// Play one simultaneous turn of the duel the way the referee does: abilities
+// first, then each step moves the walking pacs, sends back pacs of the same
+// type that met and ends the duel when one eats the other. Returns 1 when my
+// pac eats the enemy, -1 when it is eaten and 0 when both are alive.
+func duelTurn(me, enemy *duelist, mine, theirs duelAction) int {
+	me.use(mine)
+	enemy.use(theirs)
+	for step := 0; step < 2; step++ {
+		fromMe, fromEnemy := me.cell, enemy.cell
+		if step < len(mine.path) {
+			me.cell = mine.path[step]
+		}
+		if step < len(theirs.path) {
+			enemy.cell = theirs.path[step]
+		}
+		if me.cell == enemy.cell || (me.cell == fromEnemy && enemy.cell == fromMe) {
+			if outcome := state.Matchup(me.typeId, enemy.typeId); outcome != 0 {
+				return outcome
+			}
+			me.cell, enemy.cell = fromMe, fromEnemy
+		}
+	}
+	for _, d := range []*duelist{me, enemy} {
+		if d.speed > 0 {
+			d.speed--
+		}
+		if d.cooldown > 0 {
+			d.cooldown--
+		}
+	}
+	return 0
+}
+
+// Value of a duel position when the search stops: pressure on the pac whose
+// type is beaten and who cannot switch away from it
+func (g *Bot) duelEval(me, enemy duelist) float64 {
+	d, ok := g.Dist.Between(me.cell, enemy.cell)
+	if !ok {
+		return 0
+	}
+	switch state.Matchup(me.typeId, enemy.typeId) {
+	case 1:
+		if enemy.cooldown > 0 {
+			return g.Params.DuelPressure / float64(1+d)
+		}
+	case -1:
+		if me.cooldown > 0 {
+			return -g.Params.DuelPressure / float64(1+d)
+		}
+	}
+	return 0
+}
+
+// Worst value of my action against every enemy answer, looking depth turns
+// ahead, or -Inf as soon as it is no better than floor
+func (g *Bot) duelWorst(me, enemy duelist, mine duelAction, depth int, floor float64) float64 {
+	worst := math.Inf(1)
+	for _, theirs := range duelActions(enemy) {
+		m, e := me, enemy
+		v := float64(duelTurn(&m, &e, mine, theirs))
+		if v == 0 {
+			v = g.duelValue(m, e, depth-1)
+		}
+		if v < worst {
+			worst = v
+		}
+		if worst <= floor {
+			return math.Inf(-1)
+		}
+	}
+	return worst
+}
+
+// Expected value of my action against the enemy taking each type with the
+// odds the opponent model gives, playing the worst answer leading to that
+// type: the switch to it, or any answer keeping its type
+func (g *Bot) duelExpected(me, enemy duelist, mine duelAction, odds map[string]float64) float64 {
+	worst := make(map[string]float64)
+	for _, theirs := range duelActions(enemy) {
+		m, e := me, enemy
+		v := float64(duelTurn(&m, &e, mine, theirs))
+		if v == 0 {
+			v = g.duelValue(m, e, g.Params.DuelDepth-1)
+		}
+		if w, ok := worst[e.typeId]; !ok || v < w {
+			worst[e.typeId] = v
+		}
+	}
+	// summed in a fixed order, float addition over map order would change
+	// the last bits between runs and break replays
+	expected := 0.0
+	for _, t := range protocol.PacTypes {
+		expected += odds[t] * worst[t]
+	}
+	return expected
+}
+
+// Zobrist hash of a duel position
+func (g *Bot) duelHash(me, enemy duelist) uint64 {
+	z := g.Zobrist
+	return z.Pac(true, me.typeId, me.cell) ^ z.Abilities(true, me.speed, me.cooldown) ^
+		z.Pac(false, enemy.typeId, enemy.cell) ^ z.Abilities(false, enemy.speed, enemy.cooldown)
+}
+
+// Value of a duel position for my pac, maximizing over my actions the worst
+// enemy answer, as the enemy sees my action only after picking its own.
+// Positions reached again by other orders of moves are looked up in the
+// transposition table.
+func (g *Bot) duelValue(me, enemy duelist, depth int) float64 {
+	if depth == 0 || g.Budget.Low() {
+		return g.duelEval(me, enemy)
+	}
+	var hash uint64
+	if g.Transpositions != nil {
+		hash = g.duelHash(me, enemy)
+		if v, ok := g.Transpositions.Lookup(hash, depth); ok {
+			return v
+		}
+	}
+	best := math.Inf(-1)
+	for _, mine := range duelActions(me) {
+		if v := g.duelWorst(me, enemy, mine, depth, best); v > best {
+			best = v
+		}
+	}
+	// a search cut short by the budget is not worth keeping
+	if !g.Budget.Low() {
+		g.Transpositions.Store(hash, depth, best)
+	}
+	return best
+}
+
+// Closest visible opponent pac within DuelRadius steps of pac, nil if none
+func (g *Bot) duelOpponent(pac *state.Pac) *state.Pac {
+	var closest *state.Pac
+	closestDist := g.Params.DuelRadius + 1
+	for _, enemy := range g.VisibleEnemies() {
+		if d, ok := g.StepsTo(enemy, pac.X, pac.Y); ok && d < closestDist {
+			closest, closestDist = enemy, d
+		}
+	}
+	return closest
+}
+
+// Duel action of the command planned for pac: the walk of its first steps
+// towards the cell it moves to, the ability it uses or holding position
+func (g *Bot) plannedAction(me duelist, planned gameio.Command) duelAction {
+	var goal *grid.Cell
+	switch c := planned.(type) {
+	case gameio.Switch:
+		return duelAction{switchTo: c.Type}
+	case gameio.Speed:
+		return duelAction{speed: true}
+	case gameio.Move:
+		goal = grid.GetCell(c.X, c.Y, g.Grid)
+	}
+	if goal == nil || goal == me.cell {
+		return duelAction{}
+	}
+	best, bestDist := duelAction{}, -1
+	for _, a := range duelActions(me) {
+		if a.path == nil {
+			continue
+		}
+		d, ok := g.Dist.Between(a.path[len(a.path)-1], goal)
+		// prefer the longest walk among the closest ends, a fast pac moves twice
+		if ok && (bestDist < 0 || d < bestDist || (d == bestDist && len(a.path) > len(best.path))) {
+			best, bestDist = a, d
+		}
+	}
+	return best
+}
+
+// Resolve the duel of pac against enemy with a DuelDepth turn maximin
+// search, weighing the enemy's first answers by the types it may switch to
+// as its switch history predicts. Returns the command, nil to keep the planned command when it is
+// as good as anything else, whether the pac stands still for an ability,
+// and false when the turn budget ran too low to search.
+func (g *Bot) Duel(pac, enemy *state.Pac, planned gameio.Command) (gameio.Command, bool, bool) {
+	if g.Params.DuelDepth == 0 || g.Budget.Low() {
+		return nil, false, false
+	}
+	if g.Transpositions == nil && g.Zobrist != nil {
+		g.Transpositions = state.NewTranspositionTable(g.Params.TableBits)
+	}
+	g.Transpositions.NextGeneration()
+	me, them := newDuelist(g.Game, pac), newDuelist(g.Game, enemy)
+	odds := g.PredictSwitch(enemy, pac.TypeId)
+	planValue := g.duelExpected(me, them, g.plannedAction(me, planned), odds)
+	var best duelAction
+	bestValue := math.Inf(-1)
+	for _, mine := range duelActions(me) {
+		if g.Budget.Low() {
+			break
+		}
+		if v := g.duelExpected(me, them, mine, odds); v > bestValue {
+			best, bestValue = mine, v
+		}
+	}
+	logger.Log("Pac", pac.Id, "duels", enemy.Id, "for", bestValue, "plan", planValue, "odds", odds)
+	switch {
+	case planValue >= bestValue:
+		return nil, false, true
+	case best.switchTo != "":
+		return gameio.Switch{Pac: pac.Id, Type: best.switchTo}, true, true
+	case best.speed:
+		return gameio.Speed{Pac: pac.Id}, true, true
+	case best.path == nil:
+		return gameio.Wait{Pac: pac.Id, X: pac.X, Y: pac.Y}, false, true
+	}
+	end := best.path[len(best.path)-1]
+	return gameio.Move{Pac: pac.Id, X: end.X, Y: end.Y}, false, true
+}
@@ -0,0 +1,103 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/gameio"
+)
+
+func TestDuelSwitchesAgainstThreat(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#######",
+		"#0a   #",
+		"#######",
+	))
+	pac, enemy := fixture.Pac(bot.Game, 0), bot.OpponentPacs[0]
+	enemy.TypeId, enemy.AbilityCooldown = "PAPER", 4
+	command, idle, ok := bot.Duel(pac, enemy, gameio.Move{Pac: 0, X: 5, Y: 1})
+	if !ok || !idle {
+		t.Fatalf("got %v idle %v ok %v, want a switch", command, idle, ok)
+	}
+	if command != (gameio.Switch{Pac: 0, Type: "SCISSORS"}) {
+		t.Errorf("got %v, want SWITCH 0 SCISSORS", command)
+	}
+}
+
+func TestDuelCornersEnemy(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"########",
+		"#a 0   #",
+		"########",
+	))
+	pac, enemy := fixture.Pac(bot.Game, 0), bot.OpponentPacs[0]
+	enemy.TypeId, enemy.AbilityCooldown = "SCISSORS", 4
+	command, _, ok := bot.Duel(pac, enemy, gameio.Move{Pac: 0, X: 6, Y: 1})
+	if !ok || command != (gameio.Move{Pac: 0, X: 2, Y: 1}) {
+		t.Errorf("got %v, want MOVE 0 2 1 into the dead end", command)
+	}
+}
+
+func TestDuelKeepsPlanWhenSafe(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#0 a    #",
+		"#########",
+	))
+	pac, enemy := fixture.Pac(bot.Game, 0), bot.OpponentPacs[0]
+	pac.AbilityCooldown, enemy.AbilityCooldown = 4, 4
+	if command, _, ok := bot.Duel(pac, enemy, gameio.Wait{Pac: 0, X: 1, Y: 1}); !ok || command != nil {
+		t.Errorf("got %v, want to keep the plan against a blocking type", command)
+	}
+}
+
+func TestDuelFollowsSwitchHistory(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#######",
+		"#   0a#",
+		"#######",
+	))
+	pac, enemy := fixture.Pac(bot.Game, 0), bot.OpponentPacs[0]
+	enemy.TypeId = "SCISSORS"
+	pac.AbilityCooldown = 4
+	attack := gameio.Move{Pac: 0, X: 5, Y: 1}
+	// an enemy that countered in every contact is expected to counter again
+	enemy.Contacts, enemy.Switches, enemy.Counters = 30, 30, 30
+	if command, _, _ := bot.Duel(pac, enemy, gameio.Wait{Pac: 0, X: 4, Y: 1}); command == attack {
+		t.Error("attacks an enemy that always switches to paper")
+	}
+	// one that held its type in every contact is expected to hold again
+	enemy.Switches, enemy.Counters = 0, 0
+	command, _, ok := bot.Duel(pac, enemy, gameio.Wait{Pac: 0, X: 4, Y: 1})
+	if !ok || command != attack {
+		t.Errorf("got %v, want MOVE 0 5 1 onto the scissors", command)
+	}
+}
+
+func TestDuelTranspositionsKeepTheAnswer(t *testing.T) {
+	maze := []string{
+		"#########",
+		"#   #   #",
+		"# 0   a #",
+		"#   #   #",
+		"#########",
+	}
+	var answers []gameio.Command
+	for _, bits := range []int{0, 10} {
+		bot := NewBot(fixture.Game(maze...))
+		bot.Params.DuelDepth, bot.Params.TableBits = 3, bits
+		pac, enemy := fixture.Pac(bot.Game, 0), bot.OpponentPacs[0]
+		enemy.TypeId = "SCISSORS"
+		command, _, ok := bot.Duel(pac, enemy, gameio.Wait{Pac: 0, X: 2, Y: 2})
+		if !ok {
+			t.Fatal("duel not searched")
+		}
+		if bits > 0 && bot.Transpositions.Hits == 0 {
+			t.Error("no position looked up again")
+		}
+		answers = append(answers, command)
+	}
+	if answers[0] != answers[1] {
+		t.Errorf("got %v with the table, %v without", answers[1], answers[0])
+	}
+}
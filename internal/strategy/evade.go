@@ -0,0 +1,173 @@
+package strategy
+
+import (
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// How well a retreat keeps a pac away from an enemy: the turns it stays out
+// of the enemy's reach and its distance from the enemy's cell by then
+type escape struct {
+	turns, dist int
+}
+
+// Check if e keeps the pac clear longer than o, or as long and farther
+func (e escape) better(o escape) bool {
+	return e.turns > o.turns || (e.turns == o.turns && e.dist > o.dist)
+}
+
+// Most steps enemy may have walked after turns turns: at its speed, or
+// activating SPEED on some turn once its cooldown is over, which takes that
+// turn's move
+func (g *Bot) enemySteps(enemy *state.Pac, turns int) int {
+	speed := g.EnemySpeedLeft(enemy)
+	most := turns + grid.MinInt(turns, speed)
+	for at := g.EnemyCooldown(enemy); at < turns; at++ {
+		after := turns - at - 1
+		if steps := at + grid.MinInt(at, speed) + after + grid.MinInt(after, state.SpeedDuration); steps > most {
+			most = steps
+		}
+	}
+	return most
+}
+
+// Retreat planner of one pac from one enemy over EvadeDepth turns
+type evasion struct {
+	g     *Bot
+	enemy *grid.Cell
+	// steps the enemy may have walked after each turn
+	reach []int
+	memo  map[[2]int]escape
+}
+
+// Cells pac may end the next turn on with the cells walked on the way:
+// holding, one step, or two steps the way the referee walks them while sped
+func (g *Bot) evasionMoves(cell *grid.Cell, sped bool) [][]*grid.Cell {
+	moves := [][]*grid.Cell{{cell}}
+	seen := map[*grid.Cell]bool{cell: true}
+	for _, first := range cell.Neighbors {
+		if first.IsWall {
+			continue
+		}
+		moves = append(moves, []*grid.Cell{first})
+		seen[first] = true
+	}
+	if !sped {
+		return moves
+	}
+	for _, move := range moves[1:] {
+		for _, second := range move[0].Neighbors {
+			if second.IsWall || seen[second] {
+				continue
+			}
+			seen[second] = true
+			moves = append(moves, []*grid.Cell{g.StepToward(cell, second), second})
+		}
+	}
+	return moves
+}
+
+// Turns of SPEED left a turn after speed
+func slower(speed int) int {
+	if speed > 0 {
+		return speed - 1
+	}
+	return 0
+}
+
+// Check if the cells walked in turn, counted from 1, stay out of the
+// enemy's reach by then
+func (e *evasion) clear(cells []*grid.Cell, turn int) bool {
+	for _, cell := range cells {
+		if d, ok := e.g.Dist.Between(e.enemy, cell); ok && d <= e.reach[turn] {
+			return false
+		}
+	}
+	return true
+}
+
+// Best escape of a pac standing on cell after turn turns with speed turns
+// of SPEED left
+func (e *evasion) from(cell *grid.Cell, turn, speed int) escape {
+	dist, _ := e.g.Dist.Between(e.enemy, cell)
+	best := escape{turn, dist}
+	if turn == len(e.reach)-1 {
+		return best
+	}
+	key := [2]int{cell.Y*e.g.Width + cell.X, turn}
+	if cached, ok := e.memo[key]; ok {
+		return cached
+	}
+	for _, move := range e.g.evasionMoves(cell, speed > 0) {
+		if !e.clear(move, turn+1) {
+			continue
+		}
+		if next := e.from(move[len(move)-1], turn+1, slower(speed)); next.better(best) {
+			best = next
+		}
+	}
+	e.memo[key] = best
+	return best
+}
+
+// Cell pac should end this turn on to stay out of the reach of enemy the
+// longest over EvadeDepth turns, counting a SPEED the enemy may activate,
+// with how long and how far that keeps it clear. Also returns the escape of
+// ending the turn on planned, zero when planned is not clear.
+func (g *Bot) Evade(pac, enemy *state.Pac, planned *grid.Cell) (*grid.Cell, escape, escape) {
+	e := &evasion{g: g, enemy: grid.GetCell(enemy.X, enemy.Y, g.Grid), memo: make(map[[2]int]escape)}
+	e.reach = make([]int, g.Params.EvadeDepth+1)
+	for t := range e.reach {
+		e.reach[t] = g.enemySteps(enemy, t)
+	}
+	start := grid.GetCell(pac.X, pac.Y, g.Grid)
+	speed := pac.SpeedTurnsLeft
+	var best *grid.Cell
+	bestEscape, plannedEscape := escape{-1, 0}, escape{}
+	for _, move := range g.evasionMoves(start, speed > 0) {
+		end := move[len(move)-1]
+		if !e.clear(move, 1) {
+			continue
+		}
+		result := e.from(end, 1, slower(speed))
+		if end == planned {
+			plannedEscape = result
+		}
+		if result.better(bestEscape) {
+			best, bestEscape = end, result
+		}
+	}
+	return best, bestEscape, plannedEscape
+}
+
+// Retreat of pac from the closest visible opponent pac beating it within
+// EvadeRadius steps when it cannot switch to the counter: keep the planned
+// command while it stays clear of the enemy's reach for EvadeDepth turns,
+// else move where the pac stays clear the longest. Nil when nothing
+// threatens it or the plan is as good as any retreat.
+func (g *Bot) evadeThreat(pac *state.Pac, planned gameio.Command) gameio.Command {
+	if pac.AbilityCooldown == 0 || g.Params.EvadeDepth == 0 {
+		return nil
+	}
+	var threat *state.Pac
+	closest := g.Params.EvadeRadius + 1
+	for _, enemy := range g.VisibleEnemies() {
+		if d, ok := g.StepsTo(enemy, pac.X, pac.Y); ok && d < closest && state.Matchup(enemy.TypeId, pac.TypeId) == 1 {
+			threat, closest = enemy, d
+		}
+	}
+	if threat == nil {
+		return nil
+	}
+	best, bestEscape, plannedEscape := g.Evade(pac, threat, g.forecast(pac, planned)[1])
+	if best == nil || plannedEscape.turns == g.Params.EvadeDepth || !bestEscape.better(plannedEscape) {
+		return nil
+	}
+	logger.Log("Pac", pac.Id, "evades", threat.Id, "to", best.X, best.Y, "clear for", bestEscape.turns, "turns")
+	if best.X == pac.X && best.Y == pac.Y {
+		return gameio.Wait{Pac: pac.Id, X: pac.X, Y: pac.Y}
+	}
+	return gameio.Move{Pac: pac.Id, X: best.X, Y: best.Y}
+}
@@ -0,0 +1,37 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/gameio"
+)
+
+func TestEvadeLeavesPlanIntoDeadEnd(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"###########",
+		"#    0 a  #",
+		"#####.#####",
+		"#####.#####",
+		"###########",
+	))
+	pac, enemy := fixture.Pac(bot.Game, 0), bot.OpponentPacs[0]
+	pac.AbilityCooldown = 5
+	enemy.TypeId, enemy.AbilityCooldown = "PAPER", 10
+	if got := bot.enemySteps(enemy, 3); got != 3 {
+		t.Errorf("enemy on cooldown walks %d steps in 3 turns, want 3", got)
+	}
+	command, _ := bot.Fight(pac, gameio.Move{Pac: 0, X: 5, Y: 3})
+	if command != (gameio.Move{Pac: 0, X: 4, Y: 1}) {
+		t.Errorf("got %v, want MOVE 0 4 1 away from the dead end", command)
+	}
+	// a plan that keeps out of reach is left alone
+	if command, _ := bot.Fight(pac, gameio.Move{Pac: 0, X: 1, Y: 1}); command != nil {
+		t.Errorf("got %v, want the plan kept", command)
+	}
+	// an enemy able to SPEED may walk twice as far once it is active
+	enemy.AbilityCooldown = 0
+	if got := bot.enemySteps(enemy, 3); got != 4 {
+		t.Errorf("enemy able to speed walks %d steps in 3 turns, want 4", got)
+	}
+}
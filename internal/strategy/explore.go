@@ -0,0 +1,39 @@
+package strategy
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/state"
+)
+
+// Cell pac explores when it knows no pellet to go for: the reachable floor
+// cell out of sight for the most turns, cells never seen counting from the
+// start of the game, less a turn per step to walk there. Cells within
+// ExploreSpacing steps of one claimed by another pac are left out. Returns
+// nil when there is nothing left to explore.
+func (g *Bot) ExploreTarget(pac *state.Pac, claimed []*grid.Cell) *grid.Cell {
+	var best *grid.Cell
+	bestScore := 0
+	start := grid.GetCell(pac.X, pac.Y, g.Grid)
+	for _, row := range g.Grid {
+	cells:
+		for _, cell := range row {
+			if cell.IsWall || g.Visible.Has(cell.X, cell.Y) {
+				continue
+			}
+			d, ok := g.Dist.Between(start, cell)
+			if !ok || d == 0 {
+				continue
+			}
+			for _, other := range claimed {
+				if near, ok := g.Dist.Between(other, cell); ok && near < g.Params.ExploreSpacing {
+					continue cells
+				}
+			}
+			score := g.Turn - g.LastSeen[cell] - d
+			if best == nil || score > bestScore {
+				best, bestScore = cell, score
+			}
+		}
+	}
+	return best
+}
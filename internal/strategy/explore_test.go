@@ -0,0 +1,38 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/grid"
+)
+
+func TestExploreTarget(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"###########",
+		"#0        #",
+		"# ####### #",
+		"#         #",
+		"###########",
+	))
+	bot.Turn = 30
+	bot.LastSeen = make(map[*grid.Cell]int)
+	for _, row := range bot.Grid {
+		for _, cell := range row {
+			bot.LastSeen[cell] = 28
+		}
+	}
+	// the bottom right corner was never seen, the middle of the top row
+	// long ago
+	bot.LastSeen[bot.Grid[3][9]] = 0
+	bot.LastSeen[bot.Grid[1][5]] = 10
+	pac := fixture.Pac(bot.Game, 0)
+	first := bot.ExploreTarget(pac, nil)
+	if first != bot.Grid[3][9] {
+		t.Fatalf("got %v, want the never seen cell (9, 3)", first)
+	}
+	second := bot.ExploreTarget(pac, []*grid.Cell{first})
+	if second != bot.Grid[1][5] {
+		t.Fatalf("got %v, want (5, 1) away from the claimed cell", second)
+	}
+}
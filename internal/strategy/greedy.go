@@ -0,0 +1,164 @@
+package strategy
+
+import (
+	"time"
+
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// Strategy sharing out the free pellets among the pacs that replan and
+// walking every pac along the route to its target, steered off it through
+// the beam search when that collects more on the way
+type Greedy struct{}
+
+// Commands of my pacs this turn, see Strategy
+func (Greedy) ComputeCommands(g *Bot) []gameio.Command {
+	// when ahead, deny the pellets the opponent is about to harvest
+	var denials []Denial
+	if g.Mode == ModeDeny {
+		denials = g.PredictEnemyHarvest()
+	}
+	detours, lanes := g.ResolveCorridorPassing()
+	g.Detours, g.Lanes = detours, lanes
+	g.Blocking = make(map[int]*grid.Cell)
+	defer func() {
+		g.Blocking = nil
+	}()
+
+	// decide who replans before anyone does, so the replanning pacs share
+	// out the free pellets in one assignment
+	triggers := make(map[int]state.ReplanTrigger)
+	rerouted := make(map[int]bool)
+	held := make(map[int]*state.Plan)
+	var replanning []*state.Pac
+	for _, pac := range g.MyPacs {
+		if g.Roles[pac.Id].Role != RoleCollector {
+			// pacs on another role drop their plan and replan once back to collecting
+			if pac.Plan != nil {
+				pac.Plan.Abandon(g.Game, pac)
+				pac.Plan = nil
+			}
+			continue
+		}
+		trigger := g.CheckReplan(pac, g.Invalidated[pac.Id])
+		triggers[pac.Id] = trigger
+		if trigger == state.TriggerBlocked && pac.Stuck < g.Params.StuckLimit && pac.Plan.Reroute(g.Game, pac) {
+			// try another way to the same target before giving it up
+			rerouted[pac.Id] = true
+			continue
+		}
+		if trigger == state.TriggerNone {
+			continue
+		}
+		replanning = append(replanning, pac)
+		old := pac.Plan
+		if old != nil && old.Reached(pac) {
+			old.Target.Value = 0
+			old.Abandon(g.Game, pac)
+			logger.Log("Pac", pac.Id, "ate pallet", old.Target.X, old.Target.Y)
+		} else if old != nil && trigger == state.TriggerBlocked {
+			// a blocked pac keeps its old target reserved until it picked
+			// another one, and picks none behind the cell it bounced off
+			held[pac.Id] = old
+			if len(old.Waypoints) > 0 {
+				g.Blocking[pac.Id] = old.Waypoints[0]
+			}
+		} else if old != nil {
+			old.Abandon(g.Game, pac)
+		}
+		pac.Plan = nil
+	}
+	assigned := g.AssignTargets(replanning)
+	// cells the pacs without a target explore, kept apart
+	var exploring []*grid.Cell
+	var commands []gameio.Command
+
+	for i, pac := range g.MyPacs {
+		if g.Budget.Low() || g.Pub.Expired() {
+			logger.Info("Out of time before pac", pac.Id, "after", g.Budget.Elapsed())
+			for _, rest := range g.MyPacs[i:] {
+				if old := held[rest.Id]; old != nil {
+					old.Abandon(g.Game, rest)
+				}
+				g.Pub.Update(g.Fallback(rest))
+				g.Pub.Label(rest.Id, "late")
+			}
+			break
+		}
+		pacStart := time.Now()
+		g.BeginDecision(pac)
+		logger.Log("Pac", pac.Id, "x", pac.X, "y", pac.Y, "type", pac.TypeId, "speed turns left", pac.SpeedTurnsLeft, "ability cooldown", pac.AbilityCooldown, "plan", pac.Plan)
+		trigger := triggers[pac.Id]
+		g.NoteTrigger(trigger)
+		var command gameio.Command
+		// pacs moving on to their plan target may leave the path for more pellets
+		steerable := false
+		if part := g.Roles[pac.Id]; part.Role != RoleCollector {
+			command = g.PlayRole(pac, part)
+		} else if rerouted[pac.Id] {
+			x, y := pac.Plan.Next(pac)
+			logger.Log("Pac", pac.Id, "blocked, rerouting via", x, y)
+			command = gameio.Move{Pac: pac.Id, X: x, Y: y}
+		} else if trigger != state.TriggerNone {
+			logger.Log("Pac", pac.Id, "replans:", trigger)
+			// pacs the assignment left out pick greedily
+			pallet := assigned[pac.Id]
+			if pallet != nil {
+				g.NoteCandidate("assigned", pallet, -1)
+			} else if pallet = g.GetClosestSuperPallet(pac); pallet == nil {
+				pallet = g.GetClosestRegularPallet(pac)
+			}
+			// a regular pellet may give way to a tour of the pellets nearby
+			if pallet != nil && pallet.Value == 1 && g.Params.TourStops >= MinTourStops {
+				pallet = g.TourTarget(pac, pallet, assigned)
+			}
+			if pallet != nil && pallet.Value == 1 && len(denials) > 0 && g.IsSafe(pac) {
+				closestDist, _ := g.StepsTo(pac, pallet.X, pallet.Y)
+				if denied := g.GetDenialPallet(pac, denials, closestDist); denied != nil {
+					logger.Log("Pac", pac.Id, "denying pellet", denied.X, denied.Y)
+					pallet = denied
+				}
+			}
+			if pallet != nil {
+				pac.Plan = g.NewPlan(pac, pallet)
+				x, y := pac.Plan.Next(pac)
+				command = gameio.Move{Pac: pac.Id, X: x, Y: y}
+				// walks of a blocked pac would lead it back into what blocks it
+				steerable = trigger != state.TriggerBlocked
+			} else if cell := g.ExploreTarget(pac, exploring); cell != nil {
+				logger.Log("Pac", pac.Id, "has no target, exploring", cell.X, cell.Y)
+				exploring = append(exploring, cell)
+				command = gameio.Move{Pac: pac.Id, X: cell.X, Y: cell.Y}
+			} else {
+				logger.Log("Pac", pac.Id, "has no target, holding")
+				command = gameio.Wait{Pac: pac.Id, X: pac.X, Y: pac.Y}
+			}
+			if old := held[pac.Id]; old != nil {
+				old.Abandon(g.Game, pac)
+			}
+		} else if detour, ok := detours[pac.Id]; ok {
+			pac.Plan.Execute(pac)
+			command = gameio.Move{Pac: pac.Id, X: detour.X, Y: detour.Y}
+		} else {
+			if !pac.Plan.Execute(pac) && !pac.Plan.Repair(g.Game, pac) {
+				logger.Log("Pac", pac.Id, "cannot reach", pac.Plan.Target.X, pac.Plan.Target.Y)
+			} else if cell := pac.Plan.Obstruction(g.Game, pac); cell != nil && pac.Plan.Unblock(g.Game, pac, cell) {
+				logger.Log("Pac", pac.Id, "routes around the pac on", cell.X, cell.Y)
+			}
+			pac.Plan.Aim(g.Game, pac)
+			x, y := pac.Plan.Next(pac)
+			command = gameio.Move{Pac: pac.Id, X: x, Y: y}
+			steerable = true
+		}
+		if steerable {
+			if via := g.Steer(pac); via != nil {
+				command = gameio.Move{Pac: pac.Id, X: via.X, Y: via.Y}
+			}
+		}
+		commands = append(commands, g.settle(pac, command, pacStart))
+	}
+	return commands
+}
@@ -0,0 +1,39 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/budget"
+	"spring2020/internal/fixture"
+	"spring2020/internal/gameio"
+	"spring2020/internal/protocol"
+)
+
+func TestGreedyTurnsFromBlockedCorridor(t *testing.T) {
+	// an enemy of the same type blocks the corridor to the target and the
+	// closest pellet left, the pac bounced off it for StuckLimit turns and
+	// no detour exists
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#.  0a.o#",
+		"#########",
+	))
+	pac := fixture.Pac(bot.Game, 0)
+	pac.AbilityCooldown = 5
+	pac.Plan = bot.NewPlan(pac, bot.Pellet.At(7, 1))
+	pac.LastX, pac.LastY = pac.X, pac.Y
+	pac.Stuck = bot.Params.StuckLimit - 1
+	pac.Threatened = true
+	pub := gameio.NewPublisher(bot.MyPacs, bot.Width, bot.Height)
+	bot.PlayTurn(pub, budget.NewTurnBudget(0))
+	commands, errs := protocol.ParseCommands(pub.Publish())
+	if len(errs) > 0 || len(commands) != 1 || commands[0].Verb != protocol.VerbMove {
+		t.Fatalf("published %v, errors %v", commands, errs)
+	}
+	if commands[0].X >= pac.X {
+		t.Errorf("moves to x %d, want away from the blocked corridor", commands[0].X)
+	}
+	if pac.Plan == nil || pac.Plan.Target.X > pac.X {
+		t.Errorf("plan %v, want a target on the open side", pac.Plan)
+	}
+}
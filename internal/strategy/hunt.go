@@ -0,0 +1,78 @@
+package strategy
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/state"
+)
+
+// Cells enemy reaches strictly before pac at their speeds, walking from its
+// cell through such cells only; false once they number more than TrapCells,
+// when the enemy has room to get away
+func (g *Bot) escapeRegion(pac, enemy *state.Pac) ([]*grid.Cell, bool) {
+	start := grid.GetCell(enemy.X, enemy.Y, g.Grid)
+	dist := map[*grid.Cell]int{start: 0}
+	region := []*grid.Cell{start}
+	for i := 0; i < len(region); i++ {
+		current := region[i]
+		for _, next := range current.Neighbors {
+			if _, seen := dist[next]; seen || next.IsWall {
+				continue
+			}
+			d := dist[current] + 1
+			mine, ok := g.StepsTo(pac, next.X, next.Y)
+			if ok && pac.TurnsFor(mine) <= enemy.TurnsFor(d) {
+				continue
+			}
+			if len(region) == g.Params.TrapCells {
+				return nil, false
+			}
+			dist[next] = d
+			region = append(region, next)
+		}
+	}
+	return region, true
+}
+
+// Check if enemy is cornered by pac: it reaches no more than TrapCells cells
+// before pac, so it cannot run past it. Returns where it would flee to, the
+// cell of its escape region farthest from pac, which pac heads for to cut
+// off its escape.
+func (g *Bot) Cornered(pac, enemy *state.Pac) (*grid.Cell, bool) {
+	region, ok := g.escapeRegion(pac, enemy)
+	if !ok {
+		return nil, false
+	}
+	var refuge *grid.Cell
+	farthest := -1
+	for _, cell := range region {
+		if d, ok := g.StepsTo(pac, cell.X, cell.Y); ok && d > farthest {
+			refuge, farthest = cell, d
+		}
+	}
+	return refuge, refuge != nil
+}
+
+// Check if enemy is in a dead-end branch pac is outside of and reaches the
+// mouth of before the enemy gets out
+func (g *Bot) Trapped(pac, enemy *state.Pac) bool {
+	cell := grid.GetCell(enemy.X, enemy.Y, g.Grid)
+	mouth := g.DeadEnds.Mouth(cell)
+	if mouth == nil || g.DeadEnds.Mouth(grid.GetCell(pac.X, pac.Y, g.Grid)) == mouth {
+		return false
+	}
+	d, ok := g.StepsTo(pac, mouth.X, mouth.Y)
+	return ok && pac.TurnsFor(d) <= enemy.TurnsFor(g.DeadEnds.Depth(cell))
+}
+
+// Cell a hunter heads for to catch a cornered prey: the refuge it flees to,
+// or none when the prey stands on the hunter's way there and is simply
+// walked onto
+func (g *Bot) intercept(pac, prey *state.Pac, refuge *grid.Cell) *grid.Cell {
+	toRefuge, ok := g.StepsTo(pac, refuge.X, refuge.Y)
+	toPrey, _ := g.StepsTo(pac, prey.X, prey.Y)
+	beyond, _ := g.Dist.Between(grid.GetCell(prey.X, prey.Y, g.Grid), refuge)
+	if !ok || toPrey+beyond == toRefuge {
+		return nil
+	}
+	return refuge
+}
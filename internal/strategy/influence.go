@@ -0,0 +1,51 @@
+package strategy
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/state"
+)
+
+// Spread the points of the known pellets over the cells within
+// InfluenceRadius steps, decaying by InfluenceDecay per step, so a cell's
+// influence tells how rich its surroundings are. The spreads of all pellets
+// share one seen bitboard and two frontier buffers.
+func (g *Bot) ComputeInfluence() map[*grid.Cell]float64 {
+	influence := make(map[*grid.Cell]float64)
+	seen := grid.NewBitboard(g.Width, g.Height)
+	var frontier, next []*grid.Cell
+	for _, pallet := range g.Pellet.Remaining(0) {
+		if pallet.Value == 0 {
+			continue
+		}
+		seen.Reset()
+		start := grid.GetCell(pallet.X, pallet.Y, g.Grid)
+		seen.Set(start.X, start.Y)
+		frontier = append(frontier[:0], start)
+		weight := float64(pallet.Value)
+		for step := 0; step <= g.Params.InfluenceRadius && len(frontier) > 0; step++ {
+			next = next[:0]
+			for _, cell := range frontier {
+				influence[cell] += weight
+				for _, neighbor := range cell.Neighbors {
+					if !neighbor.IsWall && !seen.Has(neighbor.X, neighbor.Y) {
+						seen.Set(neighbor.X, neighbor.Y)
+						next = append(next, neighbor)
+					}
+				}
+			}
+			frontier, next = next, frontier
+			weight *= g.Params.InfluenceDecay
+		}
+	}
+	return influence
+}
+
+// Steps saved in target scoring by the richness around pellet
+func (g *Bot) InfluenceAdjustment(pallet *state.Pellet) int {
+	return int(g.Influence[grid.GetCell(pallet.X, pallet.Y, g.Grid)] * g.Params.InfluenceWeight)
+}
+
+// Check if the surroundings of pellet a are richer than those of b
+func (g *Bot) richer(a, b *state.Pellet) bool {
+	return g.Influence[grid.GetCell(a.X, a.Y, g.Grid)] > g.Influence[grid.GetCell(b.X, b.Y, g.Grid)]
+}
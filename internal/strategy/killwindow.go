@@ -0,0 +1,127 @@
+package strategy
+
+import (
+	"spring2020/internal/budget"
+	"spring2020/internal/grid"
+	"spring2020/internal/state"
+)
+
+// Most turns a kill window is searched for
+const MaxKillWindow = 8
+
+// Position of a chase with the turns left to end it
+type chaseKey struct {
+	hunter, prey                     *grid.Cell
+	hunterSpeed, preySpeed, cooldown int
+	left                             int
+}
+
+// Chase of one opponent pac by one of my pacs played out in the simulator,
+// the two alone on a copy of the game
+type chase struct {
+	sim          *state.Game
+	hunter, prey *state.Pac
+	budget       *budget.TurnBudget
+	// proven outcomes by position
+	memo map[chaseKey]bool
+}
+
+// Turns within which pac catches prey whatever way the prey runs before
+// its ability is ready again, proven by playing every line of the chase
+// in the simulator; false when no catch within that horizon is certain.
+// The prey holds or walks, two cells while sped up, and my pac may also
+// activate SPEED.
+func (g *Bot) KillWindow(pac, prey *state.Pac) (int, bool) {
+	horizon := grid.MinInt(g.EnemyCooldown(prey), MaxKillWindow)
+	if horizon == 0 || state.Matchup(pac.TypeId, prey.TypeId) != 1 {
+		return 0, false
+	}
+	sim := g.Game.Clone()
+	c := &chase{sim: sim, budget: g.Budget, memo: make(map[chaseKey]bool)}
+	for _, p := range sim.MyPacs {
+		if p.Id == pac.Id {
+			c.hunter = p
+		}
+	}
+	for _, p := range sim.OpponentPacs {
+		if p.Id == prey.Id {
+			c.prey = p
+		}
+	}
+	sim.MyPacs, sim.OpponentPacs = []*state.Pac{c.hunter}, []*state.Pac{c.prey}
+	c.prey.SpeedTurnsLeft = g.EnemySpeedLeft(prey)
+	c.prey.AbilityCooldown = horizon
+	for turns := 1; turns <= horizon && !g.Budget.Low(); turns++ {
+		if c.caught(turns) {
+			return turns, true
+		}
+	}
+	return 0, false
+}
+
+// Cells pac may end the turn on walking: its own, and those within one
+// step, two while sped up
+func (c *chase) moves(pac *state.Pac) []*grid.Cell {
+	cell := c.sim.Grid[pac.Y][pac.X]
+	cells := []*grid.Cell{cell}
+	for _, first := range cell.Neighbors {
+		if first.IsWall {
+			continue
+		}
+		cells = append(cells, first)
+		if pac.SpeedTurnsLeft == 0 {
+			continue
+		}
+		for _, second := range first.Neighbors {
+			if !second.IsWall && second != cell {
+				cells = append(cells, second)
+			}
+		}
+	}
+	return cells
+}
+
+// Check if the hunter has an action each turn that catches the prey within
+// left turns whatever the prey does, giving up unproven once the budget
+// runs low
+func (c *chase) caught(left int) bool {
+	if c.budget.Low() {
+		return false
+	}
+	hunterCell, preyCell := c.sim.Grid[c.hunter.Y][c.hunter.X], c.sim.Grid[c.prey.Y][c.prey.X]
+	// a prey holding still escapes a hunter too far to get to it, which
+	// covers two cells a turn at most
+	if d, ok := c.sim.Dist.Between(hunterCell, preyCell); !ok || d > 2*left {
+		return false
+	}
+	key := chaseKey{hunterCell, preyCell, c.hunter.SpeedTurnsLeft, c.prey.SpeedTurnsLeft, c.hunter.AbilityCooldown, left}
+	if proven, ok := c.memo[key]; ok {
+		return proven
+	}
+	var actions []state.Action
+	for _, cell := range c.moves(c.hunter) {
+		actions = append(actions, state.Action{Pac: c.hunter, Target: cell})
+	}
+	if c.hunter.AbilityCooldown == 0 {
+		actions = append(actions, state.Action{Pac: c.hunter, Speed: true})
+	}
+	evasions := c.moves(c.prey)
+	proven := false
+	for _, action := range actions {
+		proven = true
+		for _, cell := range evasions {
+			u := c.sim.Apply([]state.Action{action, {Pac: c.prey, Target: cell}})
+			ok := c.prey.TypeId == state.DeadType || (c.hunter.TypeId != state.DeadType && left > 1 && c.caught(left-1))
+			c.sim.Undo(u)
+			if !ok {
+				proven = false
+				break
+			}
+		}
+		if proven {
+			break
+		}
+	}
+	c.memo[key] = proven
+	return proven
+}
@@ -0,0 +1,47 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+)
+
+func TestKillWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     []string
+		cooldown int
+		turns    int
+		proven   bool
+	}{
+		{"cornered in a dead end", []string{
+			"#######",
+			"#a  0 #",
+			"#######",
+		}, 5, 3, true},
+		{"switch back before the catch", []string{
+			"#######",
+			"#a  0 #",
+			"#######",
+		}, 2, 0, false},
+		{"runs around a loop", []string{
+			"#######",
+			"#0    #",
+			"# ### #",
+			"#    a#",
+			"#######",
+		}, 8, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := NewBot(fixture.Game(tt.rows...))
+			pac, prey := fixture.Pac(bot.Game, 0), bot.OpponentPacs[0]
+			pac.AbilityCooldown = 5
+			prey.TypeId, prey.AbilityCooldown = "SCISSORS", tt.cooldown
+			turns, proven := bot.KillWindow(pac, prey)
+			if proven != tt.proven || turns != tt.turns {
+				t.Errorf("got %d turns proven %v, want %d %v", turns, proven, tt.turns, tt.proven)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package strategy
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// Check if paths a and b run through a shared corridor stretch in opposite
+// directions, so pacs walking them would block each other
+func headOn(a, b []*grid.Cell) bool {
+	index := make(map[*grid.Cell]int)
+	for i, cell := range b {
+		index[cell] = i
+	}
+	first, last := -1, -1
+	corridor := false
+	for _, cell := range a {
+		j, ok := index[cell]
+		if !ok {
+			continue
+		}
+		if first < 0 {
+			first = j
+		}
+		last = j
+		corridor = corridor || !cell.IsJunction()
+	}
+	return corridor && last >= 0 && first > last
+}
+
+// Find a way for a pac to give way to another pac: a loop to its target
+// avoiding the other pac's path, or else the nearest cell off that path. The
+// returned path starts at the pac, its last cell is where the pac should go.
+func (g *Bot) wayOut(pac *state.Pac, path, otherPath []*grid.Cell, reserved map[*grid.Cell]int) []*grid.Cell {
+	blocked := make(map[*grid.Cell]bool)
+	for _, cell := range otherPath {
+		blocked[cell] = true
+	}
+	free := func(c *grid.Cell) bool {
+		owner, ok := reserved[c]
+		return !ok || owner == pac.Id
+	}
+	target := grid.GetCell(pac.Plan.Target.X, pac.Plan.Target.Y, g.Grid)
+	loop := grid.BfsFind(path[0], func(c *grid.Cell) bool {
+		return !blocked[c] && free(c)
+	}, func(c *grid.Cell) bool {
+		return c == target
+	})
+	if loop != nil && len(loop) <= len(pac.Plan.Waypoints)+1+g.Params.LoopMargin {
+		// steer through the first cell where the loop leaves the direct path
+		onPath := make(map[*grid.Cell]bool)
+		for _, cell := range path {
+			onPath[cell] = true
+		}
+		for i, cell := range loop {
+			if !onPath[cell] {
+				return loop[:i+1]
+			}
+		}
+		return loop
+	}
+	return grid.BfsFind(path[0], func(c *grid.Cell) bool {
+		return c != otherPath[0] && free(c)
+	}, func(c *grid.Cell) bool {
+		return !blocked[c] && free(c)
+	})
+}
+
+// First turn within the lookahead at which pacs a and b walking paths pa and
+// pb, one cell per turn or two while sped up and waiting at the end, would
+// stand on the same corridor cell or swap cells; -1 when they never meet
+func collisionTurn(a, b *state.Pac, pa, pb []*grid.Cell, lookahead int) int {
+	at := func(path []*grid.Cell, t int) *grid.Cell {
+		if t < len(path) {
+			return path[t]
+		}
+		return path[len(path)-1]
+	}
+	for t := 1; t <= lookahead; t++ {
+		ra, rb := a.Reach(t), b.Reach(t)
+		prevA, prevB := a.Reach(t-1), b.Reach(t-1)
+		same := at(pa, ra) == at(pb, rb) && !at(pa, ra).IsJunction()
+		swap := at(pa, ra) == at(pb, prevB) && at(pb, rb) == at(pa, prevA)
+		if same || swap {
+			return t
+		}
+	}
+	return -1
+}
+
+// Lane priority of a pac, a pac racing for a super pellet beats one
+// collecting regular pellets
+func lanePriority(pac *state.Pac) int {
+	if pac.Plan != nil && pac.Plan.Target.Value > 1 {
+		return 1
+	}
+	return 0
+}
+
+// Detect my pacs about to meet in a narrow corridor by walking their planned
+// paths a few turns forward. The pac with the lower lane priority, or on a
+// tie the one with the cheaper way out, gives way at the nearest junction or
+// by looping around, while the other reserves the corridor cells it will
+// walk through. Returns the detour waypoint per yielding pac and the keeper
+// of each reserved cell.
+func (g *Bot) ResolveCorridorPassing() (map[int]*grid.Cell, map[*grid.Cell]int) {
+	paths := make(map[int][]*grid.Cell)
+	for _, pac := range g.MyPacs {
+		if pac.Plan == nil || pac.Plan.Reached(pac) {
+			continue
+		}
+		path := pac.Plan.Path(g.Game, pac)
+		if reach := pac.Reach(g.Params.PassingLookahead); len(path) > reach+1 {
+			path = path[:reach+1]
+		}
+		if len(path) > 1 {
+			paths[pac.Id] = path
+		}
+	}
+	reserved := make(map[*grid.Cell]int)
+	detours := make(map[int]*grid.Cell)
+	for i, a := range g.MyPacs {
+		for _, b := range g.MyPacs[i+1:] {
+			pa, pb := paths[a.Id], paths[b.Id]
+			if pa == nil || pb == nil || detours[a.Id] != nil || detours[b.Id] != nil {
+				continue
+			}
+			meet := collisionTurn(a, b, pa, pb, g.Params.PassingLookahead)
+			if meet < 0 && !headOn(pa, pb) {
+				continue
+			}
+			outA := g.wayOut(a, pa, pb, reserved)
+			outB := g.wayOut(b, pb, pa, reserved)
+			yielder, keeper, out, keeperPath := a, b, outA, pb
+			switch {
+			case outA == nil:
+				yielder, keeper, out, keeperPath = b, a, outB, pa
+			case outB == nil:
+			case lanePriority(a) != lanePriority(b):
+				if lanePriority(a) > lanePriority(b) {
+					yielder, keeper, out, keeperPath = b, a, outB, pa
+				}
+			case len(outB) < len(outA):
+				yielder, keeper, out, keeperPath = b, a, outB, pa
+			}
+			if out == nil {
+				continue
+			}
+			logger.Log("Pac", yielder.Id, "gives way to pac", keeper.Id, "meeting in", meet, "turns, via", out[len(out)-1].X, out[len(out)-1].Y)
+			for _, cell := range keeperPath {
+				reserved[cell] = keeper.Id
+			}
+			detours[yielder.Id] = out[len(out)-1]
+		}
+	}
+	return detours, reserved
+}
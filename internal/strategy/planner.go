@@ -0,0 +1,139 @@
+package strategy
+
+import (
+	"time"
+
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// Strategy moving every pac along the best walk of the beam search over the
+// next BeamDepth turns, scored by the pellets it collects and its progress
+// towards the pac's target, rather than along the route to the target. The
+// pacs plan one after another, each leaving the pellets on the walks chosen
+// before it to their pacs. Pacs blocked on the way take the same way out as
+// in Greedy: another route to the target while under StuckLimit, else
+// another target.
+type Planner struct{}
+
+// Commands of my pacs this turn, see Strategy
+func (Planner) ComputeCommands(g *Bot) []gameio.Command {
+	g.Walked = make(map[*state.Pellet]int)
+	g.Blocking = make(map[int]*grid.Cell)
+	defer func() {
+		g.Walked = nil
+		g.Blocking = nil
+	}()
+	var exploring []*grid.Cell
+	var commands []gameio.Command
+	for i, pac := range g.MyPacs {
+		if g.Budget.Low() || g.Pub.Expired() {
+			logger.Info("Out of time before pac", pac.Id, "after", g.Budget.Elapsed())
+			for _, rest := range g.MyPacs[i:] {
+				g.Pub.Update(g.Fallback(rest))
+				g.Pub.Label(rest.Id, "late")
+			}
+			break
+		}
+		pacStart := time.Now()
+		g.BeginDecision(pac)
+		logger.Log("Pac", pac.Id, "x", pac.X, "y", pac.Y, "type", pac.TypeId, "speed turns left", pac.SpeedTurnsLeft, "ability cooldown", pac.AbilityCooldown, "plan", pac.Plan)
+		var command gameio.Command
+		if part := g.Roles[pac.Id]; part.Role != RoleCollector {
+			if pac.Plan != nil {
+				pac.Plan.Abandon(g.Game, pac)
+				pac.Plan = nil
+			}
+			command = g.PlayRole(pac, part)
+		} else {
+			command = g.walk(pac, &exploring)
+		}
+		commands = append(commands, g.settle(pac, command, pacStart))
+	}
+	return commands
+}
+
+// Keep the target of pac until it is reached or expires, else pick the
+// closest free super pellet or regular pellet. A blocked pac keeps its old
+// target reserved while picking, so that it turns to another one not
+// behind what blocks it.
+func (g *Bot) retarget(pac *state.Pac, blocked bool) {
+	if !blocked && pac.Plan != nil && !pac.Plan.Reached(pac) && !pac.Plan.Expired(g.Turn) && (pac.Plan.Execute(pac) || pac.Plan.Repair(g.Game, pac)) {
+		return
+	}
+	old := pac.Plan
+	pac.Plan = nil
+	if old != nil && !blocked {
+		old.Abandon(g.Game, pac)
+	}
+	pallet := g.GetClosestSuperPallet(pac)
+	if pallet == nil {
+		pallet = g.GetClosestRegularPallet(pac)
+	}
+	if pallet != nil {
+		pac.Plan = g.NewPlan(pac, pallet)
+	}
+	if old != nil && blocked {
+		old.Abandon(g.Game, pac)
+	}
+}
+
+// Command moving pac this turn along its best walk, claiming the pellets on
+// it. Without a walk the pac heads for its target, or explores the cells
+// not claimed by exploring, or holds. A blocked pac follows the route
+// around what blocks it instead, as walks would lead it back into it.
+func (g *Bot) walk(pac *state.Pac, exploring *[]*grid.Cell) gameio.Command {
+	trigger := g.CheckReplan(pac, g.Invalidated[pac.Id])
+	g.NoteTrigger(trigger)
+	if trigger == state.TriggerBlocked {
+		if pac.Stuck < g.Params.StuckLimit && pac.Plan.Reroute(g.Game, pac) {
+			x, y := pac.Plan.Next(pac)
+			logger.Log("Pac", pac.Id, "blocked, rerouting via", x, y)
+			return gameio.Move{Pac: pac.Id, X: x, Y: y}
+		}
+		logger.Log("Pac", pac.Id, "replans:", trigger)
+		if pac.Plan != nil && len(pac.Plan.Waypoints) > 0 {
+			g.Blocking[pac.Id] = pac.Plan.Waypoints[0]
+		}
+		g.retarget(pac, true)
+		if pac.Plan != nil {
+			x, y := pac.Plan.Next(pac)
+			return gameio.Move{Pac: pac.Id, X: x, Y: y}
+		}
+	} else {
+		g.retarget(pac, false)
+	}
+	var target *grid.Cell
+	if pac.Plan != nil {
+		target = grid.GetCell(pac.Plan.Target.X, pac.Plan.Target.Y, g.Grid)
+	}
+	if best := g.BeamSearch(pac, target); best != nil {
+		for _, cell := range best.Cells[1:] {
+			if pallet := g.Pellet.At(cell.X, cell.Y); pallet != nil && !pallet.Consumed {
+				if _, ok := g.Walked[pallet]; !ok {
+					g.Walked[pallet] = pac.Id
+				}
+			}
+		}
+		steps := 1
+		if pac.SpeedTurnsLeft > 0 {
+			steps = 2
+		}
+		next := best.Cells[grid.MinInt(steps, len(best.Cells)-1)]
+		logger.Log("Pac", pac.Id, "walks to", next.X, next.Y, "collecting", best.Collected, "in", len(best.Cells)-1, "steps")
+		return gameio.Move{Pac: pac.Id, X: next.X, Y: next.Y}
+	}
+	if pac.Plan != nil {
+		x, y := pac.Plan.Next(pac)
+		return gameio.Move{Pac: pac.Id, X: x, Y: y}
+	}
+	if cell := g.ExploreTarget(pac, *exploring); cell != nil {
+		logger.Log("Pac", pac.Id, "has no target, exploring", cell.X, cell.Y)
+		*exploring = append(*exploring, cell)
+		return gameio.Move{Pac: pac.Id, X: cell.X, Y: cell.Y}
+	}
+	logger.Log("Pac", pac.Id, "has no target, holding")
+	return gameio.Wait{Pac: pac.Id, X: pac.X, Y: pac.Y}
+}
@@ -0,0 +1,96 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/budget"
+	"spring2020/internal/fixture"
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+	"spring2020/internal/protocol"
+	"spring2020/internal/state"
+)
+
+func TestStrategiesChooseDifferentWays(t *testing.T) {
+	// the super pellet is the target, the two pellets behind the pac are
+	// worth the detour to the planner only
+	tests := []struct {
+		strategy int
+		want     int
+	}{
+		{StrategyGreedy, 8},
+		{StrategyPlanner, 2},
+	}
+	for _, tt := range tests {
+		bot := NewBot(fixture.Game(
+			"##########",
+			"#..0    o#",
+			"##########",
+		))
+		bot.Params.Strategy = tt.strategy
+		fixture.Pac(bot.Game, 0).AbilityCooldown = 5
+		pub := gameio.NewPublisher(bot.MyPacs, bot.Width, bot.Height)
+		bot.PlayTurn(pub, budget.NewTurnBudget(0))
+		commands, errs := protocol.ParseCommands(pub.Publish())
+		if len(errs) > 0 || len(commands) != 1 || commands[0].Verb != protocol.VerbMove {
+			t.Fatalf("strategy %d published %v, errors %v", tt.strategy, commands, errs)
+		}
+		if commands[0].X != tt.want {
+			t.Errorf("strategy %d moves to x %d, want %d", tt.strategy, commands[0].X, tt.want)
+		}
+		if bot.Walked != nil {
+			t.Errorf("strategy %d left the walked pellets claimed", tt.strategy)
+		}
+	}
+}
+
+func TestCellGainSkipsPelletsWalkedByOthers(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#######",
+		"#0 . 1#",
+		"#######",
+	))
+	pac := fixture.Pac(bot.Game, 0)
+	cell := bot.Grid[1][3]
+	walk := &Beam{Cells: []*grid.Cell{bot.Grid[1][1], bot.Grid[1][2], cell}}
+	if gain := bot.cellGain(pac, walk, cell, 2); gain <= 0 {
+		t.Fatalf("free pellet gains %v", gain)
+	}
+	bot.Walked = map[*state.Pellet]int{bot.Pellet.At(3, 1): 1}
+	if gain := bot.cellGain(pac, walk, cell, 2); gain != 0 {
+		t.Errorf("pellet walked by pac 1 gains %v, want 0", gain)
+	}
+	bot.Walked[bot.Pellet.At(3, 1)] = 0
+	if gain := bot.cellGain(pac, walk, cell, 2); gain <= 0 {
+		t.Errorf("pellet walked by the pac itself gains %v", gain)
+	}
+}
+
+func TestPlannerTurnsFromBlockedCorridor(t *testing.T) {
+	// an enemy of the same type blocks the corridor to the target, the pac
+	// bounced off it for StuckLimit turns, long known, and no detour exists
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#. 0a  o#",
+		"#########",
+	))
+	bot.Params.Strategy = StrategyPlanner
+	pac := fixture.Pac(bot.Game, 0)
+	pac.AbilityCooldown = 5
+	pac.Plan = bot.NewPlan(pac, bot.Pellet.At(7, 1))
+	pac.LastX, pac.LastY = pac.X, pac.Y
+	pac.Stuck = bot.Params.StuckLimit - 1
+	pac.Threatened = true
+	pub := gameio.NewPublisher(bot.MyPacs, bot.Width, bot.Height)
+	bot.PlayTurn(pub, budget.NewTurnBudget(0))
+	commands, errs := protocol.ParseCommands(pub.Publish())
+	if len(errs) > 0 || len(commands) != 1 || commands[0].Verb != protocol.VerbMove {
+		t.Fatalf("published %v, errors %v", commands, errs)
+	}
+	if commands[0].X >= pac.X {
+		t.Errorf("moves to x %d, want away from the blocked corridor", commands[0].X)
+	}
+	if pac.Plan == nil || pac.Plan.Target == bot.Pellet.At(7, 1) {
+		t.Errorf("plan %v, want another target than the blocked one", pac.Plan)
+	}
+}
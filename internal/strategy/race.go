@@ -0,0 +1,38 @@
+package strategy
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// Check if pac, dist steps from super pellet pallet, should leave it to the
+// opponent: an opponent pac is estimated to get there more than RaceMargin
+// turns sooner, or with a safe lead any visible one may get there first or
+// at the same time
+func (g *Bot) conceded(pac *state.Pac, pallet *state.Pellet, dist int) bool {
+	if pallet.Value == 1 {
+		return false
+	}
+	cell := grid.GetCell(pallet.X, pallet.Y, g.Grid)
+	turns := pac.TurnsFor(dist)
+	if g.Mode == ModeSafe && g.raced(cell, turns) {
+		return true
+	}
+	enemyTurns, ok := g.EnemyTurnsTo(cell)
+	return ok && turns > enemyTurns+g.Params.RaceMargin
+}
+
+// Check if the race of pac to its planned super pellet became hopeless
+func (g *Bot) raceLost(pac *state.Pac) bool {
+	target := pac.Plan.Target
+	if target == nil || target.Value == 1 {
+		return false
+	}
+	d, ok := g.StepsTo(pac, target.X, target.Y)
+	if !ok || !g.conceded(pac, target, d) {
+		return false
+	}
+	logger.Log("Pac", pac.Id, "concedes super pellet", target.X, target.Y)
+	return true
+}
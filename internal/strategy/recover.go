@@ -0,0 +1,70 @@
+package strategy
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"spring2020/internal/budget"
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// Panic recovered from planning a turn, with the stack of the planner
+type TurnPanic struct {
+	Reason any
+	Stack  []byte
+}
+
+func (p *TurnPanic) String() string {
+	return fmt.Sprint(p.Reason)
+}
+
+// Play a turn like PlayTurn, but recover from a panic in planning: every pac
+// gets an emergency command so the turn is still answered, and the panic is
+// returned for the crash dump
+func (g *Bot) SafePlayTurn(pub *gameio.Publisher, turnBudget *budget.TurnBudget) (crash *TurnPanic) {
+	defer func() {
+		if r := recover(); r != nil {
+			crash = &TurnPanic{Reason: r, Stack: debug.Stack()}
+			logger.Info("Turn", g.Turn, "panicked:", r)
+			g.Emergency(pub)
+		}
+	}()
+	g.PlayTurn(pub, turnBudget)
+	return nil
+}
+
+// Replace the commands of my pacs by the simplest legal ones, moving to the
+// closest pellet on the map or holding position. Plans are dropped since the
+// panic may have left them half updated, the pacs replan next turn.
+func (g *Bot) Emergency(pub *gameio.Publisher) {
+	for _, pac := range g.MyPacs {
+		if pac.Plan != nil && pac.Plan.Target != nil {
+			pac.Plan.Abandon(g.Game, pac)
+		}
+		pac.Plan = nil
+		var command gameio.Command = gameio.Wait{Pac: pac.Id, X: pac.X, Y: pac.Y}
+		if pallet := g.closestPalletOnMap(pac); pallet != nil {
+			command = gameio.Move{Pac: pac.Id, X: pallet.X, Y: pallet.Y}
+		}
+		logger.Log("Pac", pac.Id, "emergency", command)
+		pub.Update(command)
+		pub.Label(pac.Id, "emergency")
+	}
+}
+
+// Get the pellet closest to pac ignoring walls, which needs nothing but the
+// pellet store to be intact
+func (g *Bot) closestPalletOnMap(pac *state.Pac) *state.Pellet {
+	var closest *state.Pellet
+	closestDist := 0
+	for _, pallet := range g.Pellet.Remaining(0) {
+		dist := grid.ManhattanDistance(g.Grid[pac.Y][pac.X], g.Grid[pallet.Y][pallet.X], g.Width)
+		if closest == nil || dist < closestDist {
+			closest, closestDist = pallet, dist
+		}
+	}
+	return closest
+}
@@ -0,0 +1,31 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/gameio"
+	"spring2020/internal/state"
+)
+
+func TestSafePlayTurnRecovers(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#######",
+		"#0 . o#",
+		"#######",
+	))
+	pac := fixture.Pac(bot.Game, 0)
+	// a plan without target panics when the turn checks it
+	pac.Plan = &state.Plan{}
+	pub := gameio.NewPublisher(bot.MyPacs, bot.Width, bot.Height)
+	crash := bot.SafePlayTurn(pub, nil)
+	if crash == nil {
+		t.Fatal("panic not reported")
+	}
+	if len(crash.Stack) == 0 {
+		t.Error("panic reported without stack")
+	}
+	if pac.Plan != nil {
+		t.Error("plan kept after the panic")
+	}
+}
@@ -0,0 +1,62 @@
+package strategy
+
+import (
+	"spring2020/internal/state"
+)
+
+// Decide whether pac should select a new target this turn and why. Only
+// cheap checks run here so the expensive target selection is spent on pacs
+// whose situation actually changed.
+func (g *Bot) CheckReplan(pac *state.Pac, invalidated bool) state.ReplanTrigger {
+	threatened := false
+	for _, enemy := range g.VisibleEnemies() {
+		if d, ok := g.StepsTo(enemy, pac.X, pac.Y); ok && d <= g.Params.ThreatRadius {
+			threatened = true
+		}
+	}
+	newThreat := threatened && !pac.Threatened
+	pac.Threatened = threatened
+	if pac.Plan != nil && pac.X == pac.LastX && pac.Y == pac.LastY && !pac.Idle {
+		pac.Stuck++
+	} else {
+		pac.Stuck = 0
+	}
+
+	if invalidated {
+		return state.TriggerInvalidated
+	}
+	if pac.Plan == nil {
+		return state.TriggerNoPlan
+	}
+	if pac.Plan.Reached(pac) {
+		return state.TriggerReached
+	}
+	if newThreat {
+		return state.TriggerThreat
+	}
+	if pac.Stuck > 0 {
+		return state.TriggerBlocked
+	}
+	if g.raceLost(pac) {
+		return state.TriggerConceded
+	}
+	if target := pac.Plan.Target; target != nil {
+		targetDist, _ := g.StepsTo(pac, target.X, target.Y)
+		for _, pallet := range g.Pellet.Remaining(0) {
+			if pallet.Value > target.Value && !g.Reservations.Reserved(pallet) &&
+				g.stepsLess(pac, pallet, targetDist-g.Params.ReplanHysteresis) {
+				return state.TriggerBetter
+			}
+		}
+	}
+	if pac.Plan.Expired(g.Turn) {
+		return state.TriggerElapsed
+	}
+	return state.TriggerNone
+}
+
+// Check if pac reaches pallet in fewer than limit steps
+func (g *Bot) stepsLess(pac *state.Pac, pallet *state.Pellet, limit int) bool {
+	d, ok := g.StepsTo(pac, pallet.X, pallet.Y)
+	return ok && d < limit
+}
@@ -0,0 +1,281 @@
+package strategy
+
+import (
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// Part a pac plays this turn
+type Role string
+
+// Pac roles
+const (
+	// Collect pellets on its plan, what pacs do unless given another role
+	RoleCollector Role = "collector"
+	// Chase an opponent pac it beats that cannot switch away before caught
+	RoleHunter Role = "hunter"
+	// Hold a cell against an opponent pac: the chokepoint it must pass to
+	// come out on my side while I lead, or the richest cell of my territory
+	RoleBlocker Role = "blocker"
+)
+
+// Role of a pac with what it is after
+type Part struct {
+	Role Role
+	// Opponent pac a hunter chases
+	Prey *state.Pac
+	// Cell a hunter cuts a cornered prey off on, nil to walk onto the prey
+	Intercept *grid.Cell
+	// Type a hunter switches to the turn its prey may switch, empty to
+	// disengage then
+	Counter string
+	// Cell a blocker holds
+	Post *grid.Cell
+}
+
+// Give my pacs their roles for this turn: at most one hunter, at most one
+// blocker and the rest collectors. The blocker parks on a chokepoint while I
+// lead, or guards a rich cell once there are three pacs. With a safe lead
+// or a single pac everyone collects. A hunter keeps its prey while it is
+// still the best one to chase, and the turn the prey may switch again it
+// switches first to the Counter it kept or disengages as a collector.
+func (g *Bot) AssignRoles() map[int]Part {
+	roles := make(map[int]Part, len(g.MyPacs))
+	for _, pac := range g.MyPacs {
+		roles[pac.Id] = Part{Role: RoleCollector}
+	}
+	if len(g.MyPacs) < 2 || g.Mode == ModeSafe {
+		return roles
+	}
+	if hunter, prey, cut := g.chooseHunter(); hunter != nil {
+		roles[hunter.Id] = Part{Role: RoleHunter, Prey: prey, Intercept: cut, Counter: g.huntResponse(hunter, prey)}
+	}
+	for _, pac := range g.MyPacs {
+		if last := g.Roles[pac.Id]; last.Role == RoleHunter && roles[pac.Id].Role == RoleCollector && g.counters(pac, last) {
+			roles[pac.Id] = last
+		}
+	}
+	if g.MyScore > g.OpponentScore && g.Corridors != nil {
+		if blocker, post := g.chooseChokepoint(roles); blocker != nil {
+			roles[blocker.Id] = Part{Role: RoleBlocker, Post: post}
+			logger.Log("Pac", blocker.Id, "blocks the chokepoint", post.X, post.Y)
+		}
+	}
+	if len(g.MyPacs) >= 3 && !hasRole(roles, RoleBlocker) {
+		if blocker, post := g.chooseBlocker(roles); blocker != nil {
+			roles[blocker.Id] = Part{Role: RoleBlocker, Post: post}
+		}
+	}
+	for _, pac := range g.MyPacs {
+		last := g.Roles[pac.Id].Role
+		if last == "" {
+			last = RoleCollector
+		}
+		if role := roles[pac.Id].Role; role != last {
+			logger.Log("Pac", pac.Id, "becomes", role)
+		}
+	}
+	return roles
+}
+
+// Closest pair of my pac and a visible opponent pac it beats that cannot
+// switch before the pac gets to it: within HuntRadius steps when it cannot
+// outrun the pac, within TrapRadius steps when the pac corners it. A pac
+// not hunting the prey already also needs its KillWindow proven. Also
+// returns the cell cutting off a cornered prey, nil to walk onto the prey.
+func (g *Bot) chooseHunter() (*state.Pac, *state.Pac, *grid.Cell) {
+	var hunter, prey *state.Pac
+	var cut *grid.Cell
+	best := 0
+	for _, enemy := range g.VisibleEnemies() {
+		for _, pac := range g.MyPacs {
+			if state.Matchup(pac.TypeId, enemy.TypeId) != 1 {
+				continue
+			}
+			d, ok := g.StepsTo(pac, enemy.X, enemy.Y)
+			if !ok {
+				continue
+			}
+			intercept, ok := g.huntable(pac, enemy, d)
+			if !ok {
+				continue
+			}
+			// a pac turns hunter only when the catch is proven
+			if g.Roles[pac.Id].Prey != enemy {
+				turns, proven := g.KillWindow(pac, enemy)
+				if !proven {
+					continue
+				}
+				logger.Log("Pac", pac.Id, "catches", enemy.Id, "within", turns, "turns")
+			}
+			if g.Roles[pac.Id].Prey == enemy {
+				d--
+			}
+			if hunter == nil || d < best {
+				hunter, prey, cut, best = pac, enemy, intercept, d
+			}
+		}
+	}
+	return hunter, prey, cut
+}
+
+// Check if pac d steps from enemy may hunt it, returning the cell cutting
+// it off when cornered
+func (g *Bot) huntable(pac, enemy *state.Pac, d int) (*grid.Cell, bool) {
+	if d <= g.Params.TrapRadius {
+		if refuge, ok := g.Cornered(pac, enemy); ok {
+			// the catch may wait until the prey is driven into its refuge
+			catch, _ := g.StepsTo(pac, refuge.X, refuge.Y)
+			if !g.EnemyAbilityWithin(enemy, pac.TurnsFor(catch)-1) {
+				return g.intercept(pac, enemy, refuge), true
+			}
+		}
+		// walking in, the pac holds the mouth before the prey gets out and
+		// catches it at the bottom of the branch at the latest
+		if g.Trapped(pac, enemy) && !g.EnemyAbilityWithin(enemy, pac.TurnsFor(d+g.DeadEnds.Beyond(grid.GetCell(enemy.X, enemy.Y, g.Grid)))-1) {
+			return nil, true
+		}
+	}
+	return nil, d <= g.Params.HuntRadius && enemy.SpeedTurnsLeft <= pac.SpeedTurnsLeft && !g.EnemyAbilityWithin(enemy, pac.TurnsFor(d)-1)
+}
+
+// Type pac switches to the turn prey may switch, beating the counter prey
+// would take against it; empty when the ability of pac is not ready by then
+func (g *Bot) huntResponse(pac, prey *state.Pac) string {
+	if pac.AbilityCooldown > g.EnemyCooldown(prey) {
+		return ""
+	}
+	return state.Counter(state.Counter(pac.TypeId))
+}
+
+// Check if a hunter switches to its Counter this turn: the prey may switch
+// now and is within the two cells it covers in a turn
+func (g *Bot) counters(pac *state.Pac, part Part) bool {
+	d, ok := g.StepsTo(pac, part.Prey.X, part.Prey.Y)
+	return ok && d <= 2 && part.Counter != "" && pac.AbilityCooldown == 0 && part.Prey.TypeId != state.DeadType && g.EnemyCooldown(part.Prey) == 0
+}
+
+// Check if a pac plays role
+func hasRole(roles map[int]Part, role Role) bool {
+	for _, part := range roles {
+		if part.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Collector to park on the chokepoint a visible opponent pac it beats must
+// pass to come out of its corridor on my side, and the chokepoint: the end
+// of the corridor outside opponent territory within GuardRadius steps of
+// the enemy, which the collector reaches first and the enemy cannot switch
+// before getting to. The closest such collector blocks.
+func (g *Bot) chooseChokepoint(roles map[int]Part) (*state.Pac, *grid.Cell) {
+	var blocker *state.Pac
+	var post *grid.Cell
+	best := 0
+	for _, enemy := range g.VisibleEnemies() {
+		start := grid.GetCell(enemy.X, enemy.Y, g.Grid)
+		ends := g.Corridors.Ends(start)
+		if len(ends) != 2 {
+			continue
+		}
+		for _, end := range ends {
+			enemyDist, ok := g.Dist.Between(start, end.Cell)
+			if !ok || enemyDist > g.Params.GuardRadius || g.TerritoryDepth[end.Cell] < 0 {
+				continue
+			}
+			enemyTurns := enemy.TurnsFor(enemyDist)
+			if g.EnemyAbilityWithin(enemy, enemyTurns) {
+				continue
+			}
+			for _, pac := range g.MyPacs {
+				if roles[pac.Id].Role != RoleCollector || state.Matchup(pac.TypeId, enemy.TypeId) != 1 {
+					continue
+				}
+				d, ok := g.StepsTo(pac, end.Cell.X, end.Cell.Y)
+				if !ok || pac.TurnsFor(d) >= enemyTurns {
+					continue
+				}
+				if blocker == nil || d < best {
+					blocker, post, best = pac, end.Cell, d
+				}
+			}
+		}
+	}
+	return blocker, post
+}
+
+// Collector to guard the richest cell of my territory against the closest
+// visible opponent pac within GuardRadius steps of it: the collector closest
+// to the cell among those beating the raider and getting there first
+func (g *Bot) chooseBlocker(roles map[int]Part) (*state.Pac, *grid.Cell) {
+	var post *grid.Cell
+	for _, row := range g.Grid {
+		for _, cell := range row {
+			if g.TerritoryDepth[cell] > 0 && g.Influence[cell] > 0 && (post == nil || g.Influence[cell] > g.Influence[post]) {
+				post = cell
+			}
+		}
+	}
+	if post == nil {
+		return nil, nil
+	}
+	var raider *state.Pac
+	raiderDist := g.Params.GuardRadius + 1
+	for _, enemy := range g.VisibleEnemies() {
+		if d, ok := g.StepsTo(enemy, post.X, post.Y); ok && d < raiderDist {
+			raider, raiderDist = enemy, d
+		}
+	}
+	if raider == nil {
+		return nil, nil
+	}
+	var blocker *state.Pac
+	best := 0
+	for _, pac := range g.MyPacs {
+		if roles[pac.Id].Role != RoleCollector || state.Matchup(pac.TypeId, raider.TypeId) != 1 {
+			continue
+		}
+		d, ok := g.StepsTo(pac, post.X, post.Y)
+		if !ok || pac.TurnsFor(d) > raider.TurnsFor(raiderDist) {
+			continue
+		}
+		if blocker == nil || d < best {
+			blocker, best = pac, d
+		}
+	}
+	if blocker == nil {
+		return nil, nil
+	}
+	return blocker, post
+}
+
+// Command of pac playing a role other than collector: a hunter walks onto
+// its prey or cuts it off, or switches once the prey may, a blocker to its
+// post and holds it there
+func (g *Bot) PlayRole(pac *state.Pac, part Part) gameio.Command {
+	switch part.Role {
+	case RoleHunter:
+		if g.EnemyCooldown(part.Prey) == 0 {
+			logger.Log("Pac", pac.Id, "counters", part.Prey.Id, "as", part.Counter)
+			return gameio.Switch{Pac: pac.Id, Type: part.Counter}
+		}
+		if part.Intercept != nil {
+			logger.Log("Pac", pac.Id, "corners", part.Prey.Id, "at", part.Intercept.X, part.Intercept.Y)
+			return gameio.Move{Pac: pac.Id, X: part.Intercept.X, Y: part.Intercept.Y}
+		}
+		logger.Log("Pac", pac.Id, "hunts", part.Prey.Id, "on", part.Prey.X, part.Prey.Y)
+		return gameio.Move{Pac: pac.Id, X: part.Prey.X, Y: part.Prey.Y}
+	case RoleBlocker:
+		if pac.X == part.Post.X && pac.Y == part.Post.Y {
+			logger.Log("Pac", pac.Id, "holds", part.Post.X, part.Post.Y)
+			return gameio.Wait{Pac: pac.Id, X: pac.X, Y: pac.Y}
+		}
+		logger.Log("Pac", pac.Id, "guards", part.Post.X, part.Post.Y)
+		return gameio.Move{Pac: pac.Id, X: part.Post.X, Y: part.Post.Y}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/gameio"
+)
+
+func TestAssignRolesHuntsEnemyThatCannotSwitch(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"###########",
+		"#0  a    1#",
+		"###########",
+	))
+	enemy := bot.OpponentPacs[0]
+	enemy.TypeId, enemy.AbilityCooldown = "SCISSORS", 5
+	roles := bot.AssignRoles()
+	if part := roles[0]; part.Role != RoleHunter || part.Prey != enemy {
+		t.Fatalf("pac 0 is %v, want the hunter of enemy 0", part.Role)
+	}
+	if roles[1].Role != RoleCollector {
+		t.Errorf("pac 1 is %v, want a collector", roles[1].Role)
+	}
+	if got := bot.PlayRole(fixture.Pac(bot.Game, 0), roles[0]); got != (gameio.Move{Pac: 0, X: 4, Y: 1}) {
+		t.Errorf("hunter plays %v, want MOVE 0 4 1", got)
+	}
+	// an enemy able to switch before it is caught is left alone
+	enemy.AbilityCooldown = 2
+	if roles := bot.AssignRoles(); roles[0].Role != RoleCollector {
+		t.Errorf("pac 0 is %v against an enemy that may switch, want a collector", roles[0].Role)
+	}
+}
+
+func TestAssignRolesGuardsRichCellAgainstRaider(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#############",
+		"#0 ....  2a #",
+		"#1###########",
+		"#############",
+	))
+	enemy := bot.OpponentPacs[0]
+	enemy.TypeId = "SCISSORS"
+	enemy.AbilityCooldown = 0
+	bot.Regions = bot.ComputeRegions()
+	bot.TerritoryDepth = bot.ComputeTerritory()
+	bot.Influence = bot.ComputeInfluence()
+	roles := bot.AssignRoles()
+	var blockers int
+	for id, part := range roles {
+		if part.Role == RoleBlocker {
+			blockers++
+			if bot.TerritoryDepth[part.Post] <= 0 {
+				t.Errorf("pac %d guards (%d, %d) outside my territory", id, part.Post.X, part.Post.Y)
+			}
+		}
+	}
+	if blockers != 1 || roles[0].Role != RoleBlocker {
+		t.Errorf("%d blockers, want pac 0 the closest to the rich cells", blockers)
+	}
+}
+
+func TestAssignRolesBlocksChokepointWithLead(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"###########",
+		"#   0.   1#",
+		"#####.#####",
+		"#####a#####",
+		"#####.#####",
+		"#####.#####",
+		"###########",
+	))
+	enemy := bot.OpponentPacs[0]
+	enemy.TypeId, enemy.AbilityCooldown = "SCISSORS", 8
+	bot.Params.HuntRadius, bot.Params.TrapRadius = 0, 0
+	bot.Regions = bot.ComputeRegions()
+	bot.TerritoryDepth = bot.ComputeTerritory()
+	bot.Influence = bot.ComputeInfluence()
+	bot.MyScore = 10
+	roles := bot.AssignRoles()
+	if part := roles[0]; part.Role != RoleBlocker || part.Post != bot.Grid[1][5] {
+		t.Fatalf("pac 0 is %v, want the blocker of the junction (5, 1)", part.Role)
+	}
+	if got := bot.PlayRole(fixture.Pac(bot.Game, 0), roles[0]); got != (gameio.Move{Pac: 0, X: 5, Y: 1}) {
+		t.Errorf("blocker plays %v, want MOVE 0 5 1", got)
+	}
+	bot.MyScore = 0
+	if roles := bot.AssignRoles(); roles[0].Role == RoleBlocker {
+		t.Error("chokepoint blocked without a lead")
+	}
+}
+
+func TestAssignRolesCutsOffCorneredEnemy(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"##########",
+		"#0       #",
+		"#####a####",
+		"#1###.####",
+		"##########",
+	))
+	enemy := bot.OpponentPacs[0]
+	enemy.TypeId, enemy.AbilityCooldown = "SCISSORS", 9
+	pac := fixture.Pac(bot.Game, 0)
+	refuge, ok := bot.Cornered(pac, enemy)
+	if !ok || refuge != bot.Grid[1][8] {
+		t.Fatalf("got refuge %v cornered %v, want (8, 1)", refuge, ok)
+	}
+	part := bot.AssignRoles()[0]
+	if part.Role != RoleHunter || part.Prey != enemy {
+		t.Fatalf("pac 0 is %v, want the hunter of enemy 0", part.Role)
+	}
+	// the hunter runs for the end of the corridor, past the stub the prey is in
+	if got := bot.PlayRole(pac, part); got != (gameio.Move{Pac: 0, X: 8, Y: 1}) {
+		t.Errorf("hunter plays %v, want MOVE 0 8 1", got)
+	}
+	// with room to run past the pac the enemy is not cornered
+	bot.Params.TrapCells = 4
+	if _, ok := bot.Cornered(pac, enemy); ok {
+		t.Error("enemy cornered in more cells than TrapCells")
+	}
+}
+
+func TestAssignRolesHuntsEnemyInDeadEnd(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#############",
+		"#1    #     #",
+		"# ### # ### #",
+		"#     0     #",
+		"######.######",
+		"######.######",
+		"######.######",
+		"######.######",
+		"######a######",
+		"######.######",
+		"######.######",
+		"#############",
+	))
+	enemy := bot.OpponentPacs[0]
+	enemy.TypeId, enemy.AbilityCooldown = "SCISSORS", 8
+	// neither in reach of a plain hunt nor cornered in a few cells
+	bot.Params.HuntRadius, bot.Params.TrapCells = 0, 2
+	pac := fixture.Pac(bot.Game, 0)
+	if !bot.Trapped(pac, enemy) {
+		t.Fatal("enemy below the pac holding the mouth not trapped")
+	}
+	if part := bot.AssignRoles()[0]; part.Role != RoleHunter || part.Prey != enemy {
+		t.Fatalf("pac 0 is %v, want the hunter of the trapped enemy", part.Role)
+	}
+	// an enemy that may switch before the bottom of the branch is left alone
+	enemy.AbilityCooldown = 5
+	if part := bot.AssignRoles()[0]; part.Role == RoleHunter {
+		t.Error("hunts an enemy that may switch before it is caught")
+	}
+	// the enemy gets out before a pac far from the mouth
+	if bot.Trapped(fixture.Pac(bot.Game, 1), enemy) {
+		t.Error("enemy trapped by a pac farther from the mouth")
+	}
+}
+
+func TestHunterCountersPreyAbleToSwitch(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"###########",
+		"#0 a     1#",
+		"###########",
+	))
+	pac, enemy := fixture.Pac(bot.Game, 0), bot.OpponentPacs[0]
+	enemy.TypeId, enemy.AbilityCooldown = "SCISSORS", 5
+	roles := bot.AssignRoles()
+	if part := roles[0]; part.Role != RoleHunter || part.Counter != "SCISSORS" {
+		t.Fatalf("pac 0 is %v ready to switch to %q, want the hunter keeping SCISSORS", part.Role, part.Counter)
+	}
+	// the turn the prey may switch to PAPER the hunter takes SCISSORS first
+	bot.Roles = roles
+	enemy.AbilityCooldown = 0
+	roles = bot.AssignRoles()
+	if got := bot.PlayRole(pac, roles[0]); got != (gameio.Switch{Pac: 0, Type: "SCISSORS"}) {
+		t.Errorf("hunter plays %v, want SWITCH 0 SCISSORS", got)
+	}
+	// a hunter whose ability is not ready disengages
+	pac.AbilityCooldown = 3
+	if roles := bot.AssignRoles(); roles[0].Role != RoleCollector {
+		t.Errorf("pac 0 is %v, want a collector", roles[0].Role)
+	}
+}
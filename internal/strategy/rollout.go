@@ -0,0 +1,287 @@
+package strategy
+
+import (
+	"math/rand"
+
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/protocol"
+	"spring2020/internal/state"
+)
+
+// Rollout of a few turns played on a copy of the game holding my pacs and
+// the opponent pacs in sight, the first turn with my pacs under the
+// commands. Every other pac, and every pac after the first turn, follows a
+// random greedy policy, switching to the counter of a pac about to eat it
+// when its ability is ready.
+type rollout struct {
+	g   *Bot
+	sim *state.Game
+	rng *rand.Rand
+	// cell each pac came from in its last move, the policy keeps off it
+	prev map[*state.Pac]*grid.Cell
+	// discounted points of my pacs less those of the opponent pacs
+	score float64
+}
+
+// Copy of the game the rollouts play on, without the opponent pacs out of
+// sight
+func (g *Bot) rolloutGame() *state.Game {
+	sim := g.Game.Clone()
+	var visible []*state.Pac
+	for _, enemy := range sim.OpponentPacs {
+		if enemy.Seen == g.Turn && enemy.TypeId != state.DeadType {
+			visible = append(visible, enemy)
+		}
+	}
+	sim.OpponentPacs = visible
+	return sim
+}
+
+// Check if a pellet worth eating is on cell
+func (r *rollout) pellet(cell *grid.Cell) bool {
+	pellet := r.sim.Pellet.At(cell.X, cell.Y)
+	return pellet != nil && !pellet.Consumed && pellet.Value > 0
+}
+
+// Next cell of pac from cell under the random greedy policy: onto a pac it
+// eats next to it, else onto a pellet next to it, else a random open
+// neighbor other than prev
+func (r *rollout) policy(pac *state.Pac, cell, prev *grid.Cell) *grid.Cell {
+	opponents := r.sim.OpponentPacs
+	if !pac.Mine {
+		opponents = r.sim.MyPacs
+	}
+	var open, pellets []*grid.Cell
+	for _, next := range cell.Neighbors {
+		if next.IsWall {
+			continue
+		}
+		for _, other := range opponents {
+			if other.X == next.X && other.Y == next.Y && other.TypeId != state.DeadType && state.Matchup(pac.TypeId, other.TypeId) > 0 {
+				return next
+			}
+		}
+		if r.pellet(next) {
+			pellets = append(pellets, next)
+		}
+		if next != prev {
+			open = append(open, next)
+		}
+	}
+	switch {
+	case len(pellets) > 0:
+		return pellets[r.rng.Intn(len(pellets))]
+	case len(open) > 0:
+		return open[r.rng.Intn(len(open))]
+	}
+	return cell
+}
+
+// Opponent pac of pac within two steps of it that eats it, nil when there
+// is none
+func (r *rollout) threat(pac *state.Pac) *state.Pac {
+	opponents := r.sim.OpponentPacs
+	if !pac.Mine {
+		opponents = r.sim.MyPacs
+	}
+	cell := r.sim.Grid[pac.Y][pac.X]
+	for _, other := range opponents {
+		if other.TypeId == state.DeadType || state.Matchup(pac.TypeId, other.TypeId) >= 0 {
+			continue
+		}
+		if d, ok := r.sim.Dist.Between(cell, r.sim.Grid[other.Y][other.X]); ok && d <= 2 {
+			return other
+		}
+	}
+	return nil
+}
+
+// Actions of turn t of the rollout: the commands of my pacs in the first
+// turn, the policy for the others, two cells ahead for sped up pacs
+func (r *rollout) actions(t int, commands map[int]gameio.Command) []state.Action {
+	var actions []state.Action
+	for _, pacs := range [][]*state.Pac{r.sim.MyPacs, r.sim.OpponentPacs} {
+		for _, pac := range pacs {
+			if pac.TypeId == state.DeadType {
+				continue
+			}
+			if command, ok := commands[pac.Id]; ok && t == 0 && pac.Mine {
+				switch c := command.(type) {
+				case gameio.Move:
+					actions = append(actions, state.Action{Pac: pac, Target: r.sim.Grid[c.Y][c.X]})
+				case gameio.Speed:
+					actions = append(actions, state.Action{Pac: pac, Speed: true})
+				case gameio.Switch:
+					actions = append(actions, state.Action{Pac: pac, Switch: c.Type})
+				}
+				continue
+			}
+			if threat := r.threat(pac); threat != nil && pac.AbilityCooldown == 0 {
+				actions = append(actions, state.Action{Pac: pac, Switch: state.Counter(threat.TypeId)})
+				continue
+			}
+			cell := r.sim.Grid[pac.Y][pac.X]
+			target := r.policy(pac, cell, r.prev[pac])
+			if pac.SpeedTurnsLeft > 0 && target != cell {
+				target = r.policy(pac, target, cell)
+			}
+			actions = append(actions, state.Action{Pac: pac, Target: target})
+		}
+	}
+	return actions
+}
+
+// Count the living pacs of pacs
+func living(pacs []*state.Pac) int {
+	n := 0
+	for _, pac := range pacs {
+		if pac.TypeId != state.DeadType {
+			n++
+		}
+	}
+	return n
+}
+
+// Play turn t of the rollout, scoring the points and pacs each side took
+// discounted by BeamDiscount per turn, and return it for Undo
+func (r *rollout) turn(t int, commands map[int]gameio.Command) *state.Undo {
+	discount := 1.0
+	for i := 0; i < t; i++ {
+		discount *= r.g.Params.BeamDiscount
+	}
+	mine, theirs := r.sim.MyScore, r.sim.OpponentScore
+	myPacs, theirPacs := living(r.sim.MyPacs), living(r.sim.OpponentPacs)
+	from := make(map[*state.Pac]*grid.Cell)
+	for _, pacs := range [][]*state.Pac{r.sim.MyPacs, r.sim.OpponentPacs} {
+		for _, pac := range pacs {
+			from[pac] = r.sim.Grid[pac.Y][pac.X]
+		}
+	}
+	u := r.sim.Apply(r.actions(t, commands))
+	for pac, cell := range from {
+		if pac.X != cell.X || pac.Y != cell.Y {
+			r.prev[pac] = cell
+		}
+	}
+	r.score += float64(r.sim.MyScore-mine-(r.sim.OpponentScore-theirs)) * discount
+	lost := myPacs - living(r.sim.MyPacs) - (theirPacs - living(r.sim.OpponentPacs))
+	r.score -= float64(lost) * r.g.Params.RolloutDeathCost * discount
+	return u
+}
+
+// Mean score of Rollouts rollouts of RolloutDepth turns on sim with my pacs
+// playing commands first, sim left as it was. Rollout k draws from seed k,
+// so every command set meets the same random futures.
+func (g *Bot) evaluateRollouts(sim *state.Game, commands []gameio.Command, seed int64) float64 {
+	byPac := make(map[int]gameio.Command, len(commands))
+	for _, command := range commands {
+		byPac[command.PacId()] = command
+	}
+	total := 0.0
+	undos := make([]*state.Undo, g.Params.RolloutDepth)
+	for k := 0; k < g.Params.Rollouts; k++ {
+		r := &rollout{g: g, sim: sim, rng: rand.New(rand.NewSource(seed + int64(k))), prev: make(map[*state.Pac]*grid.Cell)}
+		for t := range undos {
+			undos[t] = r.turn(t, byPac)
+		}
+		for t := len(undos) - 1; t >= 0; t-- {
+			sim.Undo(undos[t])
+		}
+		total += r.score
+	}
+	return total / float64(g.Params.Rollouts)
+}
+
+// Cells my pacs other than pac stand on or step into under commands, and
+// the corridor cells kept for another pac passing this turn
+func (g *Bot) heldCells(pac *state.Pac, commands []gameio.Command, pacs map[int]*state.Pac) map[*grid.Cell]bool {
+	held := make(map[*grid.Cell]bool)
+	for _, command := range commands {
+		other := pacs[command.PacId()]
+		if other == nil || other == pac {
+			continue
+		}
+		cell := g.Grid[other.Y][other.X]
+		held[cell] = true
+		if move, ok := command.(gameio.Move); ok {
+			held[g.StepToward(cell, g.Grid[move.Y][move.X])] = true
+		}
+	}
+	for cell, keeper := range g.Lanes {
+		if keeper != pac.Id {
+			held[cell] = true
+		}
+	}
+	return held
+}
+
+// Compare the commands of my pacs with the sets moving one pac a cell
+// another way or holding it instead, by the mean outcome of random
+// rollouts, and return the best set; commands when none beats it by
+// RolloutMinGain. A pac whose ability is ready may also activate SPEED or
+// SWITCH to another type instead. Pacs giving way in a corridor keep their
+// detour, and no pac is moved onto a cell another of mine holds. Evaluation stops once the
+// turn budget runs low.
+func (g *Bot) ImproveByRollouts(commands []gameio.Command) []gameio.Command {
+	if g.Params.Rollouts == 0 || g.Params.RolloutDepth == 0 || len(g.VisibleEnemies()) == 0 {
+		return commands
+	}
+	seed := g.Random.TurnSeed(g.Turn)
+	sim := g.rolloutGame()
+	best, bestScore := commands, g.evaluateRollouts(sim, commands, seed)
+	baseline := bestScore
+	pacs := make(map[int]*state.Pac, len(g.MyPacs))
+	for _, pac := range g.MyPacs {
+		pacs[pac.Id] = pac
+	}
+	for i, command := range commands {
+		move, ok := command.(gameio.Move)
+		pac := pacs[command.PacId()]
+		if !ok || pac == nil || g.Detours[pac.Id] != nil {
+			continue
+		}
+		cell := g.Grid[pac.Y][pac.X]
+		planned := g.StepToward(cell, g.Grid[move.Y][move.X])
+		held := g.heldCells(pac, commands, pacs)
+		alternatives := []gameio.Command{gameio.Wait{Pac: pac.Id, X: pac.X, Y: pac.Y}}
+		for _, next := range cell.Neighbors {
+			if !next.IsWall && next != planned && !held[next] {
+				alternatives = append(alternatives, gameio.Move{Pac: pac.Id, X: next.X, Y: next.Y})
+			}
+		}
+		if pac.AbilityCooldown == 0 {
+			alternatives = append(alternatives, gameio.Speed{Pac: pac.Id})
+			for _, t := range protocol.PacTypes {
+				if t != pac.TypeId {
+					alternatives = append(alternatives, gameio.Switch{Pac: pac.Id, Type: t})
+				}
+			}
+		}
+		for _, alternative := range alternatives {
+			if g.Budget.Low() {
+				return g.pickRollout(commands, best, baseline, bestScore)
+			}
+			candidate := append([]gameio.Command{}, commands...)
+			candidate[i] = alternative
+			if score := g.evaluateRollouts(sim, candidate, seed); score > bestScore {
+				best, bestScore = candidate, score
+			}
+		}
+	}
+	return g.pickRollout(commands, best, baseline, bestScore)
+}
+
+// Best rollout set when it beats the commands by RolloutMinGain
+func (g *Bot) pickRollout(commands, best []gameio.Command, baseline, bestScore float64) []gameio.Command {
+	if bestScore-baseline < g.Params.RolloutMinGain {
+		return commands
+	}
+	for i := range best {
+		if best[i] != commands[i] {
+			logger.Log("Rollouts prefer", best[i], "to", commands[i], "gaining", bestScore-baseline)
+		}
+	}
+	return best
+}
@@ -0,0 +1,83 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+)
+
+func TestImproveByRolloutsEatsTrappedEnemy(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"########",
+		"##a0 ..#",
+		"########",
+	))
+	enemy := bot.OpponentPacs[0]
+	enemy.TypeId = "SCISSORS"
+	enemy.AbilityCooldown = 5
+	// walking off to the pellets lets the enemy out of its dead end
+	commands := []gameio.Command{gameio.Move{Pac: 0, X: 6, Y: 1}}
+	improved := bot.ImproveByRollouts(commands)
+	if improved[0] == commands[0] {
+		t.Fatalf("kept %v, want to eat the enemy", commands[0])
+	}
+	if again := bot.ImproveByRollouts(commands); again[0] != improved[0] {
+		t.Errorf("got %v then %v, want the same rollouts", improved[0], again[0])
+	}
+	// an enemy able to switch turns the pac entering its cell into prey
+	enemy.AbilityCooldown = 0
+	if got := bot.ImproveByRollouts(commands); got[0] == improved[0] {
+		t.Errorf("got %v with the enemy's switch ready, want to keep off it", got[0])
+	}
+	// without an enemy in sight the planned commands stand
+	enemy.Seen = bot.Turn - 1
+	if got := bot.ImproveByRollouts(commands); got[0] != commands[0] {
+		t.Errorf("got %v with no enemy in sight, want the plan", got[0])
+	}
+}
+
+func TestImproveByRolloutsKeepsOffMyPacs(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"########",
+		"##a10..#",
+		"########",
+	))
+	bot.OpponentPacs[0].TypeId = "SCISSORS"
+	fixture.Pac(bot.Game, 1).TypeId = "PAPER"
+	// pac 0 eats the enemy only through the cell pac 1 holds
+	commands := []gameio.Command{gameio.Move{Pac: 0, X: 6, Y: 1}, gameio.Wait{Pac: 1, X: 3, Y: 1}}
+	if got := bot.ImproveByRollouts(commands); got[0] != commands[0] {
+		t.Errorf("got %v, want pac 0 kept off pac 1", got[0])
+	}
+	// a pac giving way in a corridor keeps its detour
+	bot.Detours = map[int]*grid.Cell{0: bot.Grid[1][6]}
+	commands[1] = gameio.Move{Pac: 1, X: 6, Y: 1}
+	if got := bot.ImproveByRollouts(commands); got[0] != commands[0] {
+		t.Errorf("got %v, want pac 0 on its detour", got[0])
+	}
+}
+
+func TestImproveByRolloutsSwitchesOnContact(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"########",
+		"#0a ..##",
+		"########",
+	))
+	enemy := bot.OpponentPacs[0]
+	enemy.TypeId = "PAPER"
+	enemy.AbilityCooldown = 5
+	// the paper steps onto the rock cornered in the dead end, which eats it
+	// as scissors instead
+	commands := []gameio.Command{gameio.Move{Pac: 0, X: 5, Y: 1}}
+	want := gameio.Switch{Pac: 0, Type: "SCISSORS"}
+	if got := bot.ImproveByRollouts(commands); got[0] != want {
+		t.Errorf("got %v, want %v", got[0], want)
+	}
+	// a pac still cooling down has only moves
+	fixture.Pac(bot.Game, 0).AbilityCooldown = 3
+	if got := bot.ImproveByRollouts(commands); got[0] == want {
+		t.Errorf("got %v with the ability cooling down", got[0])
+	}
+}
@@ -0,0 +1,165 @@
+// Package strategy chooses the commands of my pacs every turn.
+package strategy
+
+import (
+	"time"
+
+	"spring2020/internal/budget"
+	"spring2020/internal/gameio"
+	"spring2020/internal/grid"
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// Bot playing my pacs on the game state it shares with the input reader,
+// keeping the analysis of the map it recomputes every turn
+type Bot struct {
+	*state.Game
+	Ownership map[*state.Pellet]Owner
+	// Pac of mine reaching each cell fastest
+	Regions        map[*grid.Cell]int
+	TerritoryDepth map[*grid.Cell]int
+	Mode           Mode
+	// Role of each of my pacs this turn
+	Roles map[int]Part
+	// Points of the pellets around each cell, decaying with distance
+	Influence map[*grid.Cell]float64
+	// Time left for the turn being played, searches return their best
+	// result so far once it runs low
+	Budget *budget.TurnBudget
+	// Publisher of the turn being played, strategies update it as they
+	// decide so a deadline finds the commands decided so far
+	Pub *gameio.Publisher
+	// Pacs of mine whose target was eaten since last turn
+	Invalidated map[int]bool
+	// Pellets on the walks the planner chose this turn by the pac walking
+	// them, nil unless the planner plays
+	Walked map[*state.Pellet]int
+	// Cells my blocked pacs failed to enter by pac, the pellets behind them
+	// are cut off until the next turn
+	Blocking map[int]*grid.Cell
+	// Detours of my pacs giving way in a corridor this turn by pac, and the
+	// pac keeping each corridor cell they give way on, nil unless greedy
+	// plays
+	Detours map[int]*grid.Cell
+	Lanes   map[*grid.Cell]int
+	// Values of the duel positions searched, made by the first duel
+	Transpositions *state.TranspositionTable
+}
+
+// Decision logic choosing the commands of my pacs once the turn's analysis
+// of the map is done. Commands are published as they are decided and
+// returned in the order of my pacs, those left out when time ran out
+// holding their fallback.
+type Strategy interface {
+	ComputeCommands(g *Bot) []gameio.Command
+}
+
+// Strategies selected by the Strategy parameter
+const (
+	StrategyGreedy = iota
+	StrategyPlanner
+)
+
+// Strategy the Strategy parameter selects, the greedy one when it names none
+func (g *Bot) ChooseStrategy() Strategy {
+	switch g.Params.Strategy {
+	case StrategyGreedy:
+		return Greedy{}
+	case StrategyPlanner:
+		return Planner{}
+	}
+	logger.Info("Unknown strategy", g.Params.Strategy, "playing greedy")
+	return Greedy{}
+}
+
+// Create bot playing game
+func NewBot(game *state.Game) *Bot {
+	return &Bot{Game: game}
+}
+
+// Check if pac should activate SPEED this turn: the ability is ready, no
+// opponent is close enough to punish a turn spent standing still and there
+// is a target to run to
+func (g *Bot) ShouldSpeed(pac *state.Pac) bool {
+	return pac.AbilityCooldown == 0 && !pac.Threatened && pac.Plan != nil
+}
+
+// Cheap legal command for pac when there is no time left to plan it: keep
+// walking its plan, or hold position without one
+func (g *Bot) Fallback(pac *state.Pac) gameio.Command {
+	if pac.Plan == nil {
+		return gameio.Wait{Pac: pac.Id, X: pac.X, Y: pac.Y}
+	}
+	x, y := pac.Plan.Goal()
+	return gameio.Move{Pac: pac.Id, X: x, Y: y}
+}
+
+// Play a turn within turnBudget
+func (g *Bot) PlayTurn(pub *gameio.Publisher, turnBudget *budget.TurnBudget) {
+	g.Budget = turnBudget
+	g.Pub = pub
+	g.Invalidated = make(map[int]bool)
+	g.Detours, g.Lanes = nil, nil
+	for _, pac := range g.MyPacs {
+		g.RemovePallet(pac)
+		g.Invalidated[pac.Id] = g.CheckTargetEaten(pac)
+	}
+	for _, pac := range g.OpponentPacs {
+		g.RemovePallet(pac)
+	}
+	if dropped := g.PruneReservations(); dropped > 0 {
+		logger.Log("Released", dropped, "stale reservations")
+	}
+	g.Ownership = g.ComputeOwnership()
+	g.Regions = g.ComputeRegions()
+	g.TerritoryDepth = g.ComputeTerritory()
+	g.Risk = g.ComputeRisk()
+	g.Danger = g.ComputeDanger()
+	g.Flows = g.ComputeFlows()
+	g.Influence = g.ComputeInfluence()
+	projection := g.ProjectScores()
+	g.Mode = g.ChooseMode(projection)
+	logger.Info("Projected", projection.Mine, "to", projection.Theirs, "with", projection.Remaining, "left, mode", g.Mode)
+	g.Roles = g.AssignRoles()
+
+	commands := g.ChooseStrategy().ComputeCommands(g)
+	if !g.Budget.Low() && !pub.Expired() {
+		improved := g.ImproveByRollouts(commands)
+		for i, command := range improved {
+			if command != commands[i] {
+				pub.Update(command)
+			}
+		}
+		commands = improved
+	}
+	// the referee sends back both of two pacs of mine meeting, one gives way
+	if !g.Budget.Low() && !pub.Expired() {
+		for _, command := range g.ResolveCollisions(commands) {
+			pub.Update(command)
+		}
+	}
+	logger.Info("Turn took", g.Budget.Elapsed())
+}
+
+// Let a fight override the command decided for pac, or SPEED take its turn
+// when the plan is kept, then publish it as the decision of pac started at
+// start
+func (g *Bot) settle(pac *state.Pac, command gameio.Command, start time.Time) gameio.Command {
+	// fights override the plan, which is picked up again afterwards
+	if fight, idle := g.Fight(pac, command); fight != nil {
+		pac.Idle = idle
+		command = fight
+	} else {
+		// the plan is kept, the pac walks it twice as fast from next turn
+		pac.Idle = g.ShouldSpeed(pac)
+		if pac.Idle {
+			logger.Log("Pac", pac.Id, "speeds up")
+			command = gameio.Speed{Pac: pac.Id}
+		}
+	}
+	g.Pub.Update(command)
+	g.Pub.Label(pac.Id, g.Annotate(pac, g.Roles[pac.Id]))
+	g.EndDecision(command.String(), time.Since(start))
+	return command
+}
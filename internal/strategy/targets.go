@@ -0,0 +1,147 @@
+package strategy
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/state"
+)
+
+// Check if the shortest way of pac to pallet, dist steps long, leads through
+// the cell that blocked it this turn
+func (g *Bot) cutOff(pac *state.Pac, pallet *state.Pellet, dist int) bool {
+	cell := g.Blocking[pac.Id]
+	if cell == nil {
+		return false
+	}
+	to, ok := g.StepsTo(pac, cell.X, cell.Y)
+	if !ok {
+		return false
+	}
+	from, ok := g.Dist.Between(cell, grid.GetCell(pallet.X, pallet.Y, g.Grid))
+	return ok && to+from == dist
+}
+
+// Get the closest reachable super pallet to pac, leaving the ones it
+// concedes to an opponent pac so it falls back to regular pellets
+func (g *Bot) GetClosestSuperPallet(pac *state.Pac) *state.Pellet {
+	var closest *state.Pellet
+	var closestDist int
+	for _, pallet := range g.Pellet.Remaining(10) {
+		if !g.Reservations.Reserved(pallet) {
+			d, ok := g.StepsTo(pac, pallet.X, pallet.Y)
+			if !ok || g.conceded(pac, pallet, d) || g.cutOff(pac, pallet, d) {
+				continue
+			}
+			g.NoteCandidate("super", pallet, d)
+			dist := d + g.TerritoryAdjustment(pallet) + g.RiskAdjustment(pallet) + g.DangerAdjustment(pac, pallet, d) + g.TrapAdjustment(pac, pallet, d) - g.InfluenceAdjustment(pallet)
+			if closest == nil || dist < closestDist || (dist == closestDist && g.richer(pallet, closest)) {
+				closest = pallet
+				closestDist = dist
+			}
+		}
+	}
+	return closest
+}
+
+// Get closest regular pallet to pac, leaving pellets another pac clearly owns
+// unless there is nothing else
+func (g *Bot) GetClosestRegularPallet(pac *state.Pac) *state.Pellet {
+	if closest := g.closestRegularPallet(pac, true); closest != nil {
+		return closest
+	}
+	return g.closestRegularPallet(pac, false)
+}
+
+// Get closest reachable regular pallet to pac, optionally skipping pellets
+// another pac reaches at least OwnershipMargin steps sooner
+func (g *Bot) closestRegularPallet(pac *state.Pac, respectOwners bool) *state.Pellet {
+	var closest *state.Pellet
+	var closestDist int
+	for _, pallet := range g.Pellet.Remaining(1) {
+		if !g.Reservations.Reserved(pallet) {
+			if owner, ok := g.Ownership[pallet]; respectOwners && ok && owner.PacId != pac.Id && owner.Margin >= g.Params.OwnershipMargin {
+				continue
+			}
+			d, ok := g.StepsTo(pac, pallet.X, pallet.Y)
+			if !ok || g.cutOff(pac, pallet, d) {
+				continue
+			}
+			g.NoteCandidate("regular", pallet, d)
+			dist := d + g.TerritoryAdjustment(pallet) + g.RiskAdjustment(pallet) + g.DangerAdjustment(pac, pallet, d) + g.TrapAdjustment(pac, pallet, d) - g.InfluenceAdjustment(pallet)
+			if closest == nil || dist < closestDist || (dist == closestDist && g.richer(pallet, closest)) {
+				closest = pallet
+				closestDist = dist
+			}
+		}
+	}
+	return closest
+}
+
+// Pellet an opponent pac is predicted to harvest next
+type Denial struct {
+	Enemy     *state.Pac
+	Pellet    *state.Pellet
+	EnemyDist int
+}
+
+// Predict which pellet each opponent pac harvests next, assuming it greedily
+// walks to its closest pellet from the last known position like we do
+func (g *Bot) PredictEnemyHarvest() []Denial {
+	var denials []Denial
+	for _, enemy := range g.OpponentPacs {
+		var closest *state.Pellet
+		var closestDist int
+		for _, pallet := range g.Pellet.Remaining(0) {
+			if pallet.Value == 0 {
+				continue
+			}
+			d, ok := g.StepsTo(enemy, pallet.X, pallet.Y)
+			if !ok {
+				continue
+			}
+			if closest == nil || d < closestDist {
+				closest = pallet
+				closestDist = d
+			}
+		}
+		if closest != nil {
+			denials = append(denials, Denial{Enemy: enemy, Pellet: closest, EnemyDist: closestDist})
+		}
+	}
+	return denials
+}
+
+// Check that no opponent pac in sight is within two steps of pac
+func (g *Bot) IsSafe(pac *state.Pac) bool {
+	for _, enemy := range g.VisibleEnemies() {
+		if d, ok := g.StepsTo(enemy, pac.X, pac.Y); ok && d <= 2 {
+			return false
+		}
+	}
+	return true
+}
+
+// Get a predicted enemy pellet the pac reaches strictly first, counting
+// turns so either side's speed is taken into account, and at most
+// DenialMargin steps further than its own closest pellet
+func (g *Bot) GetDenialPallet(pac *state.Pac, denials []Denial, closestDist int) *state.Pellet {
+	var best *state.Pellet
+	var bestDist int
+	for _, denial := range denials {
+		if denial.Pellet.Consumed || g.Reservations.Reserved(denial.Pellet) {
+			continue
+		}
+		d, ok := g.StepsTo(pac, denial.Pellet.X, denial.Pellet.Y)
+		if !ok || g.cutOff(pac, denial.Pellet, d) {
+			continue
+		}
+		g.NoteCandidate("denial", denial.Pellet, d)
+		if pac.TurnsFor(d) >= denial.Enemy.TurnsFor(denial.EnemyDist) || d > closestDist+g.Params.DenialMargin {
+			continue
+		}
+		if best == nil || d < bestDist {
+			best = denial.Pellet
+			bestDist = d
+		}
+	}
+	return best
+}
@@ -0,0 +1,147 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/state"
+)
+
+func TestClosestSuperPalletByMazeDistance(t *testing.T) {
+	// the super pellet right of the pac is three cells away on the map but
+	// eleven steps around the wall
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#  0#o  #",
+		"# ##### #",
+		"#       #",
+		"#o      #",
+		"#########",
+	))
+	got := bot.GetClosestSuperPallet(fixture.Pac(bot.Game, 0))
+	if got == nil || got.X != 1 || got.Y != 4 {
+		t.Fatalf("got %v, want the super pellet at (1, 4)", got)
+	}
+}
+
+func TestClosestPalletSkipsUnreachableAndReserved(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#.0  . ##",
+		"#########",
+		"#.      #",
+		"#########",
+	))
+	pac := fixture.Pac(bot.Game, 0)
+	if got := bot.GetClosestRegularPallet(pac); got == nil || got.X != 1 || got.Y != 1 {
+		t.Fatalf("got %v, want the pellet at (1, 1)", got)
+	}
+	bot.Reservations.Reserve(bot.Pellet.At(1, 1), 8)
+	if got := bot.GetClosestRegularPallet(pac); got == nil || got.X != 5 || got.Y != 1 {
+		t.Fatalf("got %v, want the pellet at (5, 1) once (1, 1) is reserved", got)
+	}
+	bot.Reservations.Reserve(bot.Pellet.At(5, 1), 9)
+	if got := bot.GetClosestRegularPallet(pac); got != nil {
+		t.Fatalf("got %v, want none", got)
+	}
+}
+
+func TestAssignTargetsSpreadsPacs(t *testing.T) {
+	// greedily both pacs would run for the pellet between them
+	bot := NewBot(fixture.Game(
+		"###########",
+		"#. 0 . 1 .#",
+		"###########",
+	))
+	pacs := []*state.Pac{fixture.Pac(bot.Game, 0), fixture.Pac(bot.Game, 1)}
+	assigned := bot.AssignTargets(pacs)
+	if len(assigned) != 2 {
+		t.Fatalf("assigned %d pacs, want 2", len(assigned))
+	}
+	if assigned[0] == assigned[1] {
+		t.Fatalf("both pacs assigned %v", assigned[0])
+	}
+	total := 0
+	for _, pac := range pacs {
+		d, _ := bot.StepsTo(pac, assigned[pac.Id].X, assigned[pac.Id].Y)
+		total += d
+	}
+	if total != 4 {
+		t.Errorf("assignment walks %d steps, want 4", total)
+	}
+}
+
+func TestHungarian(t *testing.T) {
+	tests := []struct {
+		name string
+		cost [][]int
+		want []int
+	}{
+		{"diagonal", [][]int{{1, 9}, {9, 1}}, []int{0, 1}},
+		{"crossed", [][]int{{4, 1, 3}, {2, 0, 5}, {3, 2, 2}}, []int{1, 0, 2}},
+		{"more columns", [][]int{{5, 1, 9}, {1, 5, 9}}, []int{1, 0}},
+		{"more rows", [][]int{{1}, {0}}, []int{-1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hungarian(tt.cost)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClosestPalletPrefersRicherSurroundings(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"###########",
+		"#.  0  ...#",
+		"###########",
+	))
+	bot.Influence = bot.ComputeInfluence()
+	got := bot.GetClosestRegularPallet(fixture.Pac(bot.Game, 0))
+	if got == nil || got.X < 7 {
+		t.Fatalf("got %v, want one of the pellets right of the pac", got)
+	}
+}
+
+func TestAssignTargetsKeepsToRegion(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"###########",
+		"#.  0 .1  #",
+		"###########",
+	))
+	bot.Regions = bot.ComputeRegions()
+	if region := bot.Regions[bot.Grid[1][6]]; region != 1 {
+		t.Fatalf("(6, 1) in the region of pac %d, want 1", region)
+	}
+	assigned := bot.AssignTargets([]*state.Pac{fixture.Pac(bot.Game, 0)})
+	if got := assigned[0]; got == nil || got.X != 1 {
+		t.Fatalf("got %v, want the pellet at (1, 1) in the pac's own region", got)
+	}
+	// once its region is empty the pac may take any pellet
+	bot.Pellet.Consume(1, 1)
+	assigned = bot.AssignTargets([]*state.Pac{fixture.Pac(bot.Game, 0)})
+	if got := assigned[0]; got == nil || got.X != 6 {
+		t.Fatalf("got %v, want the pellet at (6, 1)", got)
+	}
+}
+
+func TestClosestSuperPalletConcedesHopelessRace(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#0 .  oa#",
+		"#########",
+	))
+	pac := fixture.Pac(bot.Game, 0)
+	if got := bot.GetClosestSuperPallet(pac); got != nil {
+		t.Fatalf("got %v, want the super pellet next to the enemy conceded", got)
+	}
+	// an enemy lost from sight long ago no longer races for it
+	bot.Turn = 5
+	if got := bot.GetClosestSuperPallet(pac); got == nil || got.X != 6 {
+		t.Fatalf("got %v, want the super pellet at (6, 1)", got)
+	}
+}
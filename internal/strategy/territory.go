@@ -0,0 +1,232 @@
+package strategy
+
+import (
+	"spring2020/internal/grid"
+	"spring2020/internal/state"
+)
+
+// Fastest of my pacs to a pellet
+type Owner struct {
+	PacId int
+	Dist  int
+	// Steps the runner-up pac needs more, -1 when no other pac reaches the pellet
+	Margin int
+}
+
+// Closest or runner-up pac of mine to a cell
+type regionLabel struct {
+	pac, dist int
+}
+
+// Label every cell with the pac of mine reaching it fastest and the second
+// fastest, using one multi-source BFS from all my pacs in which each cell is
+// expanded at most twice
+func (g *Bot) partition() (first, second map[*grid.Cell]regionLabel) {
+	type item struct {
+		cell *grid.Cell
+		regionLabel
+	}
+	first = make(map[*grid.Cell]regionLabel)
+	second = make(map[*grid.Cell]regionLabel)
+	var queue []item
+	for _, pac := range g.MyPacs {
+		cell := grid.GetCell(pac.X, pac.Y, g.Grid)
+		l := regionLabel{pac.Id, 0}
+		if _, ok := first[cell]; !ok {
+			first[cell] = l
+		} else if _, ok := second[cell]; !ok {
+			second[cell] = l
+		} else {
+			continue
+		}
+		queue = append(queue, item{cell, l})
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, neighbor := range current.cell.Neighbors {
+			if neighbor.IsWall {
+				continue
+			}
+			l := regionLabel{current.pac, current.dist + 1}
+			if f, ok := first[neighbor]; !ok {
+				first[neighbor] = l
+			} else if _, ok := second[neighbor]; ok || f.pac == current.pac {
+				continue
+			} else {
+				second[neighbor] = l
+			}
+			queue = append(queue, item{neighbor, l})
+		}
+	}
+	return first, second
+}
+
+// Split the maze into the regions of my pacs, each cell going to the pac
+// reaching it fastest
+func (g *Bot) ComputeRegions() map[*grid.Cell]int {
+	first, _ := g.partition()
+	regions := make(map[*grid.Cell]int, len(first))
+	for cell, l := range first {
+		regions[cell] = l.pac
+	}
+	return regions
+}
+
+// Label every known pellet with the pac reaching it fastest and the margin
+// over the second fastest
+func (g *Bot) ComputeOwnership() map[*state.Pellet]Owner {
+	first, second := g.partition()
+	ownership := make(map[*state.Pellet]Owner)
+	for _, pallet := range g.Pellet.Remaining(0) {
+		cell := grid.GetCell(pallet.X, pallet.Y, g.Grid)
+		f, ok := first[cell]
+		if !ok {
+			continue
+		}
+		owner := Owner{PacId: f.pac, Dist: f.dist, Margin: -1}
+		if s, ok := second[cell]; ok {
+			owner.Margin = s.dist - f.dist
+		}
+		ownership[pallet] = owner
+	}
+	return ownership
+}
+
+// Compute how many steps sooner my closest pac reaches each cell than the
+// closest known opponent pac: positive inside my territory, negative inside
+// theirs, zero on the frontier
+func (g *Bot) ComputeTerritory() map[*grid.Cell]int {
+	var mine, theirs []*grid.Cell
+	for _, pac := range g.MyPacs {
+		mine = append(mine, grid.GetCell(pac.X, pac.Y, g.Grid))
+	}
+	for _, pac := range g.OpponentPacs {
+		theirs = append(theirs, grid.GetCell(pac.X, pac.Y, g.Grid))
+	}
+	myDist := grid.BfsDistances(mine)
+	theirDist := grid.BfsDistances(theirs)
+	depth := make(map[*grid.Cell]int)
+	for cell, d := range myDist {
+		if o, ok := theirDist[cell]; ok {
+			depth[cell] = o - d
+		} else {
+			depth[cell] = g.Params.TerritoryCap + g.Params.TerritoryFrontier
+		}
+	}
+	for cell, o := range theirDist {
+		if _, ok := myDist[cell]; !ok {
+			depth[cell] = -o
+		}
+	}
+	return depth
+}
+
+// Steps added to the distance of a pellet for target scoring: pellets far
+// inside opponent territory will likely be eaten before we arrive and risk
+// encounters, pellets deep in my territory are safe banked score
+func (g *Bot) TerritoryAdjustment(pallet *state.Pellet) int {
+	depth, ok := g.TerritoryDepth[grid.GetCell(pallet.X, pallet.Y, g.Grid)]
+	if !ok {
+		return 0
+	}
+	switch {
+	case depth < -g.Params.TerritoryFrontier && g.Mode == ModeHunt:
+		return 0
+	case depth < -g.Params.TerritoryFrontier && (g.Mode == ModeTurtle || g.Mode == ModeSafe):
+		return 2 * grid.MinInt(-depth-g.Params.TerritoryFrontier, g.Params.TerritoryCap)
+	case depth < -g.Params.TerritoryFrontier:
+		return grid.MinInt(-depth-g.Params.TerritoryFrontier, g.Params.TerritoryCap)
+	case depth > g.Params.TerritoryFrontier:
+		return -grid.MinInt(depth-g.Params.TerritoryFrontier, g.Params.TerritoryCap) / 2
+	}
+	return 0
+}
+
+// Macro strategy mode
+type Mode string
+
+// Macro modes
+const (
+	// Collect pellets
+	ModeFarm Mode = "farm"
+	// Slightly ahead: steal pellets the opponent is heading for
+	ModeDeny Mode = "deny"
+	// Far behind: press into contested and opponent territory
+	ModeHunt Mode = "hunt"
+	// Far ahead: stay out of opponent territory and protect the lead
+	ModeTurtle Mode = "turtle"
+	// Ahead by more than all the pellets left: avoid every opponent pac and
+	// contested super pellet, the game is won unless my pacs die
+	ModeSafe Mode = "safe"
+)
+
+// Estimate of both players' final scores
+type Projection struct {
+	Mine      int
+	Theirs    int
+	Remaining int
+	// Share of the remaining pellet value expected to go to me
+	Share float64
+}
+
+// Project final scores from the current scores and the remaining known
+// pellet value, split by how much of it lies in my territory blended with
+// my share of the pacs
+func (g *Bot) ProjectScores() Projection {
+	var remaining, mine, contested int
+	for _, pallet := range g.Pellet.Remaining(0) {
+		if pallet.Value == 0 {
+			continue
+		}
+		remaining += pallet.Value
+		depth := g.TerritoryDepth[grid.GetCell(pallet.X, pallet.Y, g.Grid)]
+		if depth > 0 {
+			mine += pallet.Value
+		} else if depth == 0 {
+			contested += pallet.Value
+		}
+	}
+	p := Projection{Remaining: remaining, Share: 0.5}
+	if remaining > 0 {
+		territoryShare := (float64(mine) + float64(contested)/2) / float64(remaining)
+		pacShare := 0.5
+		if pacs := len(g.MyPacs) + len(g.OpponentPacs); pacs > 0 {
+			pacShare = float64(len(g.MyPacs)) / float64(pacs)
+		}
+		p.Share = (territoryShare + pacShare) / 2
+	}
+	p.Mine = g.MyScore + int(p.Share*float64(remaining))
+	p.Theirs = g.OpponentScore + remaining - int(p.Share*float64(remaining))
+	return p
+}
+
+// Choose the macro mode from the projected final scores, safe once no
+// pellet left can overturn the lead
+func (g *Bot) ChooseMode(p Projection) Mode {
+	total := float64(g.MyScore + g.OpponentScore + p.Remaining)
+	if total == 0 {
+		return ModeFarm
+	}
+	lead := float64(p.Mine-p.Theirs) / total
+	switch {
+	case g.MyScore > g.OpponentScore+p.Remaining:
+		return ModeSafe
+	case lead > g.Params.TurtleLead && g.MyScore > g.OpponentScore:
+		return ModeTurtle
+	case lead < -g.Params.HuntDeficit:
+		return ModeHunt
+	case lead > 0 && g.MyScore > g.OpponentScore:
+		return ModeDeny
+	}
+	return ModeFarm
+}
+
+// Risk adjustment of the game state, tripled with a safe lead when meeting
+// an opponent pac is all there is to lose
+func (g *Bot) RiskAdjustment(pallet *state.Pellet) int {
+	if g.Mode == ModeSafe {
+		return g.Params.SafeRiskFactor * g.Game.RiskAdjustment(pallet)
+	}
+	return g.Game.RiskAdjustment(pallet)
+}
@@ -0,0 +1,38 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+	"spring2020/internal/gameio"
+)
+
+func TestChooseModeSafeLead(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#0 ... a#",
+		"#########",
+	))
+	bot.MyScore, bot.OpponentScore = 20, 16
+	if mode := bot.ChooseMode(Projection{Remaining: 3}); mode != ModeSafe {
+		t.Errorf("got mode %v with a lead of 4 over 3 points left, want safe", mode)
+	}
+	if mode := bot.ChooseMode(Projection{Remaining: 4}); mode == ModeSafe {
+		t.Error("safe with the lead still in reach")
+	}
+}
+
+func TestSafeLeadAvoidsEnemies(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#########",
+		"#  0 a  #",
+		"#########",
+	))
+	bot.Mode = ModeSafe
+	pac := fixture.Pac(bot.Game, 0)
+	// a pac of the same type only blocks, it is avoided all the same
+	command, _ := bot.Fight(pac, gameio.Move{Pac: 0, X: 7, Y: 1})
+	if command != (gameio.Move{Pac: 0, X: 2, Y: 1}) {
+		t.Errorf("got %v, want MOVE 0 2 1 away from the enemy", command)
+	}
+}
@@ -0,0 +1,136 @@
+package strategy
+
+import (
+	"sort"
+
+	"spring2020/internal/logger"
+	"spring2020/internal/state"
+)
+
+// Fewest pellets a tour must visit to be worth planning
+const MinTourStops = 3
+
+// Most free pellets around a pac a tour picks its stops from
+const TourCandidates = 12
+
+// Pellets a pac eats in order with the steps and points it takes
+type Tour struct {
+	Pellets []*state.Pellet
+	Steps   int
+	Value   int
+}
+
+// Points the tour collects per turn for pac
+func (t Tour) Rate(pac *state.Pac) float64 {
+	if t.Steps == 0 {
+		return 0
+	}
+	return float64(t.Value) / float64(pac.TurnsFor(t.Steps))
+}
+
+// Free pellets within TourRadius steps of pac a tour may visit, the closest
+// TourCandidates of them: not reserved, not taken by another pac's
+// assignment this turn, not conceded to an opponent pac and not cut off
+// from pac
+func (g *Bot) tourCandidates(pac *state.Pac, assigned map[int]*state.Pellet) []*state.Pellet {
+	taken := make(map[*state.Pellet]bool)
+	for id, pallet := range assigned {
+		if id != pac.Id {
+			taken[pallet] = true
+		}
+	}
+	type candidate struct {
+		pellet *state.Pellet
+		dist   int
+	}
+	var near []candidate
+	for _, pallet := range g.Pellet.Remaining(0) {
+		if pallet.Value == 0 || taken[pallet] || g.Reservations.TakenFrom(pallet, pac.Id) {
+			continue
+		}
+		if d, ok := g.StepsTo(pac, pallet.X, pallet.Y); ok && d <= g.Params.TourRadius && !g.conceded(pac, pallet, d) && !g.cutOff(pac, pallet, d) {
+			near = append(near, candidate{pallet, d})
+		}
+	}
+	sort.SliceStable(near, func(a, b int) bool { return near[a].dist < near[b].dist })
+	if len(near) > TourCandidates {
+		near = near[:TourCandidates]
+	}
+	pellets := make([]*state.Pellet, len(near))
+	for i, c := range near {
+		pellets[i] = c.pellet
+	}
+	return pellets
+}
+
+// Grow a tour of pac from first by the stop adding the most points per step
+// walked, up to TourStops pellets within TourSteps steps, and keep the
+// prefix with the best rate that visits at least MinTourStops pellets
+func (g *Bot) growTour(pac *state.Pac, first *state.Pellet, candidates []*state.Pellet) (Tour, bool) {
+	steps, ok := g.StepsTo(pac, first.X, first.Y)
+	if !ok || steps > g.Params.TourSteps {
+		return Tour{}, false
+	}
+	tour := Tour{Pellets: []*state.Pellet{first}, Steps: steps, Value: first.Value}
+	visited := map[*state.Pellet]bool{first: true}
+	best, found := Tour{}, false
+	for len(tour.Pellets) < g.Params.TourStops {
+		last := tour.Pellets[len(tour.Pellets)-1]
+		from := g.Grid[last.Y][last.X]
+		var next *state.Pellet
+		nextDist, nextGain := 0, 0.0
+		for _, pallet := range candidates {
+			if visited[pallet] {
+				continue
+			}
+			d, ok := g.Dist.Between(from, g.Grid[pallet.Y][pallet.X])
+			if !ok || d == 0 || tour.Steps+d > g.Params.TourSteps {
+				continue
+			}
+			if gain := float64(pallet.Value) / float64(d); next == nil || gain > nextGain {
+				next, nextDist, nextGain = pallet, d, gain
+			}
+		}
+		if next == nil {
+			break
+		}
+		visited[next] = true
+		tour.Pellets = append(tour.Pellets, next)
+		tour.Steps += nextDist
+		tour.Value += next.Value
+		if len(tour.Pellets) >= MinTourStops && (!found || tour.Rate(pac) > best.Rate(pac)) {
+			best = Tour{Pellets: append([]*state.Pellet{}, tour.Pellets...), Steps: tour.Steps, Value: tour.Value}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// Plan the tour of pac through the free pellets around it collecting the
+// most points per turn, grown greedily from each candidate first stop.
+// False when no tour visits MinTourStops pellets.
+func (g *Bot) PlanTour(pac *state.Pac, assigned map[int]*state.Pellet) (Tour, bool) {
+	candidates := g.tourCandidates(pac, assigned)
+	best, found := Tour{}, false
+	for _, first := range candidates {
+		if tour, ok := g.growTour(pac, first, candidates); ok && (!found || tour.Rate(pac) > best.Rate(pac)) {
+			best, found = tour, true
+		}
+	}
+	return best, found
+}
+
+// Pellet pac should head for first: the first stop of its best tour when
+// that tour collects more per turn than the best tour starting at target,
+// target otherwise
+func (g *Bot) TourTarget(pac *state.Pac, target *state.Pellet, assigned map[int]*state.Pellet) *state.Pellet {
+	tour, ok := g.PlanTour(pac, assigned)
+	if !ok || tour.Pellets[0] == target {
+		return target
+	}
+	if kept, ok := g.growTour(pac, target, g.tourCandidates(pac, assigned)); ok && kept.Rate(pac) >= tour.Rate(pac) {
+		return target
+	}
+	logger.Log("Pac", pac.Id, "tours", len(tour.Pellets), "pellets in", tour.Steps, "steps starting at", tour.Pellets[0].X, tour.Pellets[0].Y)
+	return tour.Pellets[0]
+}
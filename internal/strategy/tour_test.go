@@ -0,0 +1,38 @@
+package strategy
+
+import (
+	"testing"
+
+	"spring2020/internal/fixture"
+)
+
+func TestTourTargetPrefersPelletCluster(t *testing.T) {
+	bot := NewBot(fixture.Game(
+		"#############",
+		"#.  0 ......#",
+		"#############",
+	))
+	pac := fixture.Pac(bot.Game, 0)
+	tour, ok := bot.PlanTour(pac, nil)
+	if !ok {
+		t.Fatal("no tour planned")
+	}
+	// the last pellet of the row lies beyond TourRadius
+	if first := tour.Pellets[0]; len(tour.Pellets) != 5 || tour.Steps != 6 || first.X != 6 {
+		t.Errorf("got %d pellets in %d steps from (%d, %d), want 5 in 6 from (6, 1)", len(tour.Pellets), tour.Steps, first.X, first.Y)
+	}
+	lone := bot.Pellet.At(1, 1)
+	if got := bot.TourTarget(pac, lone, nil); got == lone {
+		t.Error("kept the lone pellet over the cluster")
+	}
+	// pellets other pacs reserved are left to them, too few stay for a tour
+	for x := 6; x <= 9; x++ {
+		bot.Reservations.Reserve(bot.Pellet.At(x, 1), x)
+	}
+	if _, ok := bot.PlanTour(pac, nil); ok {
+		t.Error("planned a tour through reserved pellets")
+	}
+	if got := bot.TourTarget(pac, lone, nil); got != lone {
+		t.Errorf("got (%d, %d), want the lone pellet without a tour", got.X, got.Y)
+	}
+}
@@ -0,0 +1,173 @@
+// Package telemetry counts what the bot did over a game: planning time per
+// turn, path searches and floods, pellets eaten by each of my pacs,
+// abilities activated and type battles won and lost. The summary is written
+// to the log once the input ends, to compare games and versions at a glance.
+package telemetry
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"spring2020/internal/logger"
+)
+
+// Counts of a whole game, as written at its end
+type Totals struct {
+	Turns int
+	// planning time in milliseconds
+	PlanTotalMs float64
+	PlanMeanMs  float64
+	PlanMaxMs   float64
+	// turns published at the deadline before planning finished
+	Overruns int
+	// A* searches and flood fills of the game and of its busiest turn
+	Searches     int64
+	MaxSearches  int64
+	Floods       int64
+	MaxFloods    int64
+	PelletsByPac map[int]int
+	ValueByPac   map[int]int
+	Abilities    map[string]int
+	DuelsWon     int
+	DuelsLost    int
+}
+
+var (
+	// counted from the planning goroutines
+	searchCount, floodCount atomic.Int64
+
+	telemetryMu   sync.Mutex
+	gameTotals    = newGameTotals()
+	planTimeTotal time.Duration
+	planTimeMax   time.Duration
+)
+
+func newGameTotals() Totals {
+	return Totals{PelletsByPac: make(map[int]int), ValueByPac: make(map[int]int), Abilities: make(map[string]int)}
+}
+
+// Forget everything counted so far
+func ResetTelemetry() {
+	telemetryMu.Lock()
+	defer telemetryMu.Unlock()
+	searchCount.Store(0)
+	floodCount.Store(0)
+	gameTotals = newGameTotals()
+	planTimeTotal, planTimeMax = 0, 0
+}
+
+// Count an A* search
+func CountSearch() {
+	searchCount.Add(1)
+}
+
+// Count a flood fill or breadth first search
+func CountFlood() {
+	floodCount.Add(1)
+}
+
+// Count a pellet of value eaten by my pac
+func PelletEaten(pacId, value int) {
+	telemetryMu.Lock()
+	defer telemetryMu.Unlock()
+	gameTotals.PelletsByPac[pacId]++
+	gameTotals.ValueByPac[pacId] += value
+}
+
+// Count an ability activated by my pacs, SPEED or SWITCH
+func AbilityUsed(verb string) {
+	telemetryMu.Lock()
+	defer telemetryMu.Unlock()
+	gameTotals.Abilities[verb]++
+}
+
+// Count a type battle won by one of my pacs
+func DuelWon() {
+	telemetryMu.Lock()
+	defer telemetryMu.Unlock()
+	gameTotals.DuelsWon++
+}
+
+// Count one of my pacs lost
+func DuelLost() {
+	telemetryMu.Lock()
+	defer telemetryMu.Unlock()
+	gameTotals.DuelsLost++
+}
+
+// Close a turn that took planning time, overrun when its commands were
+// published at the deadline, and start counting the next one
+func RecordTurn(took time.Duration, overrun bool) {
+	telemetryMu.Lock()
+	defer telemetryMu.Unlock()
+	gameTotals.Turns++
+	if overrun {
+		gameTotals.Overruns++
+	}
+	planTimeTotal += took
+	if took > planTimeMax {
+		planTimeMax = took
+	}
+	turnSearches, turnFloods := searchCount.Swap(0), floodCount.Swap(0)
+	gameTotals.Searches += turnSearches
+	gameTotals.Floods += turnFloods
+	if turnSearches > gameTotals.MaxSearches {
+		gameTotals.MaxSearches = turnSearches
+	}
+	if turnFloods > gameTotals.MaxFloods {
+		gameTotals.MaxFloods = turnFloods
+	}
+}
+
+// Counts of the turns recorded so far
+func Summarize() Totals {
+	telemetryMu.Lock()
+	defer telemetryMu.Unlock()
+	summary := gameTotals
+	summary.PelletsByPac = copyPacCounts(gameTotals.PelletsByPac)
+	summary.ValueByPac = copyPacCounts(gameTotals.ValueByPac)
+	summary.Abilities = make(map[string]int, len(gameTotals.Abilities))
+	for verb, n := range gameTotals.Abilities {
+		summary.Abilities[verb] = n
+	}
+	summary.PlanTotalMs = durationMs(planTimeTotal)
+	summary.PlanMaxMs = durationMs(planTimeMax)
+	if gameTotals.Turns > 0 {
+		summary.PlanMeanMs = durationMs(planTimeTotal / time.Duration(gameTotals.Turns))
+	}
+	return summary
+}
+
+func copyPacCounts(counts map[int]int) map[int]int {
+	copied := make(map[int]int, len(counts))
+	for id, n := range counts {
+		copied[id] = n
+	}
+	return copied
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// Write the summary of the game to the log as one JSON line
+func ReportTelemetry() {
+	summary := Summarize()
+	line, err := json.Marshal(summary)
+	if err != nil {
+		logger.Info("Telemetry not written:", err)
+		return
+	}
+	logger.Info("Telemetry", string(line))
+	ids := make([]int, 0, len(summary.PelletsByPac))
+	for id := range summary.PelletsByPac {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		logger.Info("Pac", id, "ate", summary.PelletsByPac[id], "pellets worth", summary.ValueByPac[id])
+	}
+}
@@ -0,0 +1,41 @@
+package telemetry_test
+
+import (
+	"testing"
+	"time"
+
+	"spring2020/internal/telemetry"
+)
+
+func TestSummarize(t *testing.T) {
+	telemetry.ResetTelemetry()
+	defer telemetry.ResetTelemetry()
+	for i := 0; i < 3; i++ {
+		telemetry.CountSearch()
+	}
+	telemetry.CountFlood()
+	telemetry.RecordTurn(10*time.Millisecond, false)
+	telemetry.CountSearch()
+	telemetry.PelletEaten(2, 1)
+	telemetry.PelletEaten(2, 10)
+	telemetry.AbilityUsed("SPEED")
+	telemetry.DuelWon()
+	telemetry.DuelLost()
+	telemetry.RecordTurn(30*time.Millisecond, true)
+	s := telemetry.Summarize()
+	if s.Turns != 2 || s.Overruns != 1 {
+		t.Errorf("got %d turns, %d overruns, want 2 and 1", s.Turns, s.Overruns)
+	}
+	if s.Searches != 4 || s.MaxSearches != 3 || s.Floods != 1 || s.MaxFloods != 1 {
+		t.Errorf("got searches %d max %d, floods %d max %d", s.Searches, s.MaxSearches, s.Floods, s.MaxFloods)
+	}
+	if s.PlanTotalMs != 40 || s.PlanMeanMs != 20 || s.PlanMaxMs != 30 {
+		t.Errorf("got plan time %v total, %v mean, %v max", s.PlanTotalMs, s.PlanMeanMs, s.PlanMaxMs)
+	}
+	if s.PelletsByPac[2] != 2 || s.ValueByPac[2] != 11 || s.Abilities["SPEED"] != 1 {
+		t.Errorf("got pellets %v worth %v, abilities %v", s.PelletsByPac, s.ValueByPac, s.Abilities)
+	}
+	if s.DuelsWon != 1 || s.DuelsLost != 1 {
+		t.Errorf("got %d duels won, %d lost", s.DuelsWon, s.DuelsLost)
+	}
+}
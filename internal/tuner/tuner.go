@@ -0,0 +1,181 @@
+// Package tuner searches bot parameters offline with the cross-entropy
+// method: each generation samples parameter sets around a mean, scores them
+// with self-play games and moves the mean and spread towards the best ones.
+package tuner
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"spring2020/internal/mapgen"
+	"spring2020/internal/params"
+)
+
+// Smallest standard deviation of a whole number parameter, so rounding
+// still explores its neighbours
+const MinIntStd = 0.5
+
+// Share of its initial standard deviation a parameter keeps at least, so a
+// parameter the score hardly depends on early is still tuned later
+const MinStdShare = 0.1
+
+// Tuned parameter with the normal distribution it is sampled from
+type Dimension struct {
+	Name    string
+	Mean    float64
+	Std     float64
+	Integer bool
+	// Smallest standard deviation kept
+	MinStd float64
+}
+
+// Settings of a tuning run
+type Options struct {
+	// Parameters the samples start from, and that the untuned ones keep
+	Base params.Params
+	// Names of the tuned parameters
+	Names []string
+	// Initial standard deviation as a share of each parameter's base value
+	Spread      float64
+	Generations int
+	// Parameter sets scored per generation
+	Population int
+	// Best parameter sets the distribution moves to per generation
+	Elite int
+	// Share of the old distribution kept per generation, against collapsing
+	// onto a lucky elite
+	Smoothing float64
+	Seed      int64
+}
+
+// Parameter set scored in a generation
+type Candidate struct {
+	Params params.Params
+	// Overrides of the tuned parameters in the format of params.Set
+	Spec  string
+	Score float64
+}
+
+// Score of a parameter set and generation, higher is better
+type Evaluate func(p params.Params, spec string, generation int) (float64, error)
+
+// Distribution over the tuned parameters of opts, centered on the base
+func newDimensions(opts Options) ([]Dimension, error) {
+	dims := make([]Dimension, len(opts.Names))
+	for i, name := range opts.Names {
+		value, integer, ok := opts.Base.Value(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter %q", name)
+		}
+		std := math.Abs(value) * opts.Spread
+		if integer {
+			std = math.Max(std, 1)
+		} else if std == 0 {
+			std = opts.Spread
+		}
+		minStd := std * MinStdShare
+		if integer {
+			minStd = MinIntStd
+		}
+		dims[i] = Dimension{Name: name, Mean: value, Std: std, Integer: integer, MinStd: minStd}
+	}
+	return dims, nil
+}
+
+// Value of a dimension as a parameter: rounded for whole numbers and never
+// negative
+func (d Dimension) format(v float64) string {
+	v = math.Max(v, 0)
+	if d.Integer {
+		return fmt.Sprint(int(math.Round(v)))
+	}
+	return fmt.Sprintf("%.3g", v)
+}
+
+// Parameter set of base with the tuned dimensions set to values
+func candidate(base params.Params, dims []Dimension, values []float64) (Candidate, error) {
+	pairs := make([]string, len(dims))
+	for i, d := range dims {
+		pairs[i] = d.Name + "=" + d.format(values[i])
+	}
+	c := Candidate{Params: base, Spec: strings.Join(pairs, ",")}
+	return c, c.Params.Set(c.Spec)
+}
+
+// Normally distributed random number with the Box-Muller transform
+func normal(r *mapgen.Random) float64 {
+	u := 1 - r.Float64()
+	return math.Sqrt(-2*math.Log(u)) * math.Cos(2*math.Pi*r.Float64())
+}
+
+// Tune the parameters of opts, scoring every sampled set with evaluate and
+// calling report after each generation with its candidates, best first, and
+// the updated distribution. Returns the parameters at the final mean.
+func Run(opts Options, evaluate Evaluate, report func(generation int, ranked []Candidate, dims []Dimension)) (Candidate, error) {
+	dims, err := newDimensions(opts)
+	if err != nil {
+		return Candidate{}, err
+	}
+	elite := opts.Elite
+	if elite < 1 || elite > opts.Population {
+		elite = (opts.Population + 3) / 4
+	}
+	r := mapgen.NewRandom(opts.Seed)
+	for gen := 0; gen < opts.Generations; gen++ {
+		samples := make([][]float64, opts.Population)
+		scored := make([]Candidate, opts.Population)
+		for i := range scored {
+			samples[i] = make([]float64, len(dims))
+			for j, d := range dims {
+				samples[i][j] = d.Mean + d.Std*normal(r)
+			}
+			// the first sample of a generation scores the mean itself
+			if i == 0 {
+				for j, d := range dims {
+					samples[i][j] = d.Mean
+				}
+			}
+			if scored[i], err = candidate(opts.Base, dims, samples[i]); err != nil {
+				return Candidate{}, err
+			}
+			if scored[i].Score, err = evaluate(scored[i].Params, scored[i].Spec, gen); err != nil {
+				return Candidate{}, err
+			}
+		}
+		order := make([]int, len(scored))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return scored[order[a]].Score > scored[order[b]].Score
+		})
+		ranked := make([]Candidate, len(scored))
+		for i, k := range order {
+			ranked[i] = scored[k]
+		}
+		for j := range dims {
+			mean, variance := 0.0, 0.0
+			for _, k := range order[:elite] {
+				mean += samples[k][j]
+			}
+			mean /= float64(elite)
+			for _, k := range order[:elite] {
+				variance += (samples[k][j] - mean) * (samples[k][j] - mean)
+			}
+			std := math.Sqrt(variance / float64(elite))
+			d := &dims[j]
+			d.Mean = opts.Smoothing*d.Mean + (1-opts.Smoothing)*mean
+			d.Std = math.Max(opts.Smoothing*d.Std+(1-opts.Smoothing)*std, d.MinStd)
+		}
+		if report != nil {
+			report(gen, ranked, dims)
+		}
+	}
+	means := make([]float64, len(dims))
+	for j, d := range dims {
+		means[j] = d.Mean
+	}
+	return candidate(opts.Base, dims, means)
+}
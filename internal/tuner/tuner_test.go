@@ -0,0 +1,42 @@
+package tuner
+
+import (
+	"math"
+	"testing"
+
+	"spring2020/internal/params"
+)
+
+func TestRunConvergesOnOptimum(t *testing.T) {
+	opts := Options{
+		Base:        params.Default,
+		Names:       []string{"RiskWeight", "ThreatRadius"},
+		Spread:      0.5,
+		Generations: 30,
+		Population:  20,
+		Elite:       5,
+		Smoothing:   0.2,
+		Seed:        1,
+	}
+	// a smooth score peaking at RiskWeight 1.5 and ThreatRadius 6
+	evaluate := func(p params.Params, _ string, _ int) (float64, error) {
+		return -math.Pow(p.RiskWeight-1.5, 2) - math.Pow(float64(p.ThreatRadius-6), 2), nil
+	}
+	best, err := Run(opts, evaluate, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(best.Params.RiskWeight-1.5) > 0.3 || best.Params.ThreatRadius != 6 {
+		t.Errorf("tuned to %s, want RiskWeight near 1.5 and ThreatRadius 6", best.Spec)
+	}
+	if best.Params.ValueWeight != params.Default.ValueWeight {
+		t.Error("untuned parameter changed")
+	}
+}
+
+func TestRunRejectsUnknownParameter(t *testing.T) {
+	opts := Options{Base: params.Default, Names: []string{"Nonsense"}, Generations: 1, Population: 1}
+	if _, err := Run(opts, nil, nil); err == nil {
+		t.Error("unknown parameter accepted")
+	}
+}
@@ -0,0 +1,57 @@
+// Package viewer serves an interactive board view of the turns a bot wrote
+// with -export: the maze with the believed pellets, pacs, their targets and
+// paths and the danger maps, scrubbed turn by turn in the browser.
+package viewer
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+//go:embed index.html
+var page []byte
+
+// Read the JSON lines of a state export, one turn each
+func ReadTurns(r io.Reader) ([]json.RawMessage, error) {
+	var turns []json.RawMessage
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1<<20), 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		if !json.Valid(text) {
+			return nil, fmt.Errorf("line %d: not a JSON object", line)
+		}
+		turns = append(turns, append(json.RawMessage{}, text...))
+	}
+	return turns, scanner.Err()
+}
+
+// Handler serving the board view at / and the turns as a JSON array at
+// /turns.json
+func Handler(turns []json.RawMessage) http.Handler {
+	if turns == nil {
+		turns = []json.RawMessage{}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	})
+	mux.HandleFunc("/turns.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(turns)
+	})
+	return mux
+}
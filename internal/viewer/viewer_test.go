@@ -0,0 +1,39 @@
+package viewer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerServesTurns(t *testing.T) {
+	turns, err := ReadTurns(strings.NewReader("{\"turn\":1}\n\n{\"turn\":2}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(Handler(turns))
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/turns.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var got []struct{ Turn int }
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].Turn != 2 {
+		t.Errorf("got %+v, want turns 1 and 2", got)
+	}
+	if resp, err := http.Get(server.URL + "/"); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("board page: %v %v", resp, err)
+	}
+}
+
+func TestReadTurnsRejectsGarbage(t *testing.T) {
+	if _, err := ReadTurns(strings.NewReader("{\"turn\":1}\nnot json\n")); err == nil {
+		t.Error("garbage line accepted")
+	}
+}
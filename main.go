@@ -1,504 +1,248 @@
 package main
 
 import (
-	"container/heap"
+	"bufio"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
 	"time"
-)
-import "os"
-import "bufio"
-
-// debug logging method
-func log(a ...any) {
-	_, _ = fmt.Fprintln(os.Stderr, a)
-}
-
-// Pac structs
-type Pac struct {
-	Id               int
-	Mine             bool
-	X                int
-	Y                int
-	TypeId           string
-	SpeedTurnsLeft   int
-	AbilityCooldown  int
-	TargetX          int
-	TargetY          int
-	TargetPelletId   int
-	TargetPelletDist int
-}
-
-// Pellet structs
-type Pellet struct {
-	X        int
-	Y        int
-	Value    int
-	Consumed bool
-	Targeted bool
-}
-
-// String
-func (p Pellet) String() string {
-	return fmt.Sprintf("Pellet (%d, %d) %d %v", p.X, p.Y, p.Value, p.Consumed)
-}
 
-// Cell type struct
-type Type string
+	"spring2020/internal/budget"
+	"spring2020/internal/gameio"
+	"spring2020/internal/logger"
+	"spring2020/internal/params"
+	"spring2020/internal/random"
+	"spring2020/internal/state"
+	"spring2020/internal/strategy"
+	"spring2020/internal/telemetry"
+)
 
-// Cell type constants
+// Time after reading the first input line of a turn by which commands are printed
 const (
-	Empty Type = " "
-	Wall  Type = "#"
+	FirstTurnDeadline = 900 * time.Millisecond
+	TurnDeadline      = 40 * time.Millisecond
 )
 
-// Cell structs
-type Cell struct {
-	x, y    int
-	isWall  bool
-	g, h, f int
-	parent  *Cell
-	index   int // index in the heap
-	// Neighbors
-	Neighbors []*Cell
-}
-
-// Initialize neighbors for cell
-func (c *Cell) InitNeighbors(grid [][]*Cell) {
-	c.Neighbors = getNeighbors(c, grid)
-}
-
-type PriorityQueue []*Cell
-
-// PriorityQueue methods
-
-func (pq PriorityQueue) Len() int { return len(pq) }
-
-func (pq PriorityQueue) Less(i, j int) bool {
-	return pq[i].f < pq[j].f
-}
-
-func (pq PriorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].index = i
-	pq[j].index = j
-}
-
-func (pq *PriorityQueue) Push(x interface{}) {
-	n := len(*pq)
-	item := x.(*Cell)
-	item.index = n
-	*pq = append(*pq, item)
-}
-
-func (pq *PriorityQueue) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	item := old[n-1]
-	item.index = -1
-	*pq = old[0 : n-1]
-	return item
-}
-
-func (pq *PriorityQueue) update(item *Cell, g, h int) {
-	item.g = g
-	item.h = h
-	item.f = g + h
-	heap.Fix(pq, item.index)
-}
-
-func getNeighbors(cell *Cell, grid [][]*Cell) []*Cell {
-	neighbors := []*Cell{}
-	x, y := cell.x, cell.y
-	if x > 0 {
-		neighbors = append(neighbors, grid[y][x-1])
-	}
-	if x < len(grid[0])-1 {
-		neighbors = append(neighbors, grid[y][x+1])
-	}
-	if y > 0 {
-		neighbors = append(neighbors, grid[y-1][x])
-	}
-	if y < len(grid)-1 {
-		neighbors = append(neighbors, grid[y+1][x])
-	}
-	return neighbors
-}
-
-func manhattanDistance(a, b *Cell) int {
-	return abs(a.x-b.x) + abs(a.y-b.y)
-}
-
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
-
-// Clone grid of cells
-func cloneGrid(grid [][]*Cell) [][]*Cell {
-	newGrid := make([][]*Cell, len(grid))
-	for y, row := range grid {
-		newGrid[y] = make([]*Cell, len(row))
-		for x, cell := range row {
-			newGrid[y][x] = &Cell{
-				x:      cell.x,
-				y:      cell.y,
-				isWall: cell.isWall,
-			}
-		}
-	}
-	return newGrid
-}
-
-func AStar(startX, startY, endX, endY int, grid [][]*Cell) []*Cell {
-	openSet := &PriorityQueue{}
-	clone := cloneGrid(grid)
-	heap.Init(openSet)
-	start := GetCell(startX, startY, clone)
-	goal := GetCell(endX, endY, clone)
-	heap.Push(openSet, start)
-
-	closedSet := make(map[*Cell]bool)
-	for openSet.Len() > 0 {
-		current := heap.Pop(openSet).(*Cell)
-
-		if current == goal {
-			var path []*Cell
-			for current != nil {
-				path = append([]*Cell{current}, path...)
-				current = current.parent
-			}
-			for _, cell := range path {
-				log(cell.x, cell.y)
-			}
-			return path
-		}
-		closedSet[current] = true
-
-		for _, neighbor := range current.Neighbors {
-			if neighbor.isWall || closedSet[neighbor] {
-				continue
-			}
-
-			tentativeGScore := current.g + 1
-			if !contains(openSet, neighbor) {
-				heap.Push(openSet, neighbor)
-			} else if tentativeGScore >= neighbor.g {
-				continue
-			}
-
-			neighbor.parent = current
-			openSet.update(neighbor, tentativeGScore, manhattanDistance(neighbor, goal))
-		}
-	}
-
-	return nil
-}
-
-func contains(pq *PriorityQueue, cell *Cell) bool {
-	for _, c := range *pq {
-		if c == cell {
-			return true
-		}
-	}
-	return false
-}
-
-// Game state structs
-type Game struct {
-	Width               int
-	Height              int
-	MyPacs              []*Pac
-	OpponentPacs        []*Pac
-	Pellet              []*Pellet
-	Grid                [][]*Cell
-	MyScore             int
-	OpponentScore       int
-	VisiblePacCount     int
-	VisiblePalleteCount int
-}
-
-// Get cell pointer at x, y
-func GetCell(x, y int, grid [][]*Cell) *Cell {
-	return grid[y][x]
-}
-
-// Add pac or update existing pac location data to state mine or opponent
-func (g *Game) AddPac(id, mine, x, y int, typeId string, speedTurnsLeft, abilityCooldown int) {
-	var pacs []*Pac
-	if mine == 1 {
-		pacs = g.MyPacs
-	} else {
-		pacs = g.OpponentPacs
-	}
-	for _, pac := range pacs {
-		if pac.Id == id {
-			pac.X = x
-			pac.Y = y
-			pac.TypeId = typeId
-			pac.SpeedTurnsLeft = speedTurnsLeft
-			pac.AbilityCooldown = abilityCooldown
-			return
-		}
-	}
-	pacs = append(pacs, &Pac{
-		Id:              id,
-		Mine:            mine == 1,
-		X:               x,
-		Y:               y,
-		TypeId:          typeId,
-		SpeedTurnsLeft:  speedTurnsLeft,
-		AbilityCooldown: abilityCooldown,
-		TargetX:         x,
-		TargetY:         y,
-	})
-	if mine == 1 {
-		g.MyPacs = pacs
+// Write the recorded input, the panic with its stack and the game state to a
+// crash file and a truncated copy to stderr
+func writeCrashDump(reason any, stack []byte, in *gameio.InputReader, game *state.Game) {
+	snapshot, err := json.MarshalIndent(game.Snapshot(), "", "  ")
+	if err != nil {
+		snapshot = []byte(err.Error())
+	}
+	dump := fmt.Sprintf("%s--- panic: %v\n%s\n--- state\n%s\n", in.Recorded(), reason, stack, snapshot)
+	name := fmt.Sprintf("crash-turn%d-%d.txt", game.Turn, time.Now().Unix())
+	if err := os.WriteFile(name, []byte(dump), 0o644); err != nil {
+		logger.Info("Crash dump not written:", err)
 	} else {
-		g.OpponentPacs = pacs
+		logger.Info("Crash dump written to", name)
 	}
-}
-
-// Add pellet or update existing pellet location data to state
-func (g *Game) AddPellet(id, x, y, value int) {
-	for _, pellet := range g.Pellet {
-		if pellet.X == x && pellet.Y == y {
-			pellet.X = x
-			pellet.Y = y
-			pellet.Value = value
-			pellet.Consumed = false
-			return
-		}
+	if len(dump) > gameio.CrashStderrLimit {
+		dump = dump[:gameio.CrashStderrLimit] + "\n--- truncated"
 	}
-	g.Pellet = append(g.Pellet, &Pellet{
-		X:        x,
-		Y:        y,
-		Value:    value,
-		Consumed: false,
-	})
+	logger.Info(dump)
 }
 
-// Get the closest super pallet to pac using a star
-func (g *Game) GetClosestSuperPallet(pac *Pac) *Pellet {
-	var closest *Pellet
-	var closestDist int
-	for _, pallet := range g.Pellet {
-		if pallet.Value == 10 && !pallet.Consumed && !pallet.Targeted {
-			path := AStar(pac.X, pac.Y, pallet.X, pallet.Y, g.Grid)
-			if closest == nil || len(path) < closestDist {
-				closest = pallet
-				closestDist = len(path)
-			}
-		}
-	}
-	return closest
-}
-
-// Get closest regular pallet to pac
-func (g *Game) GetClosestRegularPallet(pac *Pac) *Pellet {
-	var closest *Pellet
-	var closestDist int
-	for _, pallet := range g.Pellet {
-		if pallet.Value == 1 && !pallet.Consumed && !pallet.Targeted {
-			path := AStar(pac.X, pac.Y, pallet.X, pallet.Y, g.Grid)
-			if closest == nil || len(path) < closestDist {
-				closest = pallet
-				closestDist = len(path)
-			}
-		}
-	}
-	return closest
-}
-
-// Get pallet by cordinates
-func (g *Game) GetPallet(x, y int) *Pellet {
-	log("Getting pallet", x, y)
-	log("total pallets", len(g.Pellet))
-	for _, pallet := range g.Pellet {
-		if pallet.X == 19 && pallet.Y == 2 {
-			log("Pallet 19,2", pallet)
-		}
-		if pallet.X == x && pallet.Y == y {
-			return pallet
-		}
-	}
-	return nil
-}
+func main() {
+	decisions := flag.String("decisions", "", "write a JSONL decision log to this file")
+	export := flag.String("export", "", "write the game state of every turn as a JSON line to this file, or to stderr when \"stderr\"")
+	replay := flag.String("replay", "", "read the game input from this recorded file and plan without deadlines")
+	step := flag.Bool("step", false, "with -replay, wait for a line on stdin before every turn")
+	// on CodinGame the stderr log is the only place the input can be kept
+	record := flag.String("record", "stderr", "mirror the input to this file, or to stderr prefixed when \"stderr\", or nowhere when empty")
+	overrides := flag.String("params", os.Getenv(params.EnvVar), "override parameters of every profile, as Name=value,Name=value")
+	weightsFile := flag.String("weights", "", "override parameters from this file too, reloaded between turns whenever it changes")
+	render := flag.String("render", "", "draw the maze to the log every turn, \"plain\" or with ANSI \"color\"")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile of the whole run to this file, best with -replay")
+	memProfile := flag.String("memprofile", "", "write an allocation profile to this file when the input ends")
+	logLevel := flag.String("log", os.Getenv(logger.LogEnvVar), "log level: trace, debug, info or silent")
+	seed := flag.String("seed", os.Getenv(random.SeedEnvVar), "seed of the random choices, from the clock when empty")
+	flag.Parse()
+	if *logLevel != "" {
+		level, err := logger.ParseLevel(*logLevel)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		logger.SetLevel(level)
+	}
+	if err := new(params.Params).Set(*overrides); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	rng, err := random.ParseSeed(*seed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// rerun a game with -seed and its recorded input to replay its choices
+	logger.Info(rng.LogLine())
+	input := io.Reader(os.Stdin)
+	if *replay != "" {
+		file, err := os.Open(*replay)
+		if err == nil {
+			input, err = gameio.ReadRecording(file)
+			file.Close()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	stopProfiles, err := state.StartProfiles(*cpuProfile, *memProfile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer stopProfiles()
+	in := gameio.NewInputReader(input)
+	switch *record {
+	case "":
+	case "stderr":
+		// a replay mirrored to stderr only repeats its file, a silenced bot
+		// writes nothing at all
+		if *replay == "" && logger.Enabled(logger.LevelInfo) {
+			in.Record(os.Stderr, gameio.InputPrefix)
+		}
+	default:
+		file, err := os.Create(*record)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		in.Record(file, "")
+	}
+	stdin := bufio.NewReader(os.Stdin)
 
-// Check if pac target has been eaten already and remove current target if so
-func (g *Game) CheckTargetEaten(pac *Pac) {
-	pallet := g.GetPallet(pac.TargetX, pac.TargetY)
-	log("Checking target", pac.TargetX, pac.TargetY, "pallet", pallet)
-	if pallet != nil {
-		log("Target eaten", pac.TargetX, pac.TargetY, pallet.Value)
-		if pallet.Consumed {
-			pac.TargetX = pac.X
-			pac.TargetY = pac.Y
-			pac.TargetPelletDist = -1
+	// game: game state
+	var game state.Game
+	game.MyPacs = make([]*state.Pac, 0)
+	game.OpponentPacs = make([]*state.Pac, 0)
+	game.Params = params.Default
+	game.Params.Set(*overrides)
+	game.Random = rng
+	if *decisions != "" {
+		decisionLog, err := state.NewDecisionLog(*decisions)
+		if err != nil {
+			logger.Info("Decision log disabled:", err)
+		} else {
+			game.DecisionLog = decisionLog
+		}
+	}
+	var weights *params.Watch
+	if *weightsFile != "" {
+		weights = params.NewWatch(*weightsFile)
+	}
+	var stateExport *state.StateExport
+	if *export != "" {
+		var err error
+		if stateExport, err = state.NewStateExport(*export); err != nil {
+			logger.Info("State export disabled:", err)
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			writeCrashDump(r, debug.Stack(), in, &game)
+			panic(r)
+		}
+	}()
+	gameio.ReadGrid(in, &game)
+	bot := strategy.NewBot(&game)
+	mem := state.NewMemReport()
+	planned := make(chan *strategy.TurnPanic)
+	close(planned)
+	// parameters of the game before the weights file applies
+	var profile params.Params
+	// commands printed last turn and the time they took
+	var commands string
+	var took time.Duration
+	for {
+		// a planner that overran the deadline stops at its next check, wait
+		// for it before touching the game state
+		if crash := <-planned; crash != nil {
+			writeCrashDump(crash, crash.Stack, in, &game)
+		}
+		if stateExport != nil && game.Turn > 0 {
+			stateExport.Write(&game, commands, took)
+		}
+		if *replay != "" && *step {
+			fmt.Fprintf(os.Stderr, "Press enter for turn %d", game.Turn+1)
+			stdin.ReadString('\n')
+		}
+		game.Turn++
+		in.StartTurn()
+		gameio.ReadScores(in, &game)
+		if in.Closed() {
+			logger.Info("Input closed after turn", game.Turn-1)
+			telemetry.ReportTelemetry()
+			return
 		}
-	} else {
-		pac.TargetX = pac.X
-		pac.TargetY = pac.Y
-		pac.TargetPelletDist = -1
-
-	}
-}
-
-// Remove pallet from game o  current Pac cordinates
-func (g *Game) RemovePallet(pac *Pac) {
-	pallet := g.GetPallet(pac.X, pac.Y)
-	if pallet != nil {
-		log("Pac", pac.Id, "ate pallet", pallet.X, pallet.Y, pallet.Value)
-		pallet.Consumed = true
-	}
-}
-
-// Play a turn
-func (g *Game) PlayTurn() {
-	startTime := time.Now()
-	log(len(g.MyPacs))
-	for _, pac := range g.MyPacs {
-		log("Pac", pac.Id, "x", pac.X, "y", pac.Y)
-		g.RemovePallet(pac)
-		g.CheckTargetEaten(pac)
-	}
-	for _, pac := range g.OpponentPacs {
-		g.RemovePallet(pac)
-	}
-	moves := ""
-	for _, pac := range g.MyPacs {
-		log("Pac", pac.Id, "x", pac.X, "y", pac.Y, "type", pac.TypeId, "speed turns left", pac.SpeedTurnsLeft, "ability cooldown", pac.AbilityCooldown, "target x", pac.TargetX, "target y", pac.TargetY, "target pellet dist", pac.TargetPelletDist)
-		if pac.X == pac.TargetX && pac.Y == pac.TargetY {
-			log("Pac", pac.Id, "reached target", pac.TargetX, pac.TargetY)
-			old := g.GetPallet(pac.TargetX, pac.TargetY)
-			if old != nil {
-				old.Value = 0
-				log("Pac", pac.Id, "ate pallet", pac.TargetX, pac.TargetY)
-				pac.TargetX = -1
-				pac.TargetY = -1
-				pac.TargetPelletDist = -1
-			}
-
-			pallet := g.GetClosestSuperPallet(pac)
-			if pallet != nil {
-				moves += fmt.Sprintf("MOVE %d %d %d|", pac.Id, pallet.X, pallet.Y)
-				pac.TargetX = pallet.X
-				pac.TargetY = pallet.Y
-				pac.TargetPelletDist = len(AStar(pac.X, pac.Y, pallet.X, pallet.Y, g.Grid))
-				pallet.Targeted = true
-			} else {
-				pallet = g.GetClosestRegularPallet(pac)
-				if pallet != nil {
-					moves += fmt.Sprintf("MOVE %d %d %d|", pac.Id, pallet.X, pallet.Y)
-					pac.TargetX = pallet.X
-					pac.TargetY = pallet.Y
-					pac.TargetPelletDist = len(AStar(pac.X, pac.Y, pallet.X, pallet.Y, g.Grid))
-					pallet.Targeted = true
+		logger.SetTurn(game.Turn)
+		logger.Info("Turn", game.Turn)
+		deadline := TurnDeadline
+		if game.Turn == 1 {
+			deadline = FirstTurnDeadline
+		}
+		// a replay has no referee waiting, planning always runs to the end
+		var timeout <-chan time.Time
+		turnBudget := budget.NewTurnBudget(0)
+		if *replay == "" {
+			turnBudget = budget.NewTurnBudget(deadline)
+			timeout = time.After(deadline)
+		}
+		gameio.ReadEntities(in, &game)
+
+		// all my pacs are visible, so the first turn tells the pac count
+		if game.Turn == 1 {
+			game.Params = params.Profile(game.Width, game.Height, len(game.MyPacs))
+			game.Params.Set(*overrides)
+			profile = game.Params
+			logger.Info("Profile", params.Size(game.Width, game.Height), len(game.MyPacs), "pacs", game.Params)
+		}
+		// edits of the weights file apply from the next turn on, over the
+		// profile with its overrides
+		if weights != nil {
+			if spec, changed, err := weights.Changed(); err != nil {
+				logger.Info("Weights file:", err)
+			} else if changed {
+				reloaded := profile
+				if err := reloaded.Set(spec); err != nil {
+					logger.Info("Weights file:", err)
 				} else {
-					moves += fmt.Sprintf("MOVE %d %d %d|", pac.Id, pallet.X, pallet.Y)
-					pac.TargetX = pac.X
-					pac.TargetY = pac.Y
-					pac.TargetPelletDist = 0
+					game.Params = reloaded
+					logger.Info("Weights reloaded:", spec)
 				}
 			}
-		} else {
-			moves += fmt.Sprintf("MOVE %d %d %d|", pac.Id, pac.TargetX, pac.TargetY)
 		}
-	}
-	fmt.Println(moves)
-	log("Turn took", time.Since(startTime))
-}
 
-func main() {
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Buffer(make([]byte, 1000000), 1000000)
-
-	// game: game state
-	var game Game
-	game.MyPacs = make([]*Pac, 0)
-	game.OpponentPacs = make([]*Pac, 0)
-	game.Pellet = make([]*Pellet, 0)
-	// width: size of the grid
-	// height: top left corner is (x=0, y=0)
-	scanner.Scan()
-	fmt.Sscan(scanner.Text(), &game.Width, &game.Height)
-	game.Grid = make([][]*Cell, game.Height)
-	for i := range game.Grid {
-		scanner.Scan()
-		row := scanner.Text()
-		game.Grid[i] = make([]*Cell, game.Width)
-		for j, c := range row {
-			game.Grid[i][j] = &Cell{
-				x:      j,
-				y:      i,
-				isWall: c == '#',
+		pub := gameio.NewPublisher(game.MyPacs, game.Width, game.Height)
+		planned = make(chan *strategy.TurnPanic, 1)
+		go func() {
+			// a panicking turn still answers with emergency commands, one
+			// bad turn must not forfeit the game
+			planned <- bot.SafePlayTurn(pub, turnBudget)
+		}()
+		select {
+		case crash := <-planned:
+			commands, took = pub.Publish(), turnBudget.Elapsed()
+			if crash != nil {
+				writeCrashDump(crash, crash.Stack, in, &game)
 			}
-		}
-	}
-
-	for _, cells := range game.Grid {
-		for _, cell := range cells {
-			cell.InitNeighbors(game.Grid)
-		}
-	}
-	for {
-		var myScore, opponentScore int
-		scanner.Scan()
-		fmt.Sscan(scanner.Text(), &myScore, &opponentScore)
-		game.MyScore = myScore
-		game.OpponentScore = opponentScore
-		// remove all pallets
-		for _, pallet := range game.Pellet {
-			pallet.Consumed = true
-		}
-		// visiblePacCount: all your pacs and enemy pacs in sight
-		var visiblePacCount int
-		scanner.Scan()
-		fmt.Sscan(scanner.Text(), &visiblePacCount)
-		game.VisiblePacCount = visiblePacCount
-		log("Visible pac count", visiblePacCount)
-		for i := 0; i < visiblePacCount; i++ {
-			// pacId: pac number (unique within a team)
-			// mine: true if this pac is yours
-			// x: position in the grid
-			// y: position in the grid
-			// typeId: unused in wood leagues
-			// speedTurnsLeft: unused in wood leagues
-			// abilityCooldown: unused in wood leagues
-			var pacId int
-			var _mine int
-			var x, y int
-			var typeId string
-			var speedTurnsLeft, abilityCooldown int
-			scanner.Scan()
-			fmt.Sscan(scanner.Text(), &pacId, &_mine, &x, &y, &typeId, &speedTurnsLeft, &abilityCooldown)
-			log("pac id", pacId, "mine", _mine, "x", x, "y", y, "type id", typeId, "speed turns left",
-				speedTurnsLeft, "ability cooldown", abilityCooldown)
-			game.AddPac(pacId, _mine, x, y, typeId, speedTurnsLeft, abilityCooldown)
-		}
-		// visiblePelletCount: all pellets in sight
-		var visiblePelletCount int
-		scanner.Scan()
-		fmt.Sscan(scanner.Text(), &visiblePelletCount)
-		game.VisiblePalleteCount = visiblePelletCount
-		for i := 0; i < visiblePelletCount; i++ {
-			// value: amount of points this pellet is worth
-			var x, y, value int
-			scanner.Scan()
-			fmt.Sscan(scanner.Text(), &x, &y, &value)
-			game.AddPellet(i, x, y, value)
-			if x == 19 && y == 9 {
-				log("Pellet", i, "x", x, "y", y, "value", value)
+			if *render != "" {
+				logger.Log("\n" + game.Render(*render == "color"))
 			}
-		}
-
-		pellets := ""
-		for _, pellet := range game.Pellet {
-			pellets += pellet.String() + " "
-		}
-		//log(pellets)
-
-		game.PlayTurn()
+			planned = make(chan *strategy.TurnPanic)
+			close(planned)
+			telemetry.RecordTurn(took, false)
+		case <-timeout:
+			logger.Info("Turn", game.Turn, "deadline reached, publishing pending commands")
+			commands, took = pub.Publish(), turnBudget.Elapsed()
+			telemetry.RecordTurn(took, true)
+		}
+		mem.Turn(game.Turn)
 	}
 }